@@ -0,0 +1,154 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dwnGnL/pg-contests/internal/repository"
+	"github.com/dwnGnL/pg-contests/lib/goerrors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func (ah *adminHandler) createBankQuestion(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	var request repository.BankQuestion
+	if err := c.ShouldBindJSON(&request); err != nil {
+		goerrors.Log().WithError(err).Error("bind request error")
+		errorModel.Error.Message = "bind request error: " + err.Error()
+		c.JSON(http.StatusBadRequest, errorModel)
+		return
+	}
+	app, tokenDetails, ok := ah.adminRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+
+	request.CreatedBy = strconv.FormatInt(tokenDetails.ID, 10)
+	question, err := app.CreateBankQuestion(request)
+	if err != nil {
+		goerrors.Log().WithError(err).Error("create bank question error")
+		errorModel.Error.Message = "create bank question error: " + err.Error()
+		c.JSON(http.StatusBadRequest, errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, question)
+}
+
+// searchBankQuestions фильтры: tags=a,b (все теги), type, difficulty или min_difficulty/max_difficulty, q - текст
+func (ah *adminHandler) searchBankQuestions(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	app, _, ok := ah.adminRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+
+	filter := repository.BankFilter{
+		Type:  repository.QuestionType(c.Query("type")),
+		Query: c.Query("q"),
+	}
+	for _, tags := range c.QueryArray("tags") {
+		filter.Tags = append(filter.Tags, strings.Split(tags, ",")...)
+	}
+	filter.MinDifficulty, _ = strconv.Atoi(c.Query("min_difficulty"))
+	filter.MaxDifficulty, _ = strconv.Atoi(c.Query("max_difficulty"))
+	if difficulty, err := strconv.Atoi(c.Query("difficulty")); err == nil {
+		filter.MinDifficulty, filter.MaxDifficulty = difficulty, difficulty
+	}
+
+	pagination := repository.GetPaginateSettings(c.Request)
+	questions, err := app.SearchBankQuestions(filter, pagination)
+	if err != nil {
+		goerrors.Log().WithError(err).Error("search bank questions error")
+		errorModel.Error.Message = "search bank questions error: " + err.Error()
+		c.JSON(http.StatusInternalServerError, errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, questions)
+}
+
+func (ah *adminHandler) getBankQuestion(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	app, questionID, ok := ah.idRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+
+	question, err := app.GetBankQuestion(questionID)
+	if err != nil {
+		goerrors.Log().WithError(err).Error("get bank question error")
+		errorModel.Error.Message = "get bank question error: " + err.Error()
+		c.JSON(bankErrorStatus(err), errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, question)
+}
+
+func (ah *adminHandler) updateBankQuestion(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	var request repository.BankQuestion
+	if err := c.ShouldBindJSON(&request); err != nil {
+		goerrors.Log().WithError(err).Error("bind request error")
+		errorModel.Error.Message = "bind request error: " + err.Error()
+		c.JSON(http.StatusBadRequest, errorModel)
+		return
+	}
+	app, questionID, ok := ah.idRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+
+	request.ID = questionID
+	question, err := app.UpdateBankQuestion(request)
+	if err != nil {
+		goerrors.Log().WithError(err).Error("update bank question error")
+		errorModel.Error.Message = "update bank question error: " + err.Error()
+		c.JSON(bankErrorStatus(err), errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, question)
+}
+
+// deleteBankQuestion снимки в конкурсах остаются, удаляется только вопрос банка
+func (ah *adminHandler) deleteBankQuestion(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	app, questionID, ok := ah.idRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+
+	if err := app.DeleteBankQuestion(questionID); err != nil {
+		goerrors.Log().WithError(err).Error("delete bank question error")
+		errorModel.Error.Message = "delete bank question error: " + err.Error()
+		c.JSON(bankErrorStatus(err), errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Bank question deleted"})
+}
+
+func (ah *adminHandler) getBankTags(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	app, _, ok := ah.adminRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+
+	tags, err := app.GetBankTags()
+	if err != nil {
+		goerrors.Log().WithError(err).Error("get bank tags error")
+		errorModel.Error.Message = "get bank tags error: " + err.Error()
+		c.JSON(http.StatusInternalServerError, errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, tags)
+}
+
+// bankErrorStatus ошибки чтения - 404, остальное при изменении - ошибки валидации
+func bankErrorStatus(err error) int {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
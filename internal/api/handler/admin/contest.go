@@ -1,9 +1,11 @@
 package admin
 
 import (
+	"errors"
 	"fmt"
 	"github.com/dwnGnL/pg-contests/internal/application"
 	"github.com/dwnGnL/pg-contests/internal/repository"
+	"github.com/dwnGnL/pg-contests/internal/service"
 	"github.com/dwnGnL/pg-contests/lib/goerrors"
 	"github.com/gin-gonic/gin"
 	"net/http"
@@ -40,6 +42,10 @@ func (ah *adminHandler) createContest(c *gin.Context) {
 	if err != nil {
 		goerrors.Log().WithError(err).Error("create contest error")
 		errorModel.Error.Message = "create contest error: " + err.Error()
+		if errors.Is(err, service.ErrInvalidBankDraw) || errors.Is(err, repository.ErrNotEnoughBankQuestions) {
+			c.JSON(http.StatusBadRequest, errorModel)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, errorModel)
 		return
 	}
@@ -170,9 +176,15 @@ func (ah *adminHandler) updateContest(c *gin.Context) {
 		return
 	}
 
-	contest, err := app.UpdateContest(request)
+	//удаление вопросов и смена правильных ответов у начатого конкурса только с force=true
+	force, _ := strconv.ParseBool(c.Query("force"))
+	contest, err := app.UpdateContest(request, force)
 	if err != nil {
 		goerrors.Log().WithError(err).Error("update contest error")
+		if errors.Is(err, repository.ErrDestructiveChange) {
+			c.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "update contest error: " + err.Error()})
 		return
 	}
@@ -183,8 +195,7 @@ func (ah *adminHandler) changeStatus(c *gin.Context) {
 
 	var (
 		errorModel = repository.ErrorResponse{}
-		msg        string
-		newStatus  bool
+		newStatus  repository.ContestStatus
 		contestID  int64
 		err        error
 		app        application.Core
@@ -215,17 +226,11 @@ func (ah *adminHandler) changeStatus(c *gin.Context) {
 	if err != nil {
 		goerrors.Log().WithError(err).Error("contest ChangeStatus error")
 		errorModel.Error.Message = "contest ChangeStatus error: " + err.Error()
-		c.JSON(http.StatusInternalServerError, errorModel)
+		c.JSON(transitionErrorStatus(err), errorModel)
 		return
 	}
-	switch newStatus {
-	case true:
-		msg = fmt.Sprintf("Contest #%d was activated", contestID)
-	case false:
-		msg = fmt.Sprintf("Contest #%d was disabled", contestID)
-	}
 
-	c.JSON(http.StatusOK, gin.H{"message": msg})
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("Contest #%d is %s now", contestID, newStatus)})
 }
 
 func (ah *adminHandler) migrate(c *gin.Context) {
@@ -0,0 +1,128 @@
+package admin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dwnGnL/pg-contests/internal/contestio"
+	"github.com/dwnGnL/pg-contests/internal/repository"
+	"github.com/dwnGnL/pg-contests/lib/goerrors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const maxImportSize = 10 << 20
+
+// exportContest format=json|csv|gift, по умолчанию json
+func (ah *adminHandler) exportContest(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	app, contestID, ok := ah.idRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+	format, err := contestio.ParseFormat(c.DefaultQuery("format", string(contestio.FormatJSON)), "")
+	if err != nil {
+		errorModel.Error.Message = err.Error()
+		c.JSON(http.StatusBadRequest, errorModel)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err = app.ExportContest(contestID, format, &buf); err != nil {
+		goerrors.Log().WithError(err).Error("export contest error")
+		errorModel.Error.Message = "export contest error: " + err.Error()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, errorModel)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errorModel)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="contest_%d.%s"`, contestID, format))
+	c.Data(http.StatusOK, format.ContentType(), buf.Bytes())
+}
+
+// importContest файл в поле file multipart-формы или телом запроса; поля конкурса для CSV и GIFT в query:
+// title, price, start_time (RFC3339), timezone. dry_run=true только проверяет файл
+func (ah *adminHandler) importContest(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	app, tokenDetails, ok := ah.adminRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+
+	base, err := importBase(c)
+	if err != nil {
+		errorModel.Error.Message = err.Error()
+		c.JSON(http.StatusBadRequest, errorModel)
+		return
+	}
+	base.CreatedBy = strconv.FormatInt(tokenDetails.ID, 10)
+	dryRun, _ := strconv.ParseBool(c.Query("dry_run"))
+
+	var (
+		body     io.Reader
+		fileName string
+	)
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			goerrors.Log().WithError(err).Error("read form file error")
+			errorModel.Error.Message = "read form file error: " + err.Error()
+			c.JSON(http.StatusBadRequest, errorModel)
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			goerrors.Log().WithError(err).Error("open form file error")
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		defer file.Close()
+		body, fileName = file, fileHeader.Filename
+	} else {
+		body = c.Request.Body
+	}
+	format, err := contestio.ParseFormat(c.Query("format"), fileName)
+	if err != nil {
+		errorModel.Error.Message = err.Error()
+		c.JSON(http.StatusBadRequest, errorModel)
+		return
+	}
+
+	result, err := app.ImportContest(body, format, base, dryRun)
+	if err != nil {
+		goerrors.Log().WithError(err).Error("import contest error")
+		errorModel.Error.Message = "import contest error: " + err.Error()
+		c.JSON(http.StatusInternalServerError, errorModel)
+		return
+	}
+	if len(result.Errors) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, result)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func importBase(c *gin.Context) (base repository.Contest, err error) {
+	base.Title = c.Query("title")
+	base.Timezone = c.Query("timezone")
+	if value := c.Query("price"); value != "" {
+		if base.Price, err = strconv.ParseFloat(value, 64); err != nil {
+			return base, fmt.Errorf("invalid price %q", value)
+		}
+	}
+	if value := c.Query("start_time"); value != "" {
+		if base.StartTime, err = time.Parse(time.RFC3339, value); err != nil {
+			return base, fmt.Errorf("invalid start_time %q, expected RFC3339", value)
+		}
+	}
+	return base, nil
+}
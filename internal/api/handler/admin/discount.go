@@ -0,0 +1,148 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/dwnGnL/pg-contests/internal/repository"
+	"github.com/dwnGnL/pg-contests/lib/goerrors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// issueTickets бесплатные билеты на конкурс: {"user_ids": [...]}
+func (ah *adminHandler) issueTickets(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	var request struct {
+		UserIDs []int64 `json:"user_ids"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		goerrors.Log().WithError(err).Error("bind request error")
+		errorModel.Error.Message = "bind request error: " + err.Error()
+		c.JSON(http.StatusBadRequest, errorModel)
+		return
+	}
+	app, tokenDetails, ok := ah.adminRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+	contestID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		goerrors.Log().WithError(err).Error("Parse id error")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	tickets, err := app.IssueTickets(contestID, request.UserIDs, strconv.FormatInt(tokenDetails.ID, 10))
+	if err != nil {
+		goerrors.Log().WithError(err).Error("issue tickets error")
+		errorModel.Error.Message = "issue tickets error: " + err.Error()
+		c.JSON(discountErrorStatus(err), errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, tickets)
+}
+
+func (ah *adminHandler) getContestTickets(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	app, contestID, ok := ah.idRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+
+	tickets, err := app.GetContestTickets(contestID)
+	if err != nil {
+		goerrors.Log().WithError(err).Error("get contest tickets error")
+		errorModel.Error.Message = "get contest tickets error: " + err.Error()
+		c.JSON(http.StatusInternalServerError, errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, tickets)
+}
+
+func (ah *adminHandler) cancelTicket(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	app, ticketID, ok := ah.idRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+
+	ticket, err := app.CancelTicket(ticketID)
+	if err != nil {
+		goerrors.Log().WithError(err).Error("cancel ticket error")
+		errorModel.Error.Message = "cancel ticket error: " + err.Error()
+		c.JSON(discountErrorStatus(err), errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, ticket)
+}
+
+func (ah *adminHandler) createPromoCode(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	var request repository.PromoCode
+	if err := c.ShouldBindJSON(&request); err != nil {
+		goerrors.Log().WithError(err).Error("bind request error")
+		errorModel.Error.Message = "bind request error: " + err.Error()
+		c.JSON(http.StatusBadRequest, errorModel)
+		return
+	}
+	app, tokenDetails, ok := ah.adminRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+
+	request.CreatedBy = strconv.FormatInt(tokenDetails.ID, 10)
+	promo, err := app.CreatePromoCode(request)
+	if err != nil {
+		goerrors.Log().WithError(err).Error("create promo code error")
+		errorModel.Error.Message = "create promo code error: " + err.Error()
+		c.JSON(discountErrorStatus(err), errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, promo)
+}
+
+func (ah *adminHandler) getPromoCodes(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	app, _, ok := ah.adminRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+
+	promos, err := app.GetPromoCodes()
+	if err != nil {
+		goerrors.Log().WithError(err).Error("get promo codes error")
+		errorModel.Error.Message = "get promo codes error: " + err.Error()
+		c.JSON(http.StatusInternalServerError, errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, promos)
+}
+
+func (ah *adminHandler) deletePromoCode(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	app, promoID, ok := ah.idRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+
+	if err := app.DeletePromoCode(promoID); err != nil {
+		goerrors.Log().WithError(err).Error("delete promo code error")
+		errorModel.Error.Message = "delete promo code error: " + err.Error()
+		c.JSON(discountErrorStatus(err), errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Promo code deleted"})
+}
+
+// discountErrorStatus остальные ошибки - ошибки проверки запроса
+func discountErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, repository.ErrTicketUnavailable), errors.Is(err, repository.ErrPromoCodeTaken):
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,73 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/dwnGnL/pg-contests/internal/repository"
+	"github.com/dwnGnL/pg-contests/internal/service"
+	"github.com/dwnGnL/pg-contests/lib/goerrors"
+	"github.com/gin-gonic/gin"
+)
+
+// hostAction пауза, продолжение, пропуск, продление или аннулирование идущего вопроса живого конкурса.
+// В ответе состояние таймлайна после действия, то же получают подключенные участники
+func (ah *adminHandler) hostAction(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	var request repository.HostEvent
+	if err := c.ShouldBindJSON(&request); err != nil {
+		goerrors.Log().WithError(err).Error("bind request error")
+		errorModel.Error.Message = "bind request error: " + err.Error()
+		c.JSON(http.StatusBadRequest, errorModel)
+		return
+	}
+	app, tokenDetails, ok := ah.adminRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+	contestID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		goerrors.Log().WithError(err).Error("Parse contest id error")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	request.CreatedBy = strconv.FormatInt(tokenDetails.ID, 10)
+	resp, err := app.HostAction(contestID, request)
+	if err != nil {
+		goerrors.Log().WithError(err).Errorf("host action %s error", request.Action)
+		errorModel.Error.Message = "host action error: " + err.Error()
+		c.JSON(hostErrorStatus(err), errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (ah *adminHandler) getHostEvents(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	app, contestID, ok := ah.idRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+
+	events, err := app.GetHostEvents(contestID)
+	if err != nil {
+		goerrors.Log().WithError(err).Error("get host events error")
+		errorModel.Error.Message = "get host events error: " + err.Error()
+		c.JSON(http.StatusInternalServerError, errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, events)
+}
+
+func hostErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrHostNotAllowed), errors.Is(err, service.ErrNoActiveQuestion),
+		errors.Is(err, service.ErrAlreadyPaused), errors.Is(err, service.ErrNotPaused):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrExtendSeconds), errors.Is(err, service.ErrUnknownHostAction):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
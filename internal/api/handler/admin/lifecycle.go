@@ -0,0 +1,58 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/dwnGnL/pg-contests/internal/application"
+	"github.com/dwnGnL/pg-contests/internal/repository"
+	"github.com/dwnGnL/pg-contests/internal/service"
+	"github.com/dwnGnL/pg-contests/lib/goerrors"
+	"github.com/gin-gonic/gin"
+)
+
+// transitionContest хендлер перехода конкурса в статус to
+func (ah *adminHandler) transitionContest(to repository.ContestStatus) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		errorModel := repository.ErrorResponse{}
+		app, err := application.GetAppFromRequest(c)
+		if err != nil {
+			goerrors.Log().Warn("fatal err: %w", err)
+			c.AbortWithStatus(http.StatusBadGateway)
+			return
+		}
+
+		bearerToken := c.Request.Header.Get("Authorization")
+		_, err = ah.jwtClient.ExtractTokenMetadata(bearerToken)
+		if err != nil {
+			goerrors.Log().WithError(err).Error("ExtractTokenMetadata error")
+			errorModel.Error.Message = err.Error()
+			c.JSON(http.StatusUnauthorized, errorModel)
+			return
+		}
+
+		contestID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			goerrors.Log().WithError(err).Error("Parse contest id error")
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		contest, err := app.TransitionContest(contestID, to)
+		if err != nil {
+			goerrors.Log().WithError(err).Errorf("contest transition to %s error", to)
+			errorModel.Error.Message = "contest transition error: " + err.Error()
+			c.JSON(transitionErrorStatus(err), errorModel)
+			return
+		}
+		c.JSON(http.StatusOK, contest)
+	}
+}
+
+func transitionErrorStatus(err error) int {
+	if errors.Is(err, service.ErrTransitionNotAllowed) || errors.Is(err, repository.ErrStatusChanged) {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,44 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/dwnGnL/pg-contests/internal/repository"
+	"github.com/dwnGnL/pg-contests/lib/goerrors"
+	"github.com/gin-gonic/gin"
+)
+
+func (ah *adminHandler) getContestPayouts(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	app, contestID, ok := ah.idRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+
+	payouts, err := app.GetContestPayouts(contestID)
+	if err != nil {
+		goerrors.Log().WithError(err).Error("get contest payouts error")
+		errorModel.Error.Message = "get contest payouts error: " + err.Error()
+		c.JSON(http.StatusInternalServerError, errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, payouts)
+}
+
+// retryPayouts повторить зачисление неудавшихся выплат, в ответе выплаты после попытки
+func (ah *adminHandler) retryPayouts(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	app, contestID, ok := ah.idRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+
+	payouts, err := app.RetryPayouts(contestID)
+	if err != nil {
+		goerrors.Log().WithError(err).Error("retry contest payouts error")
+		errorModel.Error.Message = "retry contest payouts error: " + err.Error()
+		c.JSON(http.StatusInternalServerError, errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, payouts)
+}
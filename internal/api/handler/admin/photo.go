@@ -0,0 +1,125 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/dwnGnL/pg-contests/internal/repository"
+	"github.com/dwnGnL/pg-contests/internal/service"
+	"github.com/dwnGnL/pg-contests/lib/goerrors"
+	"github.com/dwnGnL/pg-contests/lib/storage"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// uploadPhoto загрузка фото multipart-формой, файл в поле file
+func (ah *adminHandler) uploadPhoto(ownerType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		errorModel := repository.ErrorResponse{}
+		app, ownerID, ok := ah.idRequest(c, &errorModel)
+		if !ok {
+			return
+		}
+
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			goerrors.Log().WithError(err).Error("read form file error")
+			errorModel.Error.Message = "read form file error: " + err.Error()
+			c.JSON(http.StatusBadRequest, errorModel)
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			goerrors.Log().WithError(err).Error("open form file error")
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		defer file.Close()
+
+		photo, err := app.UploadPhoto(c.Request.Context(), ownerType, ownerID, fileHeader.Filename, file)
+		if err != nil {
+			goerrors.Log().WithError(err).Error("upload photo error")
+			errorModel.Error.Message = "upload photo error: " + err.Error()
+			c.JSON(photoErrorStatus(err), errorModel)
+			return
+		}
+		c.JSON(http.StatusOK, photo)
+	}
+}
+
+// presignPhoto первая фаза двухфазной загрузки: клиент получает ссылку и сам загружает файл PUT-запросом
+func (ah *adminHandler) presignPhoto(ownerType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		errorModel := repository.ErrorResponse{}
+		app, ownerID, ok := ah.idRequest(c, &errorModel)
+		if !ok {
+			return
+		}
+
+		var request service.PhotoUpload
+		if err := c.ShouldBindJSON(&request); err != nil {
+			goerrors.Log().WithError(err).Error("bind request error")
+			errorModel.Error.Message = "bind request error: " + err.Error()
+			c.JSON(http.StatusBadRequest, errorModel)
+			return
+		}
+
+		presigned, err := app.PresignPhoto(c.Request.Context(), ownerType, ownerID, request)
+		if err != nil {
+			goerrors.Log().WithError(err).Error("presign photo error")
+			errorModel.Error.Message = "presign photo error: " + err.Error()
+			c.JSON(photoErrorStatus(err), errorModel)
+			return
+		}
+		c.JSON(http.StatusOK, presigned)
+	}
+}
+
+// completePhoto вторая фаза: файл загружен, проверяем его и делаем превью
+func (ah *adminHandler) completePhoto(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	app, photoID, ok := ah.idRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+
+	photo, err := app.CompletePhoto(c.Request.Context(), photoID)
+	if err != nil {
+		goerrors.Log().WithError(err).Error("complete photo error")
+		errorModel.Error.Message = "complete photo error: " + err.Error()
+		c.JSON(photoErrorStatus(err), errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, photo)
+}
+
+func (ah *adminHandler) deletePhoto(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	app, photoID, ok := ah.idRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+
+	if err := app.DeletePhoto(c.Request.Context(), photoID); err != nil {
+		goerrors.Log().WithError(err).Error("delete photo error")
+		errorModel.Error.Message = "delete photo error: " + err.Error()
+		c.JSON(photoErrorStatus(err), errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Photo deleted"})
+}
+
+func photoErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidImage), errors.Is(err, service.ErrUnknownPhotoOwnerType):
+		return http.StatusBadRequest
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, storage.ErrNotFound):
+		//клиент еще не загрузил файл по ссылке
+		return http.StatusConflict
+	case errors.Is(err, service.ErrStorageNotConfigured):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,75 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/dwnGnL/pg-contests/internal/repository"
+	"github.com/dwnGnL/pg-contests/internal/service"
+	"github.com/dwnGnL/pg-contests/lib/goerrors"
+	"github.com/gin-gonic/gin"
+)
+
+func (ah *adminHandler) getContestRefunds(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	app, contestID, ok := ah.idRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+
+	refunds, err := app.GetContestRefunds(contestID)
+	if err != nil {
+		goerrors.Log().WithError(err).Error("get contest refunds error")
+		errorModel.Error.Message = "get contest refunds error: " + err.Error()
+		c.JSON(http.StatusInternalServerError, errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, refunds)
+}
+
+// retryRefunds повторить неудавшиеся возвраты, в ответе возвраты после попытки
+func (ah *adminHandler) retryRefunds(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	app, contestID, ok := ah.idRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+
+	refunds, err := app.RetryRefunds(contestID)
+	if err != nil {
+		goerrors.Log().WithError(err).Error("retry contest refunds error")
+		errorModel.Error.Message = "retry contest refunds error: " + err.Error()
+		c.JSON(http.StatusInternalServerError, errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, refunds)
+}
+
+// refundPlayer вернуть оплату одному участнику
+func (ah *adminHandler) refundPlayer(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	app, contestID, ok := ah.idRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+	userID, err := strconv.ParseInt(c.Param("userID"), 10, 64)
+	if err != nil {
+		goerrors.Log().WithError(err).Error("Parse userID error")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	refund, err := app.RefundPlayer(contestID, userID)
+	if err != nil {
+		goerrors.Log().WithError(err).Error("refund player error")
+		errorModel.Error.Message = "refund player error: " + err.Error()
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrNotPaid) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, refund)
+}
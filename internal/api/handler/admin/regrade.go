@@ -0,0 +1,80 @@
+package admin
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/dwnGnL/pg-contests/internal/repository"
+	"github.com/dwnGnL/pg-contests/internal/service"
+	"github.com/dwnGnL/pg-contests/lib/goerrors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// regradeQuestion перепроверка вопроса; void - аннулировать его, из тела берется только reason
+func (ah *adminHandler) regradeQuestion(void bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		errorModel := repository.ErrorResponse{}
+		var request repository.QuestionRegrade
+		if err := c.ShouldBindJSON(&request); err != nil && !(void && errors.Is(err, io.EOF)) {
+			goerrors.Log().WithError(err).Error("bind request error")
+			errorModel.Error.Message = "bind request error: " + err.Error()
+			c.JSON(http.StatusBadRequest, errorModel)
+			return
+		}
+		app, tokenDetails, ok := ah.adminRequest(c, &errorModel)
+		if !ok {
+			return
+		}
+		questionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			goerrors.Log().WithError(err).Error("Parse question id error")
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		if void {
+			voided := true
+			request = repository.QuestionRegrade{Voided: &voided, Reason: request.Reason}
+		}
+
+		question, err := app.RegradeQuestion(questionID, request, strconv.FormatInt(tokenDetails.ID, 10))
+		if err != nil {
+			goerrors.Log().WithError(err).Error("regrade question error")
+			errorModel.Error.Message = "regrade question error: " + err.Error()
+			c.JSON(regradeErrorStatus(err), errorModel)
+			return
+		}
+		c.JSON(http.StatusOK, question)
+	}
+}
+
+func (ah *adminHandler) getContestAudit(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	app, contestID, ok := ah.idRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+
+	audit, err := app.GetContestAudit(contestID)
+	if err != nil {
+		goerrors.Log().WithError(err).Error("get contest audit error")
+		errorModel.Error.Message = "get contest audit error: " + err.Error()
+		c.JSON(http.StatusInternalServerError, errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, audit)
+}
+
+func regradeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, repository.ErrInvalidRegrade):
+		return http.StatusBadRequest
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrPayoutsCreated):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
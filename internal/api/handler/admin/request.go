@@ -0,0 +1,47 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dwnGnL/pg-contests/internal/application"
+	"github.com/dwnGnL/pg-contests/internal/repository"
+	"github.com/dwnGnL/pg-contests/lib/goerrors"
+	"github.com/gin-gonic/gin"
+)
+
+// adminRequest общая часть хендлеров: приложение и токен админа; при ошибке ответ уже записан
+func (ah *adminHandler) adminRequest(c *gin.Context, errorModel *repository.ErrorResponse) (application.Core, AdminAccessDetails, bool) {
+	app, err := application.GetAppFromRequest(c)
+	if err != nil {
+		goerrors.Log().Warn("fatal err: %w", err)
+		c.AbortWithStatus(http.StatusBadGateway)
+		return nil, AdminAccessDetails{}, false
+	}
+
+	bearerToken := c.Request.Header.Get("Authorization")
+	tokenDetails, err := ah.jwtClient.ExtractTokenMetadata(bearerToken)
+	if err != nil {
+		goerrors.Log().WithError(err).Error("ExtractTokenMetadata error")
+		errorModel.Error.Message = err.Error()
+		c.JSON(http.StatusUnauthorized, errorModel)
+		return nil, AdminAccessDetails{}, false
+	}
+	return app, tokenDetails, true
+}
+
+// idRequest то же, что adminRequest, плюс id из пути
+func (ah *adminHandler) idRequest(c *gin.Context, errorModel *repository.ErrorResponse) (application.Core, int64, bool) {
+	app, _, ok := ah.adminRequest(c, errorModel)
+	if !ok {
+		return nil, 0, false
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		goerrors.Log().WithError(err).Error("Parse id error")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return nil, 0, false
+	}
+	return app, id, true
+}
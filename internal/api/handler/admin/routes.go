@@ -2,6 +2,7 @@ package admin
 
 import (
 	"github.com/dwnGnL/pg-contests/internal/config"
+	"github.com/dwnGnL/pg-contests/internal/repository"
 	"github.com/dwnGnL/pg-contests/lib/token"
 	"github.com/gin-gonic/gin"
 )
@@ -24,8 +25,59 @@ func GenRouting(r *gin.RouterGroup, cfg *config.Config) {
 	r.GET("/contests", admin.getAllContest)
 	r.GET("/contest/:id", admin.getContestById)
 	r.GET("/contest/:id/changeStatus", admin.changeStatus)
+	r.POST("/contest/:id/schedule", admin.transitionContest(repository.StatusScheduled))
+	r.POST("/contest/:id/unschedule", admin.transitionContest(repository.StatusDraft))
+	r.POST("/contest/:id/finish", admin.transitionContest(repository.StatusFinished))
+	r.POST("/contest/:id/cancel", admin.transitionContest(repository.StatusCancelled))
+	r.POST("/contest/:id/archive", admin.transitionContest(repository.StatusArchived))
+	r.POST("/contest/:id/host", admin.hostAction)
+	r.GET("/contest/:id/host", admin.getHostEvents)
 	r.DELETE("/contest/:id", admin.deleteContestById)
 	r.PUT("/contest", admin.updateContest)
+	r.GET("/contest/:id/export", admin.exportContest)
+	r.POST("/contest/import", admin.importContest)
+	r.POST("/contest/:id/clone", admin.cloneContest)
+	r.POST("/contest/:id/photos", admin.uploadPhoto(repository.PhotoOwnerContests))
+	r.POST("/contest/:id/photos/presign", admin.presignPhoto(repository.PhotoOwnerContests))
+	r.POST("/question/:id/void", admin.regradeQuestion(true))
+	r.POST("/question/:id/regrade", admin.regradeQuestion(false))
+	r.GET("/contest/:id/audit", admin.getContestAudit)
+	r.GET("/contest/:id/payouts", admin.getContestPayouts)
+	r.POST("/contest/:id/payouts/retry", admin.retryPayouts)
+	r.GET("/contest/:id/refunds", admin.getContestRefunds)
+	r.POST("/contest/:id/refunds/retry", admin.retryRefunds)
+	r.POST("/contest/:id/refunds/:userID", admin.refundPlayer)
+	r.POST("/contest/:id/tickets", admin.issueTickets)
+	r.GET("/contest/:id/tickets", admin.getContestTickets)
+	r.POST("/ticket/:id/cancel", admin.cancelTicket)
+	r.POST("/promo", admin.createPromoCode)
+	r.GET("/promos", admin.getPromoCodes)
+	r.DELETE("/promo/:id", admin.deletePromoCode)
+	r.POST("/question/:id/photos", admin.uploadPhoto(repository.PhotoOwnerQuestions))
+	r.POST("/question/:id/photos/presign", admin.presignPhoto(repository.PhotoOwnerQuestions))
+	r.POST("/answer/:id/photos", admin.uploadPhoto(repository.PhotoOwnerAnswers))
+	r.POST("/answer/:id/photos/presign", admin.presignPhoto(repository.PhotoOwnerAnswers))
+	r.POST("/photos/:id/complete", admin.completePhoto)
+	r.DELETE("/photos/:id", admin.deletePhoto)
+	r.POST("/bank/question", admin.createBankQuestion)
+	r.GET("/bank/questions", admin.searchBankQuestions)
+	r.GET("/bank/question/:id", admin.getBankQuestion)
+	r.PUT("/bank/question/:id", admin.updateBankQuestion)
+	r.DELETE("/bank/question/:id", admin.deleteBankQuestion)
+	r.GET("/bank/tags", admin.getBankTags)
+	r.POST("/template", admin.createTemplate)
+	r.GET("/templates", admin.getTemplates)
+	r.GET("/template/:id", admin.getTemplate)
+	r.PUT("/template/:id", admin.updateTemplate)
+	r.DELETE("/template/:id", admin.deleteTemplate)
+	r.POST("/template/:id/instantiate", admin.instantiateTemplate)
+	r.POST("/schedule", admin.createSchedule)
+	r.GET("/schedules", admin.getSchedules)
+	r.GET("/schedule/:id", admin.getSchedule)
+	r.PUT("/schedule/:id", admin.updateSchedule)
+	r.POST("/schedule/:id/pause", admin.pauseSchedule(true))
+	r.POST("/schedule/:id/resume", admin.pauseSchedule(false))
+	r.DELETE("/schedule/:id", admin.deleteSchedule)
 	r.POST("/migrate", admin.migrate)
 
 }
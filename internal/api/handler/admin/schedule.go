@@ -0,0 +1,141 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/dwnGnL/pg-contests/internal/repository"
+	"github.com/dwnGnL/pg-contests/lib/goerrors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func (ah *adminHandler) createSchedule(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	var request repository.ContestSchedule
+	if err := c.ShouldBindJSON(&request); err != nil {
+		goerrors.Log().WithError(err).Error("bind request error")
+		errorModel.Error.Message = "bind request error: " + err.Error()
+		c.JSON(http.StatusBadRequest, errorModel)
+		return
+	}
+	app, tokenDetails, ok := ah.adminRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+
+	request.CreatedBy = strconv.FormatInt(tokenDetails.ID, 10)
+	schedule, err := app.CreateSchedule(request)
+	if err != nil {
+		goerrors.Log().WithError(err).Error("create schedule error")
+		errorModel.Error.Message = "create schedule error: " + err.Error()
+		c.JSON(scheduleErrorStatus(err), errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, schedule)
+}
+
+func (ah *adminHandler) getSchedules(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	app, _, ok := ah.adminRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+
+	schedules, err := app.GetSchedules(repository.GetPaginateSettings(c.Request))
+	if err != nil {
+		goerrors.Log().WithError(err).Error("get schedules error")
+		errorModel.Error.Message = "get schedules error: " + err.Error()
+		c.JSON(http.StatusInternalServerError, errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, schedules)
+}
+
+func (ah *adminHandler) getSchedule(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	app, scheduleID, ok := ah.idRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+
+	schedule, err := app.GetSchedule(scheduleID)
+	if err != nil {
+		goerrors.Log().WithError(err).Error("get schedule error")
+		errorModel.Error.Message = "get schedule error: " + err.Error()
+		c.JSON(scheduleErrorStatus(err), errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, schedule)
+}
+
+func (ah *adminHandler) updateSchedule(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	var request repository.ContestSchedule
+	if err := c.ShouldBindJSON(&request); err != nil {
+		goerrors.Log().WithError(err).Error("bind request error")
+		errorModel.Error.Message = "bind request error: " + err.Error()
+		c.JSON(http.StatusBadRequest, errorModel)
+		return
+	}
+	app, scheduleID, ok := ah.idRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+
+	request.ID = scheduleID
+	schedule, err := app.UpdateSchedule(request)
+	if err != nil {
+		goerrors.Log().WithError(err).Error("update schedule error")
+		errorModel.Error.Message = "update schedule error: " + err.Error()
+		c.JSON(scheduleErrorStatus(err), errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, schedule)
+}
+
+// pauseSchedule paused=false - возобновить
+func (ah *adminHandler) pauseSchedule(paused bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		errorModel := repository.ErrorResponse{}
+		app, scheduleID, ok := ah.idRequest(c, &errorModel)
+		if !ok {
+			return
+		}
+
+		schedule, err := app.PauseSchedule(scheduleID, paused)
+		if err != nil {
+			goerrors.Log().WithError(err).Error("pause schedule error")
+			errorModel.Error.Message = "pause schedule error: " + err.Error()
+			c.JSON(scheduleErrorStatus(err), errorModel)
+			return
+		}
+		c.JSON(http.StatusOK, schedule)
+	}
+}
+
+// deleteSchedule уже созданные по расписанию конкурсы остаются
+func (ah *adminHandler) deleteSchedule(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	app, scheduleID, ok := ah.idRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+
+	if err := app.DeleteSchedule(scheduleID); err != nil {
+		goerrors.Log().WithError(err).Error("delete schedule error")
+		errorModel.Error.Message = "delete schedule error: " + err.Error()
+		c.JSON(scheduleErrorStatus(err), errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Schedule deleted"})
+}
+
+// scheduleErrorStatus не найдено расписание или его шаблон - 404, остальное - ошибки валидации
+func scheduleErrorStatus(err error) int {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
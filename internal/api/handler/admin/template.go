@@ -0,0 +1,191 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/dwnGnL/pg-contests/internal/repository"
+	"github.com/dwnGnL/pg-contests/internal/service"
+	"github.com/dwnGnL/pg-contests/lib/goerrors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// cloneContest копия конкурса в новый черновик: {"start_time": ..., "title": ...}
+func (ah *adminHandler) cloneContest(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	var request service.DraftOptions
+	if err := c.ShouldBindJSON(&request); err != nil {
+		goerrors.Log().WithError(err).Error("bind request error")
+		errorModel.Error.Message = "bind request error: " + err.Error()
+		c.JSON(http.StatusBadRequest, errorModel)
+		return
+	}
+	app, tokenDetails, ok := ah.adminRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+	contestID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		goerrors.Log().WithError(err).Error("Parse id error")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	request.CreatedBy = strconv.FormatInt(tokenDetails.ID, 10)
+	contest, err := app.CloneContest(c.Request.Context(), contestID, request)
+	if err != nil {
+		goerrors.Log().WithError(err).Error("clone contest error")
+		errorModel.Error.Message = "clone contest error: " + err.Error()
+		c.JSON(templateErrorStatus(err), errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, contest)
+}
+
+func (ah *adminHandler) createTemplate(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	var request repository.ContestTemplate
+	if err := c.ShouldBindJSON(&request); err != nil {
+		goerrors.Log().WithError(err).Error("bind request error")
+		errorModel.Error.Message = "bind request error: " + err.Error()
+		c.JSON(http.StatusBadRequest, errorModel)
+		return
+	}
+	app, tokenDetails, ok := ah.adminRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+
+	request.CreatedBy = strconv.FormatInt(tokenDetails.ID, 10)
+	template, err := app.CreateTemplate(request)
+	if err != nil {
+		goerrors.Log().WithError(err).Error("create template error")
+		errorModel.Error.Message = "create template error: " + err.Error()
+		c.JSON(http.StatusBadRequest, errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, template)
+}
+
+func (ah *adminHandler) getTemplates(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	app, _, ok := ah.adminRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+
+	templates, err := app.GetTemplates(repository.GetPaginateSettings(c.Request))
+	if err != nil {
+		goerrors.Log().WithError(err).Error("get templates error")
+		errorModel.Error.Message = "get templates error: " + err.Error()
+		c.JSON(http.StatusInternalServerError, errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, templates)
+}
+
+func (ah *adminHandler) getTemplate(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	app, templateID, ok := ah.idRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+
+	template, err := app.GetTemplate(templateID)
+	if err != nil {
+		goerrors.Log().WithError(err).Error("get template error")
+		errorModel.Error.Message = "get template error: " + err.Error()
+		c.JSON(templateErrorStatus(err), errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, template)
+}
+
+func (ah *adminHandler) updateTemplate(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	var request repository.ContestTemplate
+	if err := c.ShouldBindJSON(&request); err != nil {
+		goerrors.Log().WithError(err).Error("bind request error")
+		errorModel.Error.Message = "bind request error: " + err.Error()
+		c.JSON(http.StatusBadRequest, errorModel)
+		return
+	}
+	app, templateID, ok := ah.idRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+
+	request.ID = templateID
+	template, err := app.UpdateTemplate(request)
+	if err != nil {
+		goerrors.Log().WithError(err).Error("update template error")
+		errorModel.Error.Message = "update template error: " + err.Error()
+		c.JSON(templateErrorStatus(err), errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, template)
+}
+
+// deleteTemplate конкурсы, созданные по шаблону, остаются
+func (ah *adminHandler) deleteTemplate(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	app, templateID, ok := ah.idRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+
+	if err := app.DeleteTemplate(templateID); err != nil {
+		goerrors.Log().WithError(err).Error("delete template error")
+		errorModel.Error.Message = "delete template error: " + err.Error()
+		c.JSON(templateErrorStatus(err), errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Template deleted"})
+}
+
+// instantiateTemplate черновик следующего выпуска: {"start_time": ..., "title": ...}
+func (ah *adminHandler) instantiateTemplate(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	var request service.DraftOptions
+	if err := c.ShouldBindJSON(&request); err != nil {
+		goerrors.Log().WithError(err).Error("bind request error")
+		errorModel.Error.Message = "bind request error: " + err.Error()
+		c.JSON(http.StatusBadRequest, errorModel)
+		return
+	}
+	app, tokenDetails, ok := ah.adminRequest(c, &errorModel)
+	if !ok {
+		return
+	}
+	templateID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		goerrors.Log().WithError(err).Error("Parse id error")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	request.CreatedBy = strconv.FormatInt(tokenDetails.ID, 10)
+	contest, err := app.InstantiateTemplate(templateID, request)
+	if err != nil {
+		goerrors.Log().WithError(err).Error("instantiate template error")
+		errorModel.Error.Message = "instantiate template error: " + err.Error()
+		c.JSON(templateErrorStatus(err), errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, contest)
+}
+
+// templateErrorStatus не найден шаблон или конкурс - 404, шаблон используется расписанием - 409, не хватило вопросов в банке - 400
+func templateErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, repository.ErrTemplateInUse):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrInvalidBankDraw), errors.Is(err, repository.ErrNotEnoughBankQuestions):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
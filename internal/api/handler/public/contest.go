@@ -1,8 +1,11 @@
 package public
 
 import (
+	"errors"
 	"github.com/dwnGnL/pg-contests/internal/application"
+	"github.com/dwnGnL/pg-contests/internal/payment"
 	"github.com/dwnGnL/pg-contests/internal/repository"
+	"github.com/dwnGnL/pg-contests/internal/service"
 	"github.com/dwnGnL/pg-contests/lib/goerrors"
 	"github.com/gin-gonic/gin"
 	"net/http"
@@ -81,13 +84,39 @@ func (ph *publicHandler) subscribeContestById(c *gin.Context) {
 	request.UserID = tokenDetails.ID
 
 	err = app.SubscribeContest(&request, jwtToken)
+	if errors.Is(err, service.ErrPaymentPending) {
+		//место за участником, подписка подтвердится после сверки оплаты
+		c.JSON(http.StatusAccepted, gin.H{"message": err.Error(), "payment_status": repository.PaymentPending})
+		return
+	}
 	if err != nil {
 		goerrors.Log().WithError(err).Error("subscribe contest error")
 		errorModel.Error.Message = "subscribe contest error: " + err.Error()
-		c.JSON(http.StatusInternalServerError, errorModel)
+		c.JSON(subscribeErrorStatus(err), errorModel)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "Success"})
+	c.JSON(http.StatusOK, gin.H{"message": "Success", "price": request.Price})
+}
+
+func subscribeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, repository.ErrAlreadySubscribed), errors.Is(err, repository.ErrTicketUnavailable),
+		errors.Is(err, service.ErrAttemptWindowClosed), errors.Is(err, repository.ErrTeamFull), errors.Is(err, repository.ErrTeamNameTaken):
+		return http.StatusConflict
+	case errors.Is(err, repository.ErrPromoNotFound), errors.Is(err, repository.ErrPromoUnavailable),
+		errors.Is(err, service.ErrTeamRequired), errors.Is(err, repository.ErrTeamNotFound):
+		return http.StatusBadRequest
+	case errors.Is(err, payment.ErrInsufficientFunds), errors.Is(err, payment.ErrDeclined):
+		return http.StatusPaymentRequired
+	case errors.Is(err, payment.ErrUnavailable):
+		//место остается за участником в pending до сверки оплаты
+		return http.StatusServiceUnavailable
+	case errors.Is(err, payment.ErrUnauthorized):
+		//API баланса не принял токен сервиса или игрока, платить тут нечем - чинить надо настройки
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
 }
 
 func (ph *publicHandler) getContestStatsById(c *gin.Context) {
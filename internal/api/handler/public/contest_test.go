@@ -0,0 +1,36 @@
+package public
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/dwnGnL/pg-contests/internal/payment"
+	"github.com/dwnGnL/pg-contests/internal/repository"
+	"github.com/dwnGnL/pg-contests/internal/service"
+)
+
+func TestSubscribeErrorStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{fmt.Errorf("withdraw balance err: %w", payment.ErrInsufficientFunds), http.StatusPaymentRequired},
+		{fmt.Errorf("withdraw balance err: %w", payment.ErrDeclined), http.StatusPaymentRequired},
+		{fmt.Errorf("subscription stays pending: %w", payment.ErrUnavailable), http.StatusServiceUnavailable},
+		{fmt.Errorf("withdraw balance err: %w", payment.ErrUnauthorized), http.StatusBadGateway},
+		{repository.ErrAlreadySubscribed, http.StatusConflict},
+		{service.ErrAttemptWindowClosed, http.StatusConflict},
+		{repository.ErrPromoUnavailable, http.StatusBadRequest},
+		{service.ErrTeamRequired, http.StatusBadRequest},
+		{repository.ErrTeamNotFound, http.StatusBadRequest},
+		{repository.ErrTeamFull, http.StatusConflict},
+		{fmt.Errorf("create team: %w", repository.ErrTeamNameTaken), http.StatusConflict},
+		{fmt.Errorf("db is down"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		if got := subscribeErrorStatus(tt.err); got != tt.want {
+			t.Errorf("subscribeErrorStatus(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
@@ -29,6 +29,7 @@ func GenRouting(r *gin.RouterGroup, cfg *config.Config) {
 	r.GET("/contest/:id/stats", public.getContestStatsById)
 	r.GET("/contest/:id/userStats", public.getContestStatsForUser)
 	r.GET("/contest/:id/fullUserStats", public.getContestFullStatsForUser)
+	r.GET("/contest/:id/teams", public.getContestTeams)
 
 	//ws
 	r.Any("/connect/:contestID", public.wsContest)
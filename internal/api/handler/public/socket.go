@@ -82,8 +82,8 @@ func (ws publicHandler) wsContest(c *gin.Context) {
 		conn.Close()
 		return
 	}
-	if *contest.IsEnd {
-		conn.WriteJSON(app.Generate(contestID))
+	if contest.Status == repository.StatusFinished {
+		conn.WriteJSON(service.PersonalizeResponse(app.GenerateAttempt(contestID, tokenDetails.ID), contestID, tokenDetails.ID))
 		conn.WriteMessage(websocket.CloseMessage, []byte{})
 		return
 	}
@@ -93,6 +93,8 @@ func (ws publicHandler) wsContest(c *gin.Context) {
 	conn.SetPongHandler(func(string) error { conn.SetReadDeadline(time.Now().Add(pongWait)); return nil })
 	go func() {
 		for {
+			//поля прошлого ответа не должны протечь в следующий
+			*req = apiModels.WsRequest{}
 			err := conn.ReadJSON(req)
 			// if errors.Is(err,websocket.ErrBadHandshake)
 
@@ -102,12 +104,12 @@ func (ws publicHandler) wsContest(c *gin.Context) {
 				conn.Close()
 				break
 			}
-			if req.AnswerID == 0 || req.QuestionID == 0 {
+			if !req.HasAnswer() {
 				continue
 			}
 			//записать ответ на текущий вопрос в бд
 			//посчитать время ответа на текущий вопрос оно должно быть от 0 до question.time
-			curTime, err := app.CalculateTimeForQuestion(contestID, req.QuestionID)
+			curTime, err := app.CalculateTimeForQuestion(contestID, tokenDetails.ID, req.QuestionID)
 			if err != nil {
 				conn.WriteJSON(models.WsResponse{ErrorCode: 1, ErrorMess: "получение времени конкурса " + err.Error()}) // any model
 				goerrors.Log().WithError(err).Error("CalculateTimeForQuestion error")
@@ -134,9 +136,19 @@ func (ws publicHandler) wsContest(c *gin.Context) {
 				ContestID:  contestID,
 				QuestionID: req.QuestionID,
 				AnswerID:   req.AnswerID,
+				AnswerIDs:  req.AnswerIDs,
+				Value:      req.Value,
 				Time:       curTime,
 			}
 			err = app.SubmitAnswer(&userAnswer)
+			if errors.Is(err, service.ErrEliminated) {
+				conn.WriteJSON(models.WsResponse{ErrorCode: 3, ErrorMess: err.Error()})
+				continue
+			}
+			if errors.Is(err, service.ErrNotCaptain) || errors.Is(err, service.ErrNoTeam) {
+				conn.WriteJSON(models.WsResponse{ErrorCode: 4, ErrorMess: err.Error()})
+				continue
+			}
 			if err != nil {
 				conn.WriteJSON(models.WsResponse{ErrorCode: 1, ErrorMess: "SubmitAnswer error " + err.Error()}) // any model
 				goerrors.Log().WithError(err).Error("SubmitAnswer error")
@@ -147,17 +159,25 @@ func (ws publicHandler) wsContest(c *gin.Context) {
 
 	// запись
 	go func() {
+		//в своем темпе таймлайн у каждого участника свой, общих событий нет
+		if contest.SelfPaced() {
+			attemptTimeline(conn, app, contestID, tokenDetails.ID)
+			return
+		}
+		//таймлайн может вести другой инстанс, поэтому текущее состояние отдаем сразу, не дожидаясь события
+		conn.WriteJSON(service.PersonalizeResponse(app.Generate(contestID), contestID, tokenDetails.ID))
 		switcher, ok := ws.contestMap.Load(contestID)
 		if ok && !switcher.End {
-			conn.WriteJSON(app.Generate(contestID))
-			switcher.subscribers.Add(conn)
+			switcher.subscribers.Add(conn, tokenDetails.ID)
 			return
 		}
-		ch := app.GenerateAndProcessChan(contestID)
+		events, unsubscribe := app.SubscribeContestEvents(contestID)
 		subscriber := new(subscribers)
-		subscriber.Add(conn)
+		subscriber.Add(conn, tokenDetails.ID)
 		switcher = &subscribeSwitcher{
-			event:       ch,
+			contestID:   contestID,
+			event:       events,
+			unsubscribe: unsubscribe,
 			subscribers: subscriber,
 		}
 		ws.contestMap.Store(contestID, switcher)
@@ -0,0 +1,38 @@
+package public
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dwnGnL/pg-contests/internal/application"
+	"github.com/dwnGnL/pg-contests/internal/repository"
+	"github.com/dwnGnL/pg-contests/lib/goerrors"
+	"github.com/gin-gonic/gin"
+)
+
+// getContestTeams команды конкурса, чтобы при подписке выбрать, в какую вступить
+func (ph *publicHandler) getContestTeams(c *gin.Context) {
+	errorModel := repository.ErrorResponse{}
+	app, err := application.GetAppFromRequest(c)
+	if err != nil {
+		goerrors.Log().Warn("fatal err: %w", err)
+		c.AbortWithStatus(http.StatusBadGateway)
+		return
+	}
+
+	contestID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		goerrors.Log().WithError(err).Error("Parse contest id error")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	teams, err := app.GetContestTeams(contestID)
+	if err != nil {
+		goerrors.Log().WithError(err).Error("get contest teams error")
+		errorModel.Error.Message = "get contest teams error: " + err.Error()
+		c.JSON(http.StatusInternalServerError, errorModel)
+		return
+	}
+	c.JSON(http.StatusOK, teams)
+}
@@ -5,12 +5,16 @@ import (
 	"time"
 
 	"github.com/dwnGnL/pg-contests/internal/api/models"
+	"github.com/dwnGnL/pg-contests/internal/application"
+	"github.com/dwnGnL/pg-contests/internal/service"
 	"github.com/dwnGnL/pg-contests/lib/goerrors"
 	"github.com/gorilla/websocket"
 )
 
 type subscribeSwitcher struct {
+	contestID   int64
 	event       <-chan models.WsResponse
+	unsubscribe func()
 	subscribers *subscribers
 	End         bool
 }
@@ -20,11 +24,12 @@ const writeWait = 10 * time.Second
 func (s *subscribeSwitcher) ReceiveEvent() {
 	ticker := time.NewTicker(pingPeriod)
 	defer func() {
-		s.subscribers.Each(func(_ int, conn *websocket.Conn) {
-			conn.SetWriteDeadline(time.Now().Add(writeWait))
-			conn.Close()
+		s.subscribers.Each(func(_ int, sub subscriber) {
+			sub.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			sub.conn.Close()
 		})
 		s.End = true
+		s.unsubscribe()
 		ticker.Stop()
 	}()
 	for {
@@ -33,23 +38,26 @@ func (s *subscribeSwitcher) ReceiveEvent() {
 			if !ok {
 				return
 			}
-			s.subscribers.Each(func(_ int, conn *websocket.Conn) {
-				conn.SetWriteDeadline(time.Now().Add(writeWait))
-				err := conn.WriteJSON(resp)
+			s.subscribers.Each(func(_ int, sub subscriber) {
+				if resp.TeamAnswer != nil && !resp.TeamAnswer.HasMember(sub.userID) {
+					return
+				}
+				sub.conn.SetWriteDeadline(time.Now().Add(writeWait))
+				err := sub.conn.WriteJSON(service.PersonalizeResponse(resp, s.contestID, sub.userID))
 				if err != nil {
 					goerrors.Log().Warnf("writeJson err:%s", err.Error())
 				}
 				if resp.ContestStatus == models.End {
-					conn.Close()
+					sub.conn.Close()
 				}
 			})
 			if resp.ContestStatus == models.End {
 				return
 			}
 		case <-ticker.C:
-			s.subscribers.Each(func(_ int, conn *websocket.Conn) {
-				conn.SetWriteDeadline(time.Now().Add(writeWait))
-				if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+			s.subscribers.Each(func(_ int, sub subscriber) {
+				sub.conn.SetWriteDeadline(time.Now().Add(writeWait))
+				if err := sub.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
 					return
 				}
 			})
@@ -58,13 +66,49 @@ func (s *subscribeSwitcher) ReceiveEvent() {
 	}
 }
 
-func (s *subscribers) Add(conn *websocket.Conn) {
+// attemptTimeline ведет таймлайн попытки участника на его соединении до конца попытки.
+// Отмену конкурса участник увидит по окончании текущего вопроса
+func attemptTimeline(conn *websocket.Conn, app application.Core, contestID, userID int64) {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		conn.SetWriteDeadline(time.Now().Add(writeWait))
+		conn.Close()
+		ticker.Stop()
+	}()
+	for {
+		resp := app.GenerateAttempt(contestID, userID)
+		conn.SetWriteDeadline(time.Now().Add(writeWait))
+		if err := conn.WriteJSON(service.PersonalizeResponse(resp, contestID, userID)); err != nil {
+			goerrors.Log().Warnf("writeJson err:%s", err.Error())
+			return
+		}
+		if resp.ContestStatus == models.End || resp.ContestStatus == 0 {
+			return
+		}
+		next := time.NewTimer(time.Duration(resp.CountDown) * time.Second)
+	wait:
+		for {
+			select {
+			case <-next.C:
+				break wait
+			case <-ticker.C:
+				conn.SetWriteDeadline(time.Now().Add(writeWait))
+				if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+					next.Stop()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *subscribers) Add(conn *websocket.Conn, userID int64) {
 	s.Lock()
 	defer s.Unlock()
-	s.wsConnections = append(s.wsConnections, conn)
+	s.wsConnections = append(s.wsConnections, subscriber{conn: conn, userID: userID})
 }
 
-func (s *subscribers) Each(fn func(i int, conn *websocket.Conn)) {
+func (s *subscribers) Each(fn func(i int, sub subscriber)) {
 	s.Lock()
 	defer s.Unlock()
 	for i, v := range s.wsConnections {
@@ -74,5 +118,11 @@ func (s *subscribers) Each(fn func(i int, conn *websocket.Conn)) {
 
 type subscribers struct {
 	sync.RWMutex
-	wsConnections []*websocket.Conn
+	wsConnections []subscriber
+}
+
+// subscriber соединение участника, userID нужен для его порядка вариантов
+type subscriber struct {
+	conn   *websocket.Conn
+	userID int64
 }
@@ -9,9 +9,15 @@ const (
 )
 
 type WsRequest struct {
-	Token      string `json:"token"`
-	QuestionID int64  `json:"question_id"`
-	AnswerID   int64  `json:"answer_id"`
+	Token      string  `json:"token"`
+	QuestionID int64   `json:"question_id"`
+	AnswerID   int64   `json:"answer_id"`  // single
+	AnswerIDs  []int64 `json:"answer_ids"` // multi, ordering - в выбранном порядке
+	Value      string  `json:"value"`      // numeric, text
+}
+
+func (r *WsRequest) HasAnswer() bool {
+	return r.QuestionID != 0 && (r.AnswerID != 0 || len(r.AnswerIDs) != 0 || r.Value != "")
 }
 
 type WsResponse struct {
@@ -22,6 +28,14 @@ type WsResponse struct {
 	CountDown        int64         `json:"count_down"`
 	TotalTime        int64         `json:"total_time"`
 	Questions        []WsQuestion  `json:"questions"`
+	ShuffleAnswers   bool          `json:"shuffle_answers,omitempty"` // варианты перемешаны для каждого участника свои
+	Survivors        []WsSurvivors `json:"survivors,omitempty"`       // конкурс на выбывание: сколько осталось после каждого закрытого вопроса
+	Eliminated       bool          `json:"eliminated,omitempty"`      // участник выбыл, может только смотреть
+	EliminatedIDs    []int64       `json:"eliminated_ids,omitempty"`  // для шины, PersonalizeResponse превращает в Eliminated и не отдает клиенту
+	TeamAnswer       *WsTeamAnswer `json:"team_answer,omitempty"`     // событие только для участников команды, без состояния таймлайна
+	Paused           bool          `json:"paused,omitempty"`          // ведущий поставил вопрос на паузу, CountDown не идет
+	HostAction       string        `json:"host_action,omitempty"`     // событие вызвано действием ведущего: pause, resume, skip, extend, void
+	ResultsChanged   bool          `json:"results_changed,omitempty"` // вопрос перепроверен, баллы и таблицу надо перезапросить
 	ErrorCode        int           `json:"error_code"`
 	ErrorMess        string        `json:"error_msg"`
 }
@@ -29,11 +43,47 @@ type WsResponse struct {
 type WsQuestion struct {
 	ID      int64      `json:"id"`
 	Order   int        `json:"order"`
+	Type    string     `json:"type"`
 	Title   string     `json:"title"`
+	Photos  []WsPhoto  `json:"photos,omitempty"`
 	Answers []WsAnswer `json:"answers"`
+	Voided  bool       `json:"voided,omitempty"` // аннулирован ведущим или правкой проверки, баллов не дает
 }
 
 type WsAnswer struct {
-	ID    int64  `json:"id"`
-	Title string `json:"title"`
+	ID     int64     `json:"id"`
+	Title  string    `json:"title"`
+	Photos []WsPhoto `json:"photos,omitempty"`
+}
+
+type WsSurvivors struct {
+	QuestionID int64 `json:"question_id"`
+	Step       int   `json:"step"`
+	Count      int   `json:"count"`
+}
+
+// WsTeamAnswer ответ команды на вопрос, который еще идет
+type WsTeamAnswer struct {
+	TeamID     int64   `json:"team_id"`
+	QuestionID int64   `json:"question_id"`
+	UserID     int64   `json:"user_id"` // кто из команды ответил
+	UserName   string  `json:"user_name,omitempty"`
+	AnswerID   int64   `json:"answer_id,omitempty"`
+	AnswerIDs  []int64 `json:"answer_ids,omitempty"`
+	Value      string  `json:"value,omitempty"`
+	Members    []int64 `json:"members"`
+}
+
+func (a *WsTeamAnswer) HasMember(userID int64) bool {
+	for _, member := range a.Members {
+		if member == userID {
+			return true
+		}
+	}
+	return false
+}
+
+type WsPhoto struct {
+	Link          string `json:"link"`
+	ThumbnailLink string `json:"thumbnail_link,omitempty"`
 }
@@ -19,10 +19,14 @@ import (
 
 type GracefulStopFuncWithCtx func(ctx context.Context) error
 
-func SetupHandlers(core application.Core, cfg *config.Config) GracefulStopFuncWithCtx {
+// SetupHandlers files - раздача загруженных файлов локальным хранилищем, nil если файлы отдает внешнее хранилище
+func SetupHandlers(core application.Core, cfg *config.Config, files http.Handler) GracefulStopFuncWithCtx {
 	c := gin.New()
 
 	c.Use(application.WithApp(core), application.WithCORS())
+	if files != nil {
+		c.Any("/files/*key", gin.WrapH(http.StripPrefix("/files", files)))
+	}
 	apiv1 := c.Group("/api/v1/")
 	// apiv1.Use() добавить проверку токена
 	generateAPIV1Routing(apiv1, cfg)
@@ -1,8 +1,13 @@
 package application
 
 import (
+	"context"
+	"io"
+
 	"github.com/dwnGnL/pg-contests/internal/api/models"
+	"github.com/dwnGnL/pg-contests/internal/contestio"
 	"github.com/dwnGnL/pg-contests/internal/repository"
+	"github.com/dwnGnL/pg-contests/internal/service"
 )
 
 type Core interface {
@@ -14,14 +19,57 @@ type Core interface {
 	GetContest(contestID int64) (*repository.Contest, error)
 	DeleteContest(contestID int64) error
 	CreateContest(contest repository.Contest) (*repository.Contest, error)
-	UpdateContest(contest repository.Contest) (*repository.Contest, error)
-	ChangeStatus(contestID int64) (newStatus bool, err error)
+	UpdateContest(contest repository.Contest, force bool) (*repository.Contest, error)
+	ChangeStatus(contestID int64) (repository.ContestStatus, error)
+	TransitionContest(contestID int64, to repository.ContestStatus) (*repository.Contest, error)
+	HostAction(contestID int64, event repository.HostEvent) (*models.WsResponse, error)
+	GetHostEvents(contestID int64) ([]repository.HostEvent, error)
+	RegradeQuestion(questionID int64, regrade repository.QuestionRegrade, by string) (*repository.Question, error)
+	GetContestAudit(contestID int64) ([]repository.ContestAudit, error)
+	GetContestPayouts(contestID int64) ([]repository.Payout, error)
+	RetryPayouts(contestID int64) ([]repository.Payout, error)
+	GetContestRefunds(contestID int64) ([]repository.Refund, error)
+	RetryRefunds(contestID int64) ([]repository.Refund, error)
+	RefundPlayer(contestID, userID int64) (*repository.Refund, error)
+	IssueTickets(contestID int64, userIDs []int64, by string) ([]repository.UserTickets, error)
+	GetContestTickets(contestID int64) ([]repository.UserTickets, error)
+	CancelTicket(ticketID int64) (*repository.UserTickets, error)
+	CreatePromoCode(promo repository.PromoCode) (*repository.PromoCode, error)
+	GetPromoCodes() ([]repository.PromoCode, error)
+	DeletePromoCode(promoID int64) error
 	CheckAndReturnContestByUserID(contestID, userID int64) (*repository.Contest, error)
-	GenerateAndProcessChan(contestID int64) <-chan models.WsResponse
+	SubscribeContestEvents(contestID int64) (<-chan models.WsResponse, func())
 	Generate(contestID int64) models.WsResponse
+	GenerateAttempt(contestID, userID int64) models.WsResponse
 	Migrate() error
 	SubscribeContest(userContest *repository.UserContests, jwtToken string) error
-	CalculateTimeForQuestion(contestID, questionID int64) (int64, error)
-	GetCurrentQuestion(contestID int64) (repository.Question, error)
+	GetContestTeams(contestID int64) ([]repository.Team, error)
+	CalculateTimeForQuestion(contestID, userID, questionID int64) (int64, error)
+	GetCurrentQuestion(contestID, userID int64) (repository.Question, error)
 	SubmitAnswer(userAnswer *repository.UserAnswers) (err error)
+	UploadPhoto(ctx context.Context, ownerType string, ownerID int64, fileName string, r io.Reader) (*repository.Photo, error)
+	PresignPhoto(ctx context.Context, ownerType string, ownerID int64, upload service.PhotoUpload) (*service.PresignedPhoto, error)
+	CompletePhoto(ctx context.Context, photoID int64) (*repository.Photo, error)
+	DeletePhoto(ctx context.Context, photoID int64) error
+	CreateBankQuestion(question repository.BankQuestion) (*repository.BankQuestion, error)
+	GetBankQuestion(questionID int64) (*repository.BankQuestion, error)
+	SearchBankQuestions(filter repository.BankFilter, pagination *repository.Pagination) (*repository.Pagination, error)
+	UpdateBankQuestion(question repository.BankQuestion) (*repository.BankQuestion, error)
+	DeleteBankQuestion(questionID int64) error
+	GetBankTags() ([]repository.BankTag, error)
+	ExportContest(contestID int64, format contestio.Format, w io.Writer) error
+	ImportContest(r io.Reader, format contestio.Format, base repository.Contest, dryRun bool) (*service.ImportResult, error)
+	CloneContest(ctx context.Context, contestID int64, opts service.DraftOptions) (*repository.Contest, error)
+	CreateTemplate(template repository.ContestTemplate) (*repository.ContestTemplate, error)
+	GetTemplate(templateID int64) (*repository.ContestTemplate, error)
+	GetTemplates(pagination *repository.Pagination) (*repository.Pagination, error)
+	UpdateTemplate(template repository.ContestTemplate) (*repository.ContestTemplate, error)
+	DeleteTemplate(templateID int64) error
+	InstantiateTemplate(templateID int64, opts service.DraftOptions) (*repository.Contest, error)
+	CreateSchedule(schedule repository.ContestSchedule) (*repository.ContestSchedule, error)
+	GetSchedule(scheduleID int64) (*repository.ContestSchedule, error)
+	GetSchedules(pagination *repository.Pagination) (*repository.Pagination, error)
+	UpdateSchedule(schedule repository.ContestSchedule) (*repository.ContestSchedule, error)
+	PauseSchedule(scheduleID int64, paused bool) (*repository.ContestSchedule, error)
+	DeleteSchedule(scheduleID int64) error
 }
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/dwnGnL/pg-contests/internal/config"
+	"github.com/dwnGnL/pg-contests/internal/contestio"
+	"github.com/dwnGnL/pg-contests/internal/repository"
+	"github.com/dwnGnL/pg-contests/internal/service"
+)
+
+func ExportContest(conf *config.Config, contestID int64, format contestio.Format, w io.Writer) error {
+	repo, err := repository.NewRepository(conf)
+	if err != nil {
+		return fmt.Errorf("new repository err:%w", err)
+	}
+	return service.New(conf, repo).ExportContest(contestID, format, w)
+}
+
+// ImportContest печатает результат в w; ошибки в файле возвращаются ошибкой, чтобы команда завершилась с ненулевым кодом
+func ImportContest(conf *config.Config, r io.Reader, format contestio.Format, base repository.Contest, dryRun bool, w io.Writer) error {
+	repo, err := repository.NewRepository(conf)
+	if err != nil {
+		return fmt.Errorf("new repository err:%w", err)
+	}
+	result, err := service.New(conf, repo).ImportContest(r, format, base, dryRun)
+	if err != nil {
+		return err
+	}
+	for _, rowErr := range result.Errors {
+		fmt.Fprintln(w, rowErr.Error())
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("contest file has %d errors", len(result.Errors))
+	}
+	if dryRun {
+		fmt.Fprintf(w, "ok: %d questions\n", len(result.Contest.Questions))
+		return nil
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result.Contest)
+}
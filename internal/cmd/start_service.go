@@ -3,17 +3,20 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
 	"github.com/dwnGnL/pg-contests/internal/api"
-	"github.com/dwnGnL/pg-contests/internal/application"
 	"github.com/dwnGnL/pg-contests/internal/config"
+	"github.com/dwnGnL/pg-contests/internal/eventbus"
+	"github.com/dwnGnL/pg-contests/internal/payment"
 	"github.com/dwnGnL/pg-contests/internal/repository"
 	"github.com/dwnGnL/pg-contests/internal/service"
 	"github.com/dwnGnL/pg-contests/lib/goerrors"
+	"github.com/dwnGnL/pg-contests/lib/storage"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -24,13 +27,27 @@ const (
 func StartService(ctx context.Context, cfg *config.Config) error {
 	ctx, cancelCtx := context.WithCancel(ctx)
 	defer cancelCtx()
-	s, err := buildService(ctx, cfg)
+	store, err := buildStorage(cfg)
+	if err != nil {
+		return fmt.Errorf("build storage err:%w", err)
+	}
+	s, err := buildService(ctx, cfg, store)
 	if err != nil {
 		return fmt.Errorf("build service err:%w", err)
 	}
-	httpgrpcGracefulStopWithCtx := api.SetupHandlers(s, cfg)
+	httpgrpcGracefulStopWithCtx := api.SetupHandlers(s, cfg, storage.FileHandler(store))
 	var group errgroup.Group
 
+	group.Go(func() error {
+		s.StartScheduler(ctx)
+		return nil
+	})
+
+	group.Go(func() error {
+		s.StartReconciler(ctx)
+		return nil
+	})
+
 	group.Go(func() error {
 		sigCh := make(chan os.Signal, 1)
 		signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
@@ -59,10 +76,119 @@ func StartMigrate(conf *config.Config) error {
 	return repo.Migrate()
 }
 
-func buildService(ctx context.Context, conf *config.Config) (application.Core, error) {
+func StartMigrateDown(conf *config.Config, steps int) error {
+	repo, err := repository.NewRepository(conf)
+	if err != nil {
+		return fmt.Errorf("new repository err:%w", err)
+	}
+	return repo.MigrateDown(steps)
+}
+
+func PrintMigrationStatus(conf *config.Config, w io.Writer) error {
+	repo, err := repository.NewRepository(conf)
+	if err != nil {
+		return fmt.Errorf("new repository err:%w", err)
+	}
+	statuses, err := repo.MigrationStatus()
+	if err != nil {
+		return err
+	}
+	for _, status := range statuses {
+		applied := "pending"
+		if status.AppliedAt != nil {
+			applied = status.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%04d_%s\t%s\n", status.Version, status.Name, applied)
+	}
+	return nil
+}
+
+func buildService(ctx context.Context, conf *config.Config, store storage.Storage) (*service.ServiceImpl, error) {
 	repo, err := repository.NewRepository(conf)
 	if err != nil {
 		return nil, fmt.Errorf("new repository err:%w", err)
 	}
-	return service.New(conf, repo), nil
+	bus, err := buildEventBus(conf)
+	if err != nil {
+		return nil, fmt.Errorf("build event bus err:%w", err)
+	}
+	go func() {
+		<-ctx.Done()
+		_ = bus.Close()
+	}()
+	payments, err := buildPayments(conf)
+	if err != nil {
+		return nil, fmt.Errorf("build payments err:%w", err)
+	}
+	return service.New(conf, repo, service.WithBus(bus), service.WithStorage(store), service.WithPayments(payments)), nil
+}
+
+func buildPayments(conf *config.Config) (payment.Provider, error) {
+	switch conf.Payments.Provider {
+	case "", "balance":
+		//без токена выплаты, возвраты и сверка уходили бы с пустым Authorization
+		if conf.Payments.ServiceToken == "" {
+			return nil, fmt.Errorf("Payments.ServiceToken is required for the balance provider")
+		}
+		return payment.NewBalance(payment.BalanceConfig{
+			URL:          conf.ApiURL,
+			ServiceToken: conf.Payments.ServiceToken,
+			WithdrawPath: conf.Payments.WithdrawPath,
+			CreditPath:   conf.Payments.CreditPath,
+			RefundPath:   conf.Payments.RefundPath,
+			StatusPath:   conf.Payments.StatusPath,
+			Timeout:      conf.Payments.Timeout,
+			Retries:      conf.Payments.Retries,
+			RetryDelay:   conf.Payments.RetryDelay,
+		}), nil
+	case "fake":
+		return payment.NewFake(conf.Payments.FakeBalance), nil
+	default:
+		return nil, fmt.Errorf("unknown payment provider %q", conf.Payments.Provider)
+	}
+}
+
+func buildEventBus(conf *config.Config) (eventbus.Bus, error) {
+	switch conf.EventBus {
+	case "", "postgres":
+		return eventbus.NewPostgres(conf.DB.DSN)
+	case "memory":
+		return eventbus.NewMemory(), nil
+	default:
+		return nil, fmt.Errorf("unknown event bus %q", conf.EventBus)
+	}
+}
+
+func buildStorage(conf *config.Config) (storage.Storage, error) {
+	switch conf.Storage.Backend {
+	case "", "local":
+		dir := conf.Storage.Dir
+		if dir == "" {
+			dir = "uploads"
+		}
+		baseURL := conf.Storage.BaseURL
+		if baseURL == "" {
+			baseURL = fmt.Sprintf("http://localhost:%d/files", conf.ListenPort)
+		}
+		signKey := conf.Storage.SignKey
+		if signKey == "" {
+			signKey = conf.AdminPrivKey
+		}
+		maxPut := conf.Storage.MaxSize
+		if maxPut <= 0 {
+			maxPut = 5 << 20
+		}
+		return storage.NewLocal(dir, baseURL, []byte(signKey), maxPut)
+	case "s3":
+		return storage.NewS3(storage.S3Config{
+			Endpoint:  conf.Storage.S3.Endpoint,
+			Region:    conf.Storage.S3.Region,
+			Bucket:    conf.Storage.S3.Bucket,
+			AccessKey: conf.Storage.S3.AccessKey,
+			SecretKey: conf.Storage.S3.SecretKey,
+			PublicURL: conf.Storage.S3.PublicURL,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", conf.Storage.Backend)
+	}
 }
@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 type Config struct {
 	LogLevel      string
 	DB            Database
@@ -7,8 +9,56 @@ type Config struct {
 	ApiURL        string
 	AdminPrivKey  string
 	PublicPrivKey string
+	EventBus      string // postgres (по умолчанию) или memory
+	Scheduler     Scheduler
+	Storage       Storage
+	Payments      Payments
 }
 
 type Database struct {
 	DSN string
 }
+
+type Scheduler struct {
+	Interval time.Duration // как часто перечитывать список конкурсов
+	Horizon  time.Duration // за сколько до старта начинать вести таймлайн конкурса
+
+	RecurringInterval time.Duration // как часто создавать конкурсы по расписаниям
+	RecurringHorizon  time.Duration // на сколько вперед создавать конкурсы по расписаниям
+}
+
+type Storage struct {
+	Backend    string        // local (по умолчанию) или s3
+	Dir        string        // каталог для local
+	BaseURL    string        // адрес раздачи файлов local, по умолчанию http://localhost:ListenPort/files
+	SignKey    string        // ключ подписи ссылок на загрузку для local, по умолчанию AdminPrivKey
+	MaxSize    int64         // максимальный размер картинки в байтах
+	Thumbnail  int           // большая сторона превью в пикселях
+	PresignTTL time.Duration // время жизни ссылки на загрузку
+	S3         S3
+}
+
+type Payments struct {
+	Provider     string // balance (по умолчанию) - API баланса по ApiURL, или fake - в памяти для локального запуска
+	ServiceToken string // Authorization для операций, которые сервис делает сам: выплаты, возвраты, сверка оплат; для balance обязателен
+	WithdrawPath string // пути API баланса, пустые - пути по умолчанию из payment.Balance
+	CreditPath   string
+	RefundPath   string
+	StatusPath   string
+	Timeout      time.Duration // таймаут запроса к API баланса, по умолчанию 15s
+	Retries      int           // попыток запроса к API баланса, по умолчанию 3
+	RetryDelay   time.Duration // пауза перед первым повтором, дальше удваивается
+	FakeBalance  float64       // стартовый баланс игрока в fake
+
+	ReconcileInterval time.Duration // как часто проверять неподтвержденные оплаты подписок
+	PendingTimeout    time.Duration // через сколько неподтвержденная оплата считается зависшей
+}
+
+type S3 struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PublicURL string
+}
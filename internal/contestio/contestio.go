@@ -0,0 +1,183 @@
+// Package contestio импорт и экспорт конкурса с вопросами в JSON, CSV и Moodle GIFT
+package contestio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dwnGnL/pg-contests/internal/repository"
+)
+
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatCSV  Format = "csv"
+	FormatGIFT Format = "gift"
+)
+
+var ErrUnknownFormat = errors.New("unknown contest file format")
+
+// ParseFormat формат по имени; если имя пустое - по расширению файла
+func ParseFormat(name, fileName string) (Format, error) {
+	if name == "" {
+		name = strings.TrimPrefix(filepath.Ext(fileName), ".")
+	}
+	switch format := Format(strings.ToLower(name)); format {
+	case FormatJSON, FormatCSV, FormatGIFT:
+		return format, nil
+	case "txt":
+		return FormatGIFT, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownFormat, name)
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case FormatJSON:
+		return "application/json"
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	}
+	return "text/plain; charset=utf-8"
+}
+
+// RowError ошибка строки исходного файла; Row 0 - сам конкурс, а не вопрос
+type RowError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+func (e RowError) Error() string {
+	return fmt.Sprintf("row %d: %s", e.Row, e.Message)
+}
+
+// Result разобранный файл: конкурс и для каждого вопроса номер строки, с которой он начинается
+type Result struct {
+	Contest repository.Contest
+	Rows    []int
+	Errors  []RowError
+}
+
+// Import разбирает файл; в base поля конкурса, которых нет в CSV и GIFT (название, цена, время старта)
+func Import(r io.Reader, format Format, base repository.Contest) (*Result, error) {
+	var (
+		result *Result
+		err    error
+	)
+	switch format {
+	case FormatJSON:
+		result, err = importJSON(r, base)
+	case FormatCSV:
+		result, err = importCSV(r, base)
+	case FormatGIFT:
+		result, err = importGIFT(r, base)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	result.Errors = append(result.Errors, Validate(&result.Contest, result.Rows)...)
+	return result, nil
+}
+
+// Export пишет конкурс без id и полей жизненного цикла, чтобы файл можно было импортировать обратно
+func Export(w io.Writer, contest *repository.Contest, format Format) error {
+	switch format {
+	case FormatJSON:
+		return exportJSON(w, contest)
+	case FormatCSV:
+		return exportCSV(w, contest)
+	case FormatGIFT:
+		return exportGIFT(w, contest)
+	}
+	return fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+}
+
+// Validate проверяет конкурс через Contest.Validate отдельно для каждого вопроса, чтобы показать все ошибки сразу
+func Validate(contest *repository.Contest, rows []int) []RowError {
+	var rowErrors []RowError
+	head := *contest
+	head.Questions = nil
+	headErr := head.Validate()
+	if headErr == nil && strings.TrimSpace(head.Title) == "" {
+		headErr = errors.New("title is required")
+	}
+	if headErr != nil {
+		rowErrors = append(rowErrors, RowError{Row: 0, Message: headErr.Error()})
+	}
+	for i, question := range contest.Questions {
+		var err error
+		if headErr == nil {
+			single := head
+			single.Questions = []repository.Question{question}
+			err = single.Validate()
+		} else {
+			//ошибка самого конкурса уже показана, по строке интересна только ошибка вопроса
+			err = question.Validate()
+		}
+		if err == nil {
+			continue
+		}
+		if inner := errors.Unwrap(err); inner != nil {
+			err = inner
+		}
+		row := i + 1
+		if i < len(rows) {
+			row = rows[i]
+		}
+		rowErrors = append(rowErrors, RowError{Row: row, Message: err.Error()})
+	}
+	return rowErrors
+}
+
+// sortedAnswers варианты в порядке файла: для ordering - в правильном порядке, иначе по id
+func sortedAnswers(question repository.Question) []repository.Answer {
+	answers := make([]repository.Answer, len(question.Answers))
+	copy(answers, question.Answers)
+	ordering := question.Kind() == repository.QuestionOrdering
+	sort.SliceStable(answers, func(i, j int) bool {
+		if ordering {
+			return answers[i].Position < answers[j].Position
+		}
+		return answers[i].ID < answers[j].ID
+	})
+	return answers
+}
+
+func photoLinks(photos []repository.Photo) []string {
+	links := make([]string, 0, len(photos))
+	for _, photo := range photos {
+		if photo.Link != "" {
+			links = append(links, photo.Link)
+		}
+	}
+	return links
+}
+
+// linkPhotos фото по ссылкам: файл остается там, где был, хранилище им не владеет
+func linkPhotos(links []string) []repository.Photo {
+	var photos []repository.Photo
+	for _, link := range links {
+		link = strings.TrimSpace(link)
+		if link == "" {
+			continue
+		}
+		uploaded := true
+		photos = append(photos, repository.Photo{
+			FileName: filepath.Base(link),
+			Uploaded: &uploaded,
+			Link:     link,
+		})
+	}
+	return photos
+}
+
+func boolPtr(v bool) *bool {
+	return &v
+}
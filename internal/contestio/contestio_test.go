@@ -0,0 +1,209 @@
+package contestio
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dwnGnL/pg-contests/internal/repository"
+)
+
+func testBase() repository.Contest {
+	return repository.Contest{Title: "Квиз", Price: 10, StartTime: time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)}
+}
+
+// testContest по вопросу каждого типа; id вариантов в порядке файла, как после sortedAnswers
+func testContest() repository.Contest {
+	contest := testBase()
+	contest.ID = 7
+	contest.Status = repository.StatusFinished
+	contest.Questions = []repository.Question{
+		{
+			ID: 1, ContestID: 7, Order: 1, Type: repository.QuestionSingle, Title: "2+2=? {жирным}", Score: 10, Time: 30,
+			Photos: []repository.Photo{{ID: 3, FileName: "q.png", Link: "https://cdn.example.com/q.png"}},
+			Answers: []repository.Answer{
+				{ID: 1, Title: "3", IsCorrect: boolPtr(false)},
+				{ID: 2, Title: "4", IsCorrect: boolPtr(true)},
+			},
+		},
+		{
+			ID: 2, ContestID: 7, Order: 2, Type: repository.QuestionMulti, Title: "Простые числа", Score: 20, Time: 45, PartialCredit: true,
+			Answers: []repository.Answer{
+				{ID: 3, Title: "2", IsCorrect: boolPtr(true)},
+				{ID: 4, Title: "4", IsCorrect: boolPtr(false)},
+				{ID: 5, Title: "5", IsCorrect: boolPtr(true)},
+			},
+		},
+		{
+			ID: 3, ContestID: 7, Order: 3, Type: repository.QuestionNumeric, Title: "Число пи", Score: 5, Time: 20, Tolerance: 0.01,
+			Answers: []repository.Answer{{ID: 6, Title: "3.14", IsCorrect: boolPtr(true)}},
+		},
+		{
+			ID: 4, ContestID: 7, Order: 4, Type: repository.QuestionText, Title: "Столица Франции", Score: 5, Time: 20,
+			Answers: []repository.Answer{
+				{ID: 7, Title: "Париж", IsCorrect: boolPtr(true)},
+				{ID: 8, Title: "Paris", IsCorrect: boolPtr(true)},
+			},
+		},
+		{
+			ID: 5, ContestID: 7, Order: 5, Type: repository.QuestionOrdering, Title: "По возрастанию", Score: 15, Time: 60,
+			Answers: []repository.Answer{
+				{ID: 9, Title: "десять", IsCorrect: boolPtr(false), Position: 2},
+				{ID: 10, Title: "один", IsCorrect: boolPtr(false), Position: 1},
+				{ID: 11, Title: "сто", IsCorrect: boolPtr(false), Position: 3},
+			},
+		},
+	}
+	return contest
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, format := range []Format{FormatJSON, FormatCSV, FormatGIFT} {
+		t.Run(string(format), func(t *testing.T) {
+			contest := testContest()
+			var buf bytes.Buffer
+			if err := Export(&buf, &contest, format); err != nil {
+				t.Fatalf("export: %v", err)
+			}
+			result, err := Import(&buf, format, testBase())
+			if err != nil {
+				t.Fatalf("import: %v", err)
+			}
+			if len(result.Errors) > 0 {
+				t.Fatalf("import errors: %v", result.Errors)
+			}
+			want := cleanContest(contest)
+			if format == FormatJSON {
+				if !reflect.DeepEqual(result.Contest, want) {
+					t.Fatalf("contest = %+v, want %+v", result.Contest, want)
+				}
+				return
+			}
+			if result.Contest.Title != "Квиз" || !result.Contest.StartTime.Equal(testBase().StartTime) {
+				t.Fatalf("contest fields must come from base, got %q %v", result.Contest.Title, result.Contest.StartTime)
+			}
+			if len(result.Contest.Questions) != len(want.Questions) {
+				t.Fatalf("questions = %d, want %d", len(result.Contest.Questions), len(want.Questions))
+			}
+			for i, question := range result.Contest.Questions {
+				if !reflect.DeepEqual(question, want.Questions[i]) {
+					t.Errorf("question %d = %+v, want %+v", i+1, question, want.Questions[i])
+				}
+			}
+		})
+	}
+}
+
+func TestImportRowErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		format    Format
+		base      repository.Contest
+		input     string
+		questions int
+		want      []RowError
+	}{
+		{
+			name:   "json syntax",
+			format: FormatJSON,
+			base:   testBase(),
+			input:  `{"title": "Квиз", "questions": [}`,
+			want:   []RowError{{Row: 0}},
+		},
+		{
+			name:      "json contest without start time",
+			format:    FormatJSON,
+			input:     `{"title": "Квиз", "questions": [{"title": "Вопрос", "score": 1, "time": 10, "answers": [{"title": "Да", "is_correct": true}]}]}`,
+			questions: 1,
+			want:      []RowError{{Row: 0, Message: "start_time is required"}},
+		},
+		{
+			name:   "csv missing column",
+			format: FormatCSV,
+			base:   testBase(),
+			input:  "title,time\nВопрос,10\n",
+			want:   []RowError{{Row: 1, Message: `column "score" is required`}},
+		},
+		{
+			name:   "csv bad rows are skipped",
+			format: FormatCSV,
+			base:   testBase(),
+			input: "title,score,time,correct,answer_1,answer_2\n" +
+				"Первый,10,30,1,Да,Нет\n" +
+				"Второй,много,30,1,Да,Нет\n" +
+				"Третий,10,30,3,Да,Нет\n" +
+				"Четвертый,10,30,,Да,Нет\n",
+			questions: 2,
+			want: []RowError{
+				{Row: 3, Message: `invalid score "много"`},
+				{Row: 4, Message: `invalid correct answer number "3"`},
+				{Row: 5, Message: "вопрос без правильных ответов"},
+			},
+		},
+		{
+			name:   "csv broken quotes stop the import",
+			format: FormatCSV,
+			base:   testBase(),
+			input: "title,score,time,correct,answer_1\n" +
+				"Первый,10,30,1,Да\n" +
+				"Вто\"рой,10,30,1,Да\n",
+			questions: 1,
+			want:      []RowError{{Row: 3}},
+		},
+		{
+			name:   "gift bad questions are skipped",
+			format: FormatGIFT,
+			base:   testBase(),
+			input: "// Квиз\n\n" +
+				"Первый {=Да ~Нет}\n\n" +
+				"Просто текст\n\n" +
+				"// pg-contests: score=0\n" +
+				"Третий {=Да ~Нет}\n\n" +
+				"Четвертый {\n=Да\n~Нет\n\n" +
+				"Пятый {}\n",
+			questions: 1,
+			want: []RowError{
+				{Row: 5, Message: "descriptions without answers are not supported"},
+				{Row: 8, Message: "score and time must be positive"},
+				{Row: 10, Message: "unterminated answer block"},
+				{Row: 14, Message: "essay questions are not supported"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Import(strings.NewReader(tt.input), tt.format, tt.base)
+			if err != nil {
+				t.Fatalf("import: %v", err)
+			}
+			if len(result.Contest.Questions) != tt.questions {
+				t.Errorf("questions = %d, want %d", len(result.Contest.Questions), tt.questions)
+			}
+			if len(result.Errors) != len(tt.want) {
+				t.Fatalf("errors = %v, want %v", result.Errors, tt.want)
+			}
+			for i, want := range tt.want {
+				got := result.Errors[i]
+				//сообщения encoding/json и encoding/csv не проверяем, только строку
+				if got.Row != want.Row || (want.Message != "" && got.Message != want.Message) {
+					t.Errorf("error %d = %v, want %v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateUsesSourceRows(t *testing.T) {
+	contest := testBase()
+	contest.Questions = []repository.Question{
+		{Title: "Первый", Score: 1, Time: 10, Answers: []repository.Answer{{Title: "Да", IsCorrect: boolPtr(true)}}},
+		{Title: "Второй", Score: 1, Time: 10, Type: repository.QuestionMulti, Answers: []repository.Answer{{Title: "Да", IsCorrect: boolPtr(true)}}},
+	}
+	got := Validate(&contest, []int{3, 9})
+	want := []RowError{{Row: 9, Message: "вопрос с несколькими ответами должен иметь минимум 2 варианта"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Validate = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,203 @@
+package contestio
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/dwnGnL/pg-contests/internal/repository"
+)
+
+// колонки CSV; варианты ответа в колонках answer_1..answer_N, для ordering - в правильном порядке
+var csvHeader = []string{"order", "type", "title", "score", "time", "tolerance", "partial_credit", "correct", "photos"}
+
+const csvAnswerPrefix = "answer_"
+
+func exportCSV(w io.Writer, contest *repository.Contest) error {
+	answersCount := 0
+	for _, question := range contest.Questions {
+		if len(question.Answers) > answersCount {
+			answersCount = len(question.Answers)
+		}
+	}
+	header := append([]string{}, csvHeader...)
+	for i := 1; i <= answersCount; i++ {
+		header = append(header, csvAnswerPrefix+strconv.Itoa(i))
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, question := range contest.Questions {
+		answers := sortedAnswers(question)
+		var correct []string
+		if question.Kind() != repository.QuestionOrdering {
+			for i, answer := range answers {
+				if answer.Correct() {
+					correct = append(correct, strconv.Itoa(i+1))
+				}
+			}
+		}
+		record := []string{
+			strconv.Itoa(question.Order),
+			string(question.Kind()),
+			question.Title,
+			strconv.Itoa(question.Score),
+			strconv.FormatInt(question.Time, 10),
+			strconv.FormatFloat(question.Tolerance, 'f', -1, 64),
+			strconv.FormatBool(question.PartialCredit),
+			strings.Join(correct, ";"),
+			strings.Join(photoLinks(question.Photos), ";"),
+		}
+		for i := 0; i < answersCount; i++ {
+			title := ""
+			if i < len(answers) {
+				title = answers[i].Title
+			}
+			record = append(record, title)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// importCSV конкурс берется из base, из файла только вопросы; строка с ошибкой разбора пропускается
+func importCSV(r io.Reader, base repository.Contest) (*Result, error) {
+	result := &Result{Contest: cleanContest(base)}
+	result.Contest.Questions = nil
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		result.Errors = append(result.Errors, RowError{Row: 1, Message: "empty file"})
+		return result, nil
+	}
+	if err != nil {
+		result.Errors = append(result.Errors, csvRowError(err, 1))
+		return result, nil
+	}
+	columns := make(map[string]int, len(header))
+	var answerColumns []int
+	for i, name := range header {
+		//Excel сохраняет CSV с BOM
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+		if strings.HasPrefix(name, csvAnswerPrefix) {
+			answerColumns = append(answerColumns, i)
+		}
+	}
+	for _, name := range []string{"title", "score", "time"} {
+		if _, ok := columns[name]; !ok {
+			result.Errors = append(result.Errors, RowError{Row: 1, Message: fmt.Sprintf("column %q is required", name)})
+		}
+	}
+	if len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			//после ошибки кавычек граница следующей строки уже не надежна
+			result.Errors = append(result.Errors, csvRowError(err, 0))
+			break
+		}
+		row, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		var answers []string
+		for _, i := range answerColumns {
+			if i < len(record) && strings.TrimSpace(record[i]) != "" {
+				answers = append(answers, strings.TrimSpace(record[i]))
+			}
+		}
+		if field("title") == "" && len(answers) == 0 {
+			continue
+		}
+		question, err := csvQuestion(field, answers, len(result.Contest.Questions)+1)
+		if err != nil {
+			result.Errors = append(result.Errors, RowError{Row: row, Message: err.Error()})
+			continue
+		}
+		result.Contest.Questions = append(result.Contest.Questions, question)
+		result.Rows = append(result.Rows, row)
+	}
+	return result, nil
+}
+
+func csvQuestion(field func(name string) string, answers []string, order int) (question repository.Question, err error) {
+	question = repository.Question{
+		Title:  field("title"),
+		Type:   repository.QuestionType(strings.ToLower(field("type"))),
+		Order:  order,
+		Photos: linkPhotos(strings.Split(field("photos"), ";")),
+	}
+	if question.Title == "" {
+		return question, errors.New("title is required")
+	}
+	if question.Score, err = strconv.Atoi(field("score")); err != nil || question.Score <= 0 {
+		return question, fmt.Errorf("invalid score %q", field("score"))
+	}
+	if question.Time, err = strconv.ParseInt(field("time"), 10, 64); err != nil || question.Time <= 0 {
+		return question, fmt.Errorf("invalid time %q", field("time"))
+	}
+	if value := field("order"); value != "" {
+		if question.Order, err = strconv.Atoi(value); err != nil {
+			return question, fmt.Errorf("invalid order %q", value)
+		}
+	}
+	if value := field("tolerance"); value != "" {
+		if question.Tolerance, err = strconv.ParseFloat(value, 64); err != nil {
+			return question, fmt.Errorf("invalid tolerance %q", value)
+		}
+	}
+	if value := field("partial_credit"); value != "" {
+		if question.PartialCredit, err = strconv.ParseBool(value); err != nil {
+			return question, fmt.Errorf("invalid partial_credit %q", value)
+		}
+	}
+
+	correct := make(map[int]bool)
+	for _, value := range strings.FieldsFunc(field("correct"), func(r rune) bool { return r == ';' || r == ',' }) {
+		index, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || index < 1 || index > len(answers) {
+			return question, fmt.Errorf("invalid correct answer number %q", value)
+		}
+		correct[index] = true
+	}
+	kind := question.Kind()
+	//у numeric и text обычно перечислены только допустимые ответы
+	allCorrect := len(correct) == 0 && (kind == repository.QuestionNumeric || kind == repository.QuestionText)
+	for i, title := range answers {
+		answer := repository.Answer{Title: title, IsCorrect: boolPtr(allCorrect || correct[i+1])}
+		if kind == repository.QuestionOrdering {
+			answer.Position = i + 1
+		}
+		question.Answers = append(question.Answers, answer)
+	}
+	return question, nil
+}
+
+func csvRowError(err error, row int) RowError {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return RowError{Row: parseErr.StartLine, Message: parseErr.Err.Error()}
+	}
+	return RowError{Row: row, Message: err.Error()}
+}
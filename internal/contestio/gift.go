@@ -0,0 +1,436 @@
+package contestio
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/dwnGnL/pg-contests/internal/repository"
+)
+
+// giftMetaPrefix комментарий перед вопросом с полями, которых нет в GIFT:
+// // pg-contests: type=multi score=10 time=30 partial_credit=true photos=url1,url2
+const giftMetaPrefix = "pg-contests:"
+
+const (
+	giftDefaultScore = 1
+	giftDefaultTime  = 30
+)
+
+// giftSpecial символы разметки GIFT, в тексте экранируются обратной косой чертой
+const giftSpecial = "~=#{}:\\"
+
+var giftFormats = map[string]bool{"[html]": true, "[moodle]": true, "[plain]": true, "[markdown]": true}
+
+func exportGIFT(w io.Writer, contest *repository.Contest) error {
+	writer := bufio.NewWriter(w)
+	if contest.Title != "" {
+		fmt.Fprintf(writer, "// %s\n\n", strings.Join(strings.Fields(contest.Title), " "))
+	}
+	for _, question := range contest.Questions {
+		kind := question.Kind()
+		meta := []string{
+			"type=" + string(kind),
+			"score=" + strconv.Itoa(question.Score),
+			"time=" + strconv.FormatInt(question.Time, 10),
+		}
+		if kind == repository.QuestionMulti || kind == repository.QuestionOrdering {
+			meta = append(meta, "partial_credit="+strconv.FormatBool(question.PartialCredit))
+		}
+		if links := photoLinks(question.Photos); len(links) > 0 {
+			meta = append(meta, "photos="+strings.Join(links, ","))
+		}
+		fmt.Fprintf(writer, "// %s %s\n", giftMetaPrefix, strings.Join(meta, " "))
+
+		answers := sortedAnswers(question)
+		correctCount := 0
+		for _, answer := range answers {
+			if answer.Correct() {
+				correctCount++
+			}
+		}
+		fmt.Fprintf(writer, "%s {\n", giftEscape(question.Title))
+		if kind == repository.QuestionNumeric {
+			writer.WriteString("#\n")
+		}
+		for _, answer := range answers {
+			title := giftEscape(answer.Title)
+			switch kind {
+			case repository.QuestionSingle:
+				if answer.Correct() {
+					fmt.Fprintf(writer, "\t=%s\n", title)
+				} else {
+					fmt.Fprintf(writer, "\t~%s\n", title)
+				}
+			case repository.QuestionMulti:
+				weight := "-100"
+				if answer.Correct() {
+					weight = strconv.FormatFloat(math.Round(100/float64(correctCount)*1e5)/1e5, 'f', -1, 64)
+				}
+				fmt.Fprintf(writer, "\t~%%%s%%%s\n", weight, title)
+			case repository.QuestionNumeric:
+				if answer.Correct() {
+					fmt.Fprintf(writer, "\t=%s:%s\n", answer.Title, strconv.FormatFloat(question.Tolerance, 'f', -1, 64))
+				}
+			case repository.QuestionText:
+				if answer.Correct() {
+					fmt.Fprintf(writer, "\t=%s\n", title)
+				}
+			case repository.QuestionOrdering:
+				fmt.Fprintf(writer, "\t=%d -> %s\n", answer.Position, title)
+			}
+		}
+		writer.WriteString("}\n\n")
+	}
+	return writer.Flush()
+}
+
+// importGIFT вопросы разделены пустыми строками; конкурс берется из base
+func importGIFT(r io.Reader, base repository.Contest) (*Result, error) {
+	result := &Result{Contest: cleanContest(base)}
+	result.Contest.Questions = nil
+
+	var (
+		text, meta []string
+		start      int
+	)
+	flush := func() {
+		defer func() { text, meta = nil, nil }()
+		if len(text) == 0 {
+			return
+		}
+		question, err := giftQuestion(strings.Join(text, "\n"), meta, len(result.Contest.Questions)+1)
+		if err != nil {
+			result.Errors = append(result.Errors, RowError{Row: start, Message: err.Error()})
+			return
+		}
+		result.Contest.Questions = append(result.Contest.Questions, question)
+		result.Rows = append(result.Rows, start)
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		value := strings.TrimSpace(scanner.Text())
+		if line == 1 {
+			value = strings.TrimPrefix(value, "\ufeff")
+		}
+		switch {
+		case value == "":
+			flush()
+		case strings.HasPrefix(value, "//"):
+			comment := strings.TrimSpace(strings.TrimPrefix(value, "//"))
+			if strings.HasPrefix(comment, giftMetaPrefix) {
+				meta = append(meta, strings.Fields(strings.TrimPrefix(comment, giftMetaPrefix))...)
+			}
+		case strings.HasPrefix(value, "$CATEGORY"):
+		default:
+			if len(text) == 0 {
+				start = line
+			}
+			text = append(text, value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return result, nil
+}
+
+func giftQuestion(text string, meta []string, order int) (question repository.Question, err error) {
+	question = repository.Question{Order: order, Score: giftDefaultScore, Time: giftDefaultTime}
+
+	if strings.HasPrefix(text, "::") {
+		end := giftIndex(text[2:], "::")
+		if end < 0 {
+			return question, errors.New("unterminated question name")
+		}
+		text = strings.TrimSpace(text[2+end+2:])
+	}
+	if end := strings.Index(text, "]"); strings.HasPrefix(text, "[") && end > 0 && giftFormats[strings.ToLower(text[:end+1])] {
+		text = text[end+1:]
+	}
+	open := giftIndex(text, "{")
+	if open < 0 {
+		return question, errors.New("descriptions without answers are not supported")
+	}
+	end := giftIndex(text[open:], "}")
+	if end < 0 {
+		return question, errors.New("unterminated answer block")
+	}
+	end += open
+	question.Title = giftUnescape(text[:open])
+	//вопрос с пропуском: "Столица Франции {=Париж} - город на Сене"
+	if rest := giftUnescape(text[end+1:]); rest != "" {
+		question.Title = strings.TrimSpace(question.Title + " _____ " + rest)
+	}
+
+	body := strings.TrimSpace(text[open+1 : end])
+	switch {
+	case body == "":
+		return question, errors.New("essay questions are not supported")
+	case strings.HasPrefix(body, "#"):
+		err = giftNumeric(&question, strings.TrimSpace(body[1:]))
+	case giftBoolean(body) != nil:
+		value := *giftBoolean(body)
+		question.Type = repository.QuestionSingle
+		question.Answers = []repository.Answer{
+			{Title: "Верно", IsCorrect: boolPtr(value)},
+			{Title: "Неверно", IsCorrect: boolPtr(!value)},
+		}
+	default:
+		err = giftChoice(&question, body)
+	}
+	if err != nil {
+		return question, err
+	}
+	return question, giftMeta(&question, meta)
+}
+
+func giftNumeric(question *repository.Question, body string) error {
+	question.Type = repository.QuestionNumeric
+	var values []string
+	if giftIndex(body, "=") < 0 {
+		values = []string{giftStripFeedback(body)}
+	} else {
+		tokens, err := giftTokens(body)
+		if err != nil {
+			return err
+		}
+		for _, token := range tokens {
+			//частично верные числа не поддерживаются, берем только полностью верные
+			if token.correct && (token.weight == nil || *token.weight >= 100) {
+				values = append(values, token.text)
+			}
+		}
+	}
+	for _, value := range values {
+		var number, tolerance float64
+		var err error
+		if low, high, ok := strings.Cut(value, ".."); ok {
+			var lowValue, highValue float64
+			lowValue, err = strconv.ParseFloat(strings.TrimSpace(low), 64)
+			if err == nil {
+				highValue, err = strconv.ParseFloat(strings.TrimSpace(high), 64)
+			}
+			number, tolerance = (lowValue+highValue)/2, math.Abs(highValue-lowValue)/2
+		} else {
+			numberValue, toleranceValue, _ := strings.Cut(value, ":")
+			number, err = strconv.ParseFloat(strings.TrimSpace(numberValue), 64)
+			if err == nil && strings.TrimSpace(toleranceValue) != "" {
+				tolerance, err = strconv.ParseFloat(strings.TrimSpace(toleranceValue), 64)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("invalid numeric answer %q", value)
+		}
+		//допуск у вопроса один, берем наибольший
+		question.Tolerance = math.Max(question.Tolerance, tolerance)
+		question.Answers = append(question.Answers, repository.Answer{
+			Title:     strconv.FormatFloat(number, 'f', -1, 64),
+			IsCorrect: boolPtr(true),
+		})
+	}
+	return nil
+}
+
+func giftChoice(question *repository.Question, body string) error {
+	tokens, err := giftTokens(body)
+	if err != nil {
+		return err
+	}
+	allCorrect, matching := true, true
+	correctCount, partial := 0, false
+	for _, token := range tokens {
+		allCorrect = allCorrect && token.correct
+		matching = matching && token.correct && strings.Contains(token.text, "->")
+		if token.weight != nil && *token.weight > 0 && *token.weight < 100 {
+			partial = true
+		}
+		if token.correct || (token.weight != nil && *token.weight > 0) {
+			correctCount++
+		}
+	}
+
+	switch {
+	case matching:
+		//сопоставление поддерживается только как порядок: "=1 -> первый"
+		question.Type = repository.QuestionOrdering
+		for _, token := range tokens {
+			left, right, _ := strings.Cut(token.text, "->")
+			position, err := strconv.Atoi(strings.TrimSpace(left))
+			if err != nil {
+				return errors.New("matching questions are supported only as ordering (=1 -> item)")
+			}
+			question.Answers = append(question.Answers, repository.Answer{
+				Title:     strings.TrimSpace(right),
+				IsCorrect: boolPtr(false),
+				Position:  position,
+			})
+		}
+		return nil
+	case allCorrect:
+		question.Type = repository.QuestionText
+	case correctCount > 1 || partial:
+		question.Type = repository.QuestionMulti
+		question.PartialCredit = partial
+	default:
+		question.Type = repository.QuestionSingle
+	}
+	for _, token := range tokens {
+		question.Answers = append(question.Answers, repository.Answer{
+			Title:     token.text,
+			IsCorrect: boolPtr(token.correct || (token.weight != nil && *token.weight > 0)),
+		})
+	}
+	return nil
+}
+
+// giftMeta поля из комментария pg-contests важнее выведенных из разметки
+func giftMeta(question *repository.Question, meta []string) (err error) {
+	for _, field := range meta {
+		key, value, _ := strings.Cut(field, "=")
+		switch key {
+		case "type":
+			question.Type = repository.QuestionType(value)
+		case "score":
+			question.Score, err = strconv.Atoi(value)
+		case "time":
+			question.Time, err = strconv.ParseInt(value, 10, 64)
+		case "partial_credit":
+			question.PartialCredit, err = strconv.ParseBool(value)
+		case "photos":
+			question.Photos = linkPhotos(strings.Split(value, ","))
+		default:
+			err = errors.New("unknown field")
+		}
+		if err != nil {
+			return fmt.Errorf("invalid %s %q", giftMetaPrefix, field)
+		}
+	}
+	if question.Score <= 0 || question.Time <= 0 {
+		return errors.New("score and time must be positive")
+	}
+	return nil
+}
+
+type giftToken struct {
+	correct bool     // '=' а не '~'
+	weight  *float64 // %50%, в процентах
+	text    string
+}
+
+// giftTokens варианты ответа: каждый начинается с неэкранированного '=' или '~'
+func giftTokens(body string) ([]giftToken, error) {
+	var (
+		tokens []giftToken
+		starts []int
+	)
+	for i := 0; i < len(body); i++ {
+		switch body[i] {
+		case '\\':
+			i++
+		case '=', '~':
+			starts = append(starts, i)
+		}
+	}
+	if len(starts) == 0 || strings.TrimSpace(body[:starts[0]]) != "" {
+		return nil, fmt.Errorf("unexpected text %q in answer block", body)
+	}
+	for i, start := range starts {
+		end := len(body)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		token := giftToken{correct: body[start] == '='}
+		text := strings.TrimSpace(giftStripFeedback(body[start+1 : end]))
+		if strings.HasPrefix(text, "%") {
+			weightEnd := strings.Index(text[1:], "%")
+			if weightEnd < 0 {
+				return nil, fmt.Errorf("unterminated weight in %q", text)
+			}
+			weight, err := strconv.ParseFloat(text[1:weightEnd+1], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid weight in %q", text)
+			}
+			token.weight = &weight
+			text = text[weightEnd+2:]
+		}
+		token.text = giftUnescape(text)
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+func giftBoolean(body string) *bool {
+	switch strings.ToUpper(strings.TrimSpace(giftStripFeedback(body))) {
+	case "T", "TRUE":
+		return boolPtr(true)
+	case "F", "FALSE":
+		return boolPtr(false)
+	}
+	return nil
+}
+
+// giftStripFeedback отбрасывает отзыв после неэкранированного '#'
+func giftStripFeedback(text string) string {
+	if i := giftIndex(text, "#"); i >= 0 {
+		return text[:i]
+	}
+	return text
+}
+
+// giftIndex индекс первого неэкранированного вхождения sub
+func giftIndex(text, sub string) int {
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.HasPrefix(text[i:], sub) {
+			return i
+		}
+	}
+	return -1
+}
+
+func giftEscape(text string) string {
+	var builder strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\n':
+			builder.WriteString(`\n`)
+		case strings.ContainsRune(giftSpecial, r):
+			builder.WriteByte('\\')
+			builder.WriteRune(r)
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
+
+func giftUnescape(text string) string {
+	var builder strings.Builder
+	escaped := false
+	for _, r := range text {
+		switch {
+		case escaped && r == 'n':
+			builder.WriteByte('\n')
+		case escaped:
+			builder.WriteRune(r)
+		case r == '\\':
+			escaped = true
+			continue
+		default:
+			builder.WriteRune(r)
+		}
+		escaped = false
+	}
+	return strings.TrimSpace(builder.String())
+}
@@ -0,0 +1,85 @@
+package contestio
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/dwnGnL/pg-contests/internal/repository"
+)
+
+func exportJSON(w io.Writer, contest *repository.Contest) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(cleanContest(*contest))
+}
+
+// importJSON файл в формате тела POST /contest; непустые поля base имеют приоритет над файлом
+func importJSON(r io.Reader, base repository.Contest) (*Result, error) {
+	var contest repository.Contest
+	if err := json.NewDecoder(r).Decode(&contest); err != nil {
+		//конкурс из base, чтобы к ошибке разбора не добавлялись ошибки пустого конкурса
+		return &Result{
+			Contest: cleanContest(base),
+			Errors:  []RowError{{Row: 0, Message: fmt.Sprintf("invalid json: %s", err)}},
+		}, nil
+	}
+	if base.Title != "" {
+		contest.Title = base.Title
+	}
+	if base.Price != 0 {
+		contest.Price = base.Price
+	}
+	if !base.StartTime.IsZero() {
+		contest.StartTime = base.StartTime
+	}
+	if base.Timezone != "" {
+		contest.Timezone = base.Timezone
+	}
+
+	result := &Result{Contest: cleanContest(contest)}
+	for i := range result.Contest.Questions {
+		result.Rows = append(result.Rows, i+1)
+	}
+	return result, nil
+}
+
+// cleanContest копия без id, владельцев и состояния: импорт всегда создает новые записи
+func cleanContest(contest repository.Contest) repository.Contest {
+	contest.ID = 0
+	contest.Status = ""
+	contest.CreatedBy = ""
+	contest.ScheduledAt, contest.StartedAt, contest.FinishedAt = nil, nil, nil
+	contest.CancelledAt, contest.ArchivedAt, contest.CreatedAt = nil, nil, nil
+	contest.Bank = nil
+	contest.TemplateID = nil
+	contest.ScheduleID = nil
+	contest.Photos = cleanPhotos(contest.Photos)
+
+	questions := make([]repository.Question, len(contest.Questions))
+	for i, question := range contest.Questions {
+		answers := sortedAnswers(question)
+		for j := range answers {
+			answers[j].ID = 0
+			answers[j].QuestionID = 0
+			answers[j].ChooseTime = 0
+			answers[j].Photos = cleanPhotos(answers[j].Photos)
+		}
+		question.ID = 0
+		question.ContestID = 0
+		question.UserAnswer = nil
+		question.Points = nil
+		question.Answers = answers
+		question.Photos = cleanPhotos(question.Photos)
+		questions[i] = question
+	}
+	contest.Questions = questions
+	return contest
+}
+
+func cleanPhotos(photos []repository.Photo) []repository.Photo {
+	if len(photos) == 0 {
+		return nil
+	}
+	return linkPhotos(photoLinks(photos))
+}
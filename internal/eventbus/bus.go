@@ -0,0 +1,87 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+
+	"github.com/dwnGnL/pg-contests/internal/api/models"
+	"github.com/dwnGnL/pg-contests/lib/goerrors"
+)
+
+// Bus рассылает события конкурса всем инстансам и выбирает один инстанс, который ведет таймлайн конкурса
+type Bus interface {
+	Publish(ctx context.Context, contestID int64, resp models.WsResponse) error
+	Subscribe(contestID int64) (events <-chan models.WsResponse, unsubscribe func())
+	// Lead пытается захватить лидерство по конкурсу, release нужно вызвать когда таймлайн закончен
+	Lead(ctx context.Context, contestID int64) (release func(), ok bool, err error)
+	Close() error
+}
+
+const subscriberBuffer = 16
+
+// fanout локальные подписчики инстанса, общий для всех реализаций
+type fanout struct {
+	sync.RWMutex
+	subscribers map[int64]map[chan models.WsResponse]struct{}
+}
+
+func newFanout() *fanout {
+	return &fanout{subscribers: make(map[int64]map[chan models.WsResponse]struct{})}
+}
+
+func (f *fanout) subscribe(contestID int64) (<-chan models.WsResponse, func()) {
+	ch := make(chan models.WsResponse, subscriberBuffer)
+	f.Lock()
+	if f.subscribers[contestID] == nil {
+		f.subscribers[contestID] = make(map[chan models.WsResponse]struct{})
+	}
+	f.subscribers[contestID][ch] = struct{}{}
+	f.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			f.Lock()
+			defer f.Unlock()
+			//канал уже закрыт в closeAll
+			if _, ok := f.subscribers[contestID][ch]; !ok {
+				return
+			}
+			delete(f.subscribers[contestID], ch)
+			if len(f.subscribers[contestID]) == 0 {
+				delete(f.subscribers, contestID)
+			}
+			close(ch)
+		})
+	}
+}
+
+func (f *fanout) has(contestID int64) bool {
+	f.RLock()
+	defer f.RUnlock()
+	return len(f.subscribers[contestID]) != 0
+}
+
+func (f *fanout) dispatch(contestID int64, resp models.WsResponse) {
+	f.RLock()
+	defer f.RUnlock()
+	for ch := range f.subscribers[contestID] {
+		select {
+		case ch <- resp:
+		default:
+			//медленный подписчик не должен тормозить остальных, он получит актуальное состояние следующим событием
+			goerrors.Log().Warnf("eventbus: subscriber of contest %d is full, event dropped", contestID)
+		}
+	}
+}
+
+func (f *fanout) closeAll() {
+	f.Lock()
+	defer f.Unlock()
+	for contestID, chans := range f.subscribers {
+		for ch := range chans {
+			close(ch)
+		}
+		delete(f.subscribers, contestID)
+	}
+}
@@ -0,0 +1,54 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+
+	"github.com/dwnGnL/pg-contests/internal/api/models"
+)
+
+// Memory шина внутри одного процесса, для тестов и локального запуска
+type Memory struct {
+	*fanout
+	mu      sync.Mutex
+	leaders map[int64]struct{}
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		fanout:  newFanout(),
+		leaders: make(map[int64]struct{}),
+	}
+}
+
+func (m *Memory) Publish(_ context.Context, contestID int64, resp models.WsResponse) error {
+	m.dispatch(contestID, resp)
+	return nil
+}
+
+func (m *Memory) Subscribe(contestID int64) (<-chan models.WsResponse, func()) {
+	return m.subscribe(contestID)
+}
+
+func (m *Memory) Lead(_ context.Context, contestID int64) (func(), bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.leaders[contestID]; ok {
+		return nil, false, nil
+	}
+	m.leaders[contestID] = struct{}{}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			m.mu.Lock()
+			delete(m.leaders, contestID)
+			m.mu.Unlock()
+		})
+	}, true, nil
+}
+
+func (m *Memory) Close() error {
+	m.closeAll()
+	return nil
+}
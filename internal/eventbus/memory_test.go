@@ -0,0 +1,116 @@
+package eventbus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dwnGnL/pg-contests/internal/api/models"
+)
+
+func TestMemoryPublishSubscribe(t *testing.T) {
+	bus := NewMemory()
+	defer bus.Close()
+	first, unsubscribeFirst := bus.Subscribe(1)
+	second, unsubscribeSecond := bus.Subscribe(1)
+	defer unsubscribeSecond()
+	other, unsubscribeOther := bus.Subscribe(2)
+	defer unsubscribeOther()
+
+	if err := bus.Publish(context.Background(), 1, models.WsResponse{Step: 3}); err != nil {
+		t.Fatal(err)
+	}
+	for _, ch := range []<-chan models.WsResponse{first, second} {
+		select {
+		case resp := <-ch:
+			if resp.Step != 3 {
+				t.Fatalf("step = %d, want 3", resp.Step)
+			}
+		default:
+			t.Fatal("subscriber did not get the event")
+		}
+	}
+	select {
+	case resp := <-other:
+		t.Fatalf("subscriber of another contest got %+v", resp)
+	default:
+	}
+
+	unsubscribeFirst()
+	unsubscribeFirst()
+	if _, ok := <-first; ok {
+		t.Fatal("channel must be closed after unsubscribe")
+	}
+	if err := bus.Publish(context.Background(), 1, models.WsResponse{Step: 4}); err != nil {
+		t.Fatal(err)
+	}
+	if resp := <-second; resp.Step != 4 {
+		t.Fatalf("step = %d, want 4", resp.Step)
+	}
+}
+
+func TestMemorySlowSubscriberDoesNotBlock(t *testing.T) {
+	bus := NewMemory()
+	defer bus.Close()
+	ch, unsubscribe := bus.Subscribe(1)
+	defer unsubscribe()
+	for i := 0; i < subscriberBuffer*2; i++ {
+		if err := bus.Publish(context.Background(), 1, models.WsResponse{Step: i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(ch) != subscriberBuffer {
+		t.Fatalf("buffered %d events, want %d", len(ch), subscriberBuffer)
+	}
+	//лишние события отброшены, первые дошли по порядку
+	if resp := <-ch; resp.Step != 0 {
+		t.Fatalf("first event step = %d, want 0", resp.Step)
+	}
+}
+
+func TestMemoryLead(t *testing.T) {
+	bus := NewMemory()
+	defer bus.Close()
+	ctx := context.Background()
+
+	release, ok, err := bus.Lead(ctx, 1)
+	if err != nil || !ok {
+		t.Fatalf("Lead = %v, %v, want leadership", ok, err)
+	}
+	if _, ok, _ = bus.Lead(ctx, 1); ok {
+		t.Fatal("second leader of the same contest")
+	}
+	otherRelease, ok, _ := bus.Lead(ctx, 2)
+	if !ok {
+		t.Fatal("leadership of another contest must be free")
+	}
+	defer otherRelease()
+
+	release()
+	release()
+	again, ok, _ := bus.Lead(ctx, 1)
+	if !ok {
+		t.Fatal("leadership must be free after release")
+	}
+	//повторный release старого лидера не снимает нового
+	release()
+	if _, ok, _ = bus.Lead(ctx, 1); ok {
+		t.Fatal("stale release freed the new leader")
+	}
+	again()
+}
+
+func TestMemoryClose(t *testing.T) {
+	bus := NewMemory()
+	ch, unsubscribe := bus.Subscribe(1)
+	if err := bus.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := <-ch; ok {
+		t.Fatal("channel must be closed by Close")
+	}
+	//отписка после Close не должна закрывать канал второй раз
+	unsubscribe()
+	if err := bus.Publish(context.Background(), 1, models.WsResponse{}); err != nil {
+		t.Fatal(err)
+	}
+}
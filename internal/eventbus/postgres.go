@@ -0,0 +1,181 @@
+package eventbus
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dwnGnL/pg-contests/internal/api/models"
+	"github.com/dwnGnL/pg-contests/lib/goerrors"
+	"github.com/lib/pq"
+)
+
+const (
+	notifyChannel = "contest_events"
+	// leaderLockNamespace старшие биты ключа advisory-lock лидера, в младших leaderLockIDBits - id конкурса
+	leaderLockNamespace = 20221
+	leaderLockIDBits    = 48
+	eventsTTL           = time.Hour
+	pruneInterval       = 10 * time.Minute
+)
+
+// Postgres шина на LISTEN/NOTIFY. Само событие лежит в contest_events, в NOTIFY уходит только "contestID:eventID",
+// лидер конкурса определяется session advisory-lock на выделенном соединении
+type Postgres struct {
+	*fanout
+	db       *sql.DB
+	listener *pq.Listener
+
+	pruneMu    sync.Mutex
+	lastPruned time.Time
+}
+
+func NewPostgres(dsn string) (*Postgres, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err = db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			goerrors.Log().WithError(err).Warnf("eventbus: listener event %d", ev)
+		}
+	})
+	if err = listener.Listen(notifyChannel); err != nil {
+		listener.Close()
+		db.Close()
+		return nil, err
+	}
+
+	p := &Postgres{
+		fanout:   newFanout(),
+		db:       db,
+		listener: listener,
+	}
+	go p.listen()
+	return p, nil
+}
+
+func (p *Postgres) Publish(ctx context.Context, contestID int64, resp models.WsResponse) error {
+	payload, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	var eventID int64
+	err = p.db.QueryRowContext(ctx,
+		"INSERT INTO contest_events (contest_id, payload) VALUES ($1, $2) RETURNING id", contestID, payload).
+		Scan(&eventID)
+	if err != nil {
+		return fmt.Errorf("insert contest event err: %w", err)
+	}
+	_, err = p.db.ExecContext(ctx, "SELECT pg_notify($1, $2)", notifyChannel, fmt.Sprintf("%d:%d", contestID, eventID))
+	if err != nil {
+		return fmt.Errorf("notify contest event err: %w", err)
+	}
+	p.prune(ctx)
+	return nil
+}
+
+func (p *Postgres) Subscribe(contestID int64) (<-chan models.WsResponse, func()) {
+	return p.subscribe(contestID)
+}
+
+func (p *Postgres) Lead(ctx context.Context, contestID int64) (func(), bool, error) {
+	key, err := leaderLockKey(contestID)
+	if err != nil {
+		return nil, false, err
+	}
+	conn, err := p.db.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	var ok bool
+	err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&ok)
+	if err != nil || !ok {
+		conn.Close()
+		return nil, false, err
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			_, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", key)
+			if err != nil {
+				goerrors.Log().WithError(err).Warnf("eventbus: unlock contest %d", contestID)
+			}
+			conn.Close()
+		})
+	}, true, nil
+}
+
+// leaderLockKey один bigint-ключ на конкурс: int4-ключ обрезал бы id, и конкурсы с id больше 2^31 делили бы лидера
+func leaderLockKey(contestID int64) (int64, error) {
+	if contestID < 0 || contestID >= 1<<leaderLockIDBits {
+		return 0, fmt.Errorf("contest id %d does not fit the leader lock key", contestID)
+	}
+	return leaderLockNamespace<<leaderLockIDBits | contestID, nil
+}
+
+func (p *Postgres) Close() error {
+	err := p.listener.Close()
+	p.closeAll()
+	if dbErr := p.db.Close(); err == nil {
+		err = dbErr
+	}
+	return err
+}
+
+func (p *Postgres) listen() {
+	for n := range p.listener.Notify {
+		//nil приходит после переподключения, пропущенные события догонятся следующим тиком таймлайна
+		if n == nil {
+			continue
+		}
+		contestStr, eventStr, found := strings.Cut(n.Extra, ":")
+		if !found {
+			continue
+		}
+		contestID, err := strconv.ParseInt(contestStr, 10, 64)
+		if err != nil || !p.has(contestID) {
+			continue
+		}
+		eventID, err := strconv.ParseInt(eventStr, 10, 64)
+		if err != nil {
+			continue
+		}
+		var payload []byte
+		err = p.db.QueryRow("SELECT payload FROM contest_events WHERE id = $1", eventID).Scan(&payload)
+		if err != nil {
+			goerrors.Log().WithError(err).Warnf("eventbus: load event %d", eventID)
+			continue
+		}
+		var resp models.WsResponse
+		if err = json.Unmarshal(payload, &resp); err != nil {
+			goerrors.Log().WithError(err).Warnf("eventbus: decode event %d", eventID)
+			continue
+		}
+		p.dispatch(contestID, resp)
+	}
+}
+
+func (p *Postgres) prune(ctx context.Context) {
+	p.pruneMu.Lock()
+	defer p.pruneMu.Unlock()
+	if time.Since(p.lastPruned) < pruneInterval {
+		return
+	}
+	p.lastPruned = time.Now()
+	_, err := p.db.ExecContext(ctx, "DELETE FROM contest_events WHERE created_at < $1", time.Now().Add(-eventsTTL))
+	if err != nil {
+		goerrors.Log().WithError(err).Warn("eventbus: prune contest events")
+	}
+}
@@ -0,0 +1,26 @@
+package eventbus
+
+import "testing"
+
+func TestLeaderLockKey(t *testing.T) {
+	keys := make(map[int64]int64)
+	//с int4-ключом все эти id давали бы один и тот же замок
+	for _, contestID := range []int64{0, 1, 1<<31 + 1, 1<<32 + 1, 1<<47 + 1, 1<<leaderLockIDBits - 1} {
+		key, err := leaderLockKey(contestID)
+		if err != nil {
+			t.Fatalf("leaderLockKey(%d): %v", contestID, err)
+		}
+		if other, ok := keys[key]; ok {
+			t.Fatalf("contests %d and %d share lock key %d", other, contestID, key)
+		}
+		if key <= 0 || key>>leaderLockIDBits != leaderLockNamespace {
+			t.Fatalf("leaderLockKey(%d) = %d is outside the namespace", contestID, key)
+		}
+		keys[key] = contestID
+	}
+	for _, contestID := range []int64{-1, 1 << leaderLockIDBits} {
+		if _, err := leaderLockKey(contestID); err == nil {
+			t.Fatalf("leaderLockKey(%d) must fail", contestID)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/dwnGnL/pg-contests/lib/goerrors"
+)
+
+// пути по умолчанию: withdraw - из прежнего клиента, остальные нужно сверить с API баланса и при расхождении задать в конфиге
+const (
+	withdrawPath = "/client/quiz/withdraw-balance"
+	creditPath   = "/client/quiz/credit-balance"
+	refundPath   = "/client/quiz/refund-balance"
+	statusPath   = "/client/quiz/payment-status"
+
+	defaultTimeout    = 15 * time.Second
+	defaultRetries    = 3
+	defaultRetryDelay = time.Second
+)
+
+type BalanceConfig struct {
+	URL          string
+	ServiceToken string // Authorization для операций, которые сервис делает сам: выплаты, возвраты, сверка оплат
+	WithdrawPath string
+	CreditPath   string
+	RefundPath   string
+	StatusPath   string
+	Timeout      time.Duration
+	Retries      int
+	RetryDelay   time.Duration // пауза перед первым повтором, дальше удваивается
+}
+
+// Balance API баланса основного приложения
+type Balance struct {
+	conf   BalanceConfig
+	client *http.Client
+}
+
+func NewBalance(conf BalanceConfig) *Balance {
+	conf.WithdrawPath = pathOrDefault(conf.WithdrawPath, withdrawPath)
+	conf.CreditPath = pathOrDefault(conf.CreditPath, creditPath)
+	conf.RefundPath = pathOrDefault(conf.RefundPath, refundPath)
+	conf.StatusPath = pathOrDefault(conf.StatusPath, statusPath)
+	if conf.Timeout <= 0 {
+		conf.Timeout = defaultTimeout
+	}
+	if conf.Retries <= 0 {
+		conf.Retries = defaultRetries
+	}
+	if conf.RetryDelay <= 0 {
+		conf.RetryDelay = defaultRetryDelay
+	}
+	return &Balance{conf: conf, client: &http.Client{Timeout: conf.Timeout}}
+}
+
+func pathOrDefault(path, def string) string {
+	if path == "" {
+		return def
+	}
+	return path
+}
+
+type amountRequest struct {
+	UserID int64   `json:"user_id,omitempty"`
+	Amount float64 `json:"amount"`
+}
+
+// Withdraw игрока API определяет по токену
+func (b *Balance) Withdraw(ctx context.Context, _ int64, authorization string, amount float64, key string) error {
+	if err := b.send(ctx, b.conf.WithdrawPath, authorization, key, amountRequest{Amount: amount}, nil); err != nil {
+		return fmt.Errorf("withdraw balance err: %w", err)
+	}
+	return nil
+}
+
+func (b *Balance) Refund(ctx context.Context, userID int64, amount float64, key string) error {
+	if err := b.send(ctx, b.conf.RefundPath, b.conf.ServiceToken, key, amountRequest{userID, amount}, nil); err != nil {
+		return fmt.Errorf("refund balance err: %w", err)
+	}
+	return nil
+}
+
+func (b *Balance) Credit(ctx context.Context, userID int64, amount float64, key string) error {
+	if err := b.send(ctx, b.conf.CreditPath, b.conf.ServiceToken, key, amountRequest{userID, amount}, nil); err != nil {
+		return fmt.Errorf("credit balance err: %w", err)
+	}
+	return nil
+}
+
+func (b *Balance) Status(ctx context.Context, key string) (Status, error) {
+	req := struct {
+		IdempotencyKey string `json:"idempotency_key"`
+	}{key}
+	var res struct {
+		Status Status `json:"status"`
+	}
+	if err := b.send(ctx, b.conf.StatusPath, b.conf.ServiceToken, key, req, &res); err != nil {
+		return "", fmt.Errorf("payment status err: %w", err)
+	}
+	return res.Status, nil
+}
+
+// send запрос с повторами; ключ идемпотентности один на все попытки,
+// поэтому повтор после потерянного ответа не проводит операцию дважды
+func (b *Balance) send(ctx context.Context, path, authorization, key string, payload, respStruct interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	delay := b.conf.RetryDelay
+	for attempt := 1; ; attempt++ {
+		err = b.post(ctx, path, authorization, key, body, respStruct)
+		if err == nil || !errors.Is(err, ErrUnavailable) || attempt == b.conf.Retries {
+			return err
+		}
+		goerrors.Log().WithError(err).Warnf("%s %s: attempt %d failed", path, key, attempt)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// post ответ не 200 превращается в типизированную ошибку, тело ответа - ее текст
+func (b *Balance) post(ctx context.Context, path, authorization, key string, body []byte, respStruct interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.conf.URL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Idempotency-Key", key)
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(resp.Body)
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		if respStruct == nil {
+			return nil
+		}
+		return json.Unmarshal(respBody, respStruct)
+	case resp.StatusCode == http.StatusPaymentRequired:
+		return fmt.Errorf("%w: %s", ErrInsufficientFunds, respBody)
+	case resp.StatusCode == http.StatusUnauthorized, resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("%w: %d %s", ErrUnauthorized, resp.StatusCode, respBody)
+	//таймаут и ограничение частоты - провайдер запрос не обработал, его можно повторить
+	case resp.StatusCode >= http.StatusInternalServerError, resp.StatusCode == http.StatusRequestTimeout,
+		resp.StatusCode == http.StatusTooManyRequests:
+		return fmt.Errorf("%w: %d %s", ErrUnavailable, resp.StatusCode, respBody)
+	default:
+		return fmt.Errorf("%w: %d %s", ErrDeclined, resp.StatusCode, respBody)
+	}
+}
@@ -0,0 +1,194 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBalanceErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		wantErr   error
+		wantCalls int
+	}{
+		{"ok", http.StatusOK, nil, 1},
+		{"insufficient funds", http.StatusPaymentRequired, ErrInsufficientFunds, 1},
+		{"declined", http.StatusBadRequest, ErrDeclined, 1},
+		{"bad token", http.StatusUnauthorized, ErrUnauthorized, 1},
+		{"forbidden", http.StatusForbidden, ErrUnauthorized, 1},
+		{"server error is retried", http.StatusBadGateway, ErrUnavailable, 3},
+		{"timeout is retried", http.StatusRequestTimeout, ErrUnavailable, 3},
+		{"throttling is retried", http.StatusTooManyRequests, ErrUnavailable, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if r.URL.Path != withdrawPath || r.Header.Get("Authorization") != "user-token" || r.Header.Get("Idempotency-Key") != "key" {
+					t.Errorf("unexpected request %s %v", r.URL.Path, r.Header)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			b := NewBalance(BalanceConfig{URL: srv.URL, RetryDelay: time.Millisecond})
+			err := b.Withdraw(context.Background(), 1, "user-token", 10, "key")
+			if tt.wantErr == nil && err != nil || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestBalanceRetryKeepsKey(t *testing.T) {
+	var keys []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if len(keys) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var req amountRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.UserID != 5 || req.Amount != 12.5 {
+			t.Errorf("body %+v, %v", req, err)
+		}
+		if r.Header.Get("Authorization") != "service" {
+			t.Errorf("credit must use the service token")
+		}
+	}))
+	defer srv.Close()
+
+	b := NewBalance(BalanceConfig{URL: srv.URL, ServiceToken: "service", RetryDelay: time.Millisecond})
+	if err := b.Credit(context.Background(), 5, 12.5, "contest-1-payout-5"); err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || keys[0] != keys[1] {
+		t.Errorf("keys = %v, want the same key on retry", keys)
+	}
+}
+
+func TestBalanceStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != statusPath {
+			t.Errorf("path %s", r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"status":"not_found"}`))
+	}))
+	defer srv.Close()
+
+	status, err := NewBalance(BalanceConfig{URL: srv.URL}).Status(context.Background(), "k")
+	if err != nil || status != StatusNotFound {
+		t.Errorf("status = %q, %v", status, err)
+	}
+}
+
+func TestBalanceConfiguredPaths(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.URL.Path == "/v2/status" {
+			w.Write([]byte(`{"status": "paid"}`))
+		}
+	}))
+	defer srv.Close()
+
+	b := NewBalance(BalanceConfig{URL: srv.URL, ServiceToken: "service", CreditPath: "/v2/credit", StatusPath: "/v2/status"})
+	ctx := context.Background()
+	if err := b.Withdraw(ctx, 1, "user-token", 10, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Credit(ctx, 1, 10, "b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Refund(ctx, 1, 10, "c"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Status(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{withdrawPath, "/v2/credit", refundPath, "/v2/status"}
+	if fmt.Sprint(paths) != fmt.Sprint(want) {
+		t.Fatalf("paths = %v, want %v", paths, want)
+	}
+}
+
+func TestBalanceTimeoutIsRetried(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			time.Sleep(100 * time.Millisecond)
+		}
+	}))
+	defer srv.Close()
+
+	b := NewBalance(BalanceConfig{URL: srv.URL, ServiceToken: "service", Timeout: 20 * time.Millisecond, RetryDelay: time.Millisecond})
+	if err := b.Refund(context.Background(), 1, 10, "key"); err != nil {
+		t.Fatalf("refund after a timed out attempt: %v", err)
+	}
+	if calls := atomic.LoadInt32(&calls); calls != 2 {
+		t.Fatalf("calls = %d, want 2", calls)
+	}
+}
+
+func TestBalanceCancelledContextStopsRetries(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := NewBalance(BalanceConfig{URL: srv.URL, ServiceToken: "service", Retries: 5, RetryDelay: time.Hour})
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	if err := b.Credit(ctx, 1, 10, "key"); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("err = %v, want ErrUnavailable", err)
+	}
+	if calls := atomic.LoadInt32(&calls); calls != 1 {
+		t.Fatalf("calls = %d, want 1", calls)
+	}
+}
+
+// TestBalanceLostResponseChargesOnce API провел списание, но ответ потерялся: повтор с тем же ключом не списывает второй раз
+func TestBalanceLostResponseChargesOnce(t *testing.T) {
+	charged := make(map[string]float64)
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		var req amountRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		key := r.Header.Get("Idempotency-Key")
+		if _, ok := charged[key]; !ok {
+			charged[key] = req.Amount
+		}
+		if calls == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+
+	b := NewBalance(BalanceConfig{URL: srv.URL, RetryDelay: time.Millisecond})
+	if err := b.Withdraw(context.Background(), 1, "user-token", 10, "contest-1-user-1"); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 || len(charged) != 1 || charged["contest-1-user-1"] != 10 {
+		t.Fatalf("calls = %d, charged = %v, want one charge of 10", calls, charged)
+	}
+}
@@ -0,0 +1,90 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Fake провайдер в памяти процесса, для тестов и локального запуска.
+// Игрок, которому баланс не задан через SetBalance, начинает с initialBalance
+type Fake struct {
+	mu             sync.Mutex
+	initialBalance float64
+	balances       map[int64]float64
+	operations     map[string]Status
+}
+
+func NewFake(initialBalance float64) *Fake {
+	return &Fake{
+		initialBalance: initialBalance,
+		balances:       make(map[int64]float64),
+		operations:     make(map[string]Status),
+	}
+}
+
+func (f *Fake) SetBalance(userID int64, amount float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.balances[userID] = amount
+}
+
+func (f *Fake) Balance(userID int64) float64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.balance(userID)
+}
+
+func (f *Fake) balance(userID int64) float64 {
+	if amount, ok := f.balances[userID]; ok {
+		return amount
+	}
+	return f.initialBalance
+}
+
+func (f *Fake) Withdraw(_ context.Context, userID int64, _ string, amount float64, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch f.operations[key] {
+	case StatusPaid:
+		return nil
+	case StatusFailed:
+		return fmt.Errorf("withdraw balance err: %w", ErrInsufficientFunds)
+	}
+	if f.balance(userID) < amount {
+		f.operations[key] = StatusFailed
+		return fmt.Errorf("withdraw balance err: %w", ErrInsufficientFunds)
+	}
+	f.balances[userID] = f.balance(userID) - amount
+	f.operations[key] = StatusPaid
+	return nil
+}
+
+func (f *Fake) Refund(_ context.Context, userID int64, amount float64, key string) error {
+	f.add(userID, amount, key)
+	return nil
+}
+
+func (f *Fake) Credit(_ context.Context, userID int64, amount float64, key string) error {
+	f.add(userID, amount, key)
+	return nil
+}
+
+func (f *Fake) add(userID int64, amount float64, key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, done := f.operations[key]; done {
+		return
+	}
+	f.balances[userID] = f.balance(userID) + amount
+	f.operations[key] = StatusPaid
+}
+
+func (f *Fake) Status(_ context.Context, key string) (Status, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if status, ok := f.operations[key]; ok {
+		return status, nil
+	}
+	return StatusNotFound, nil
+}
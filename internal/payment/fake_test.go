@@ -0,0 +1,64 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestFakeWithdrawIdempotent(t *testing.T) {
+	ctx := context.Background()
+	f := NewFake(100)
+	for i := 0; i < 3; i++ {
+		if err := f.Withdraw(ctx, 1, "token", 30, "contest-1-subscribe-1"); err != nil {
+			t.Fatalf("withdraw %d: %v", i, err)
+		}
+	}
+	if balance := f.Balance(1); balance != 70 {
+		t.Errorf("balance = %v, want 70: repeated key must not charge again", balance)
+	}
+	if err := f.Withdraw(ctx, 1, "token", 30, "contest-2-subscribe-1"); err != nil {
+		t.Fatal(err)
+	}
+	if balance := f.Balance(1); balance != 40 {
+		t.Errorf("balance = %v, want 40", balance)
+	}
+}
+
+func TestFakeInsufficientFunds(t *testing.T) {
+	ctx := context.Background()
+	f := NewFake(0)
+	f.SetBalance(1, 5)
+	err := f.Withdraw(ctx, 1, "token", 10, "k")
+	if !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("err = %v, want ErrInsufficientFunds", err)
+	}
+	if f.Balance(1) != 5 {
+		t.Errorf("balance changed after a failed withdraw: %v", f.Balance(1))
+	}
+	//повтор с тем же ключом повторяет результат, даже если деньги появились
+	f.SetBalance(1, 50)
+	if err = f.Withdraw(ctx, 1, "token", 10, "k"); !errors.Is(err, ErrInsufficientFunds) {
+		t.Errorf("repeat err = %v, want ErrInsufficientFunds", err)
+	}
+	if status, _ := f.Status(ctx, "k"); status != StatusFailed {
+		t.Errorf("status = %q, want failed", status)
+	}
+}
+
+func TestFakeCreditRefundStatus(t *testing.T) {
+	ctx := context.Background()
+	f := NewFake(0)
+	_ = f.Credit(ctx, 2, 15, "payout")
+	_ = f.Credit(ctx, 2, 15, "payout")
+	_ = f.Refund(ctx, 2, 5, "refund")
+	if balance := f.Balance(2); balance != 20 {
+		t.Errorf("balance = %v, want 20", balance)
+	}
+	tests := map[string]Status{"payout": StatusPaid, "refund": StatusPaid, "unknown": StatusNotFound}
+	for key, want := range tests {
+		if status, err := f.Status(ctx, key); err != nil || status != want {
+			t.Errorf("Status(%q) = %q, %v; want %q", key, status, err, want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+// Package payment операции с балансом игроков: списание оплаты участия, возвраты, выплаты призов
+package payment
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrUnavailable       = errors.New("payment provider unavailable")
+	ErrDeclined          = errors.New("payment declined")
+	// ErrUnauthorized провайдер не принял наши учетные данные: это ошибка настройки сервиса, а не игрока
+	ErrUnauthorized = errors.New("payment provider rejected credentials")
+)
+
+// Status чем закончилась операция с ключом идемпотентности
+type Status string
+
+const (
+	StatusPaid     Status = "paid"
+	StatusFailed   Status = "failed"
+	StatusNotFound Status = "not_found"
+)
+
+// Provider все операции идемпотентны по key: повтор с тем же ключом деньги второй раз не двигает.
+// ErrUnavailable - результат неизвестен, операцию можно повторить с тем же ключом или узнать через Status;
+// остальные ошибки - провайдер операцию отклонил
+type Provider interface {
+	// Withdraw списание от имени игрока userID, authorization - его токен
+	Withdraw(ctx context.Context, userID int64, authorization string, amount float64, key string) error
+	Refund(ctx context.Context, userID int64, amount float64, key string) error
+	Credit(ctx context.Context, userID int64, amount float64, key string) error
+	Status(ctx context.Context, key string) (Status, error)
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// Attempt прохождение конкурса в своем темпе: таймлайн вопросов участника идет от StartedAt
+type Attempt struct {
+	ContestID int64     `json:"contest_id" gorm:"column:contest_id;primaryKey"`
+	UserID    int64     `json:"user_id" gorm:"column:user_id;primaryKey"`
+	StartedAt time.Time `json:"started_at" gorm:"column:started_at"`
+}
+
+func (Attempt) TableName() string {
+	return "contest_attempts"
+}
+
+// StartAttempt попытка у участника одна: если она уже начата, возвращается существующая
+func (r RepoImpl) StartAttempt(contestID, userID int64, at time.Time) (*Attempt, error) {
+	attempt := Attempt{ContestID: contestID, UserID: userID, StartedAt: at.Truncate(time.Second).UTC()}
+	err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&attempt).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.GetAttempt(contestID, userID)
+}
+
+// GetAttempt nil, если участник еще не начинал попытку
+func (r RepoImpl) GetAttempt(contestID, userID int64) (*Attempt, error) {
+	var attempts []Attempt
+	err := r.db.Where("contest_id = ? AND user_id = ?", contestID, userID).Limit(1).Find(&attempts).Error
+	if err != nil || len(attempts) == 0 {
+		return nil, err
+	}
+	return &attempts[0], nil
+}
+
+func (r RepoImpl) GetContestAttempts(contestID int64) (attempts []Attempt, err error) {
+	err = r.db.Where("contest_id = ?", contestID).Find(&attempts).Error
+	return
+}
@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+const (
+	MinDifficulty = 1
+	MaxDifficulty = 5
+)
+
+// BankQuestion вопрос банка, в конкурс попадает его снимок (см. Snapshot)
+type BankQuestion struct {
+	ID            int64          `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	Title         string         `json:"title" binding:"required" gorm:"column:title"`
+	Type          QuestionType   `json:"type" gorm:"column:type;default:single"`
+	PartialCredit bool           `json:"partial_credit" gorm:"column:partial_credit"`
+	Tolerance     float64        `json:"tolerance" gorm:"column:tolerance"`
+	Score         int            `json:"score" binding:"required" gorm:"column:score"`
+	Time          int64          `json:"time" binding:"required" gorm:"column:time"`
+	Difficulty    int            `json:"difficulty" binding:"required" gorm:"column:difficulty"` // 1..5
+	Tags          pq.StringArray `json:"tags" gorm:"column:tags;type:text[]"`
+	Answers       []BankAnswer   `json:"answers" gorm:"foreignKey:BankQuestionID;constraint:OnDelete:CASCADE"`
+	CreatedBy     string         `json:"created_by" gorm:"column:created_by"`
+	CreatedAt     *time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     *time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type BankAnswer struct {
+	ID             int64  `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	BankQuestionID int64  `json:"bank_question_id" gorm:"column:bank_question_id"`
+	Title          string `json:"title" binding:"required" gorm:"column:title"`
+	IsCorrect      *bool  `json:"is_correct" gorm:"column:is_correct;default:false"`
+	Position       int    `json:"position,omitempty" gorm:"column:position"`
+}
+
+// BankFilter поиск по банку, пустые поля не фильтруют
+type BankFilter struct {
+	Tags          []string // вопрос должен иметь все теги
+	Type          QuestionType
+	MinDifficulty int
+	MaxDifficulty int
+	Query         string // подстрока в тексте вопроса
+}
+
+// BankTag тег банка и сколько вопросов им помечено
+type BankTag struct {
+	Tag   string `json:"tag" gorm:"column:tag"`
+	Count int64  `json:"count" gorm:"column:count"`
+}
+
+// BankDraw вопросы банка для нового конкурса: конкретные IDs или Count случайных с тегами Tags и сложностью Difficulty
+type BankDraw struct {
+	IDs        []int64  `json:"ids,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	Difficulty int      `json:"difficulty,omitempty"`
+	Count      int      `json:"count,omitempty"`
+}
+
+var ErrNotEnoughBankQuestions = errors.New("not enough questions in the bank")
+
+// Snapshot копия вопроса банка для конкурса: дальнейшие правки банка на конкурс не влияют
+func (b *BankQuestion) Snapshot(order int) Question {
+	bankQuestionID := b.ID
+	question := Question{
+		BankQuestionID: &bankQuestionID,
+		Title:          b.Title,
+		Type:           b.Type,
+		PartialCredit:  b.PartialCredit,
+		Tolerance:      b.Tolerance,
+		Score:          b.Score,
+		Order:          order,
+		Time:           b.Time,
+	}
+	for _, answer := range b.Answers {
+		question.Answers = append(question.Answers, Answer{
+			Title:     answer.Title,
+			IsCorrect: answer.IsCorrect,
+			Position:  answer.Position,
+		})
+	}
+	return question
+}
+
+// Validate проверяет вопрос по правилам его типа и нормализует теги
+func (b *BankQuestion) Validate() error {
+	if b.Difficulty < MinDifficulty || b.Difficulty > MaxDifficulty {
+		return fmt.Errorf("сложность должна быть от %d до %d", MinDifficulty, MaxDifficulty)
+	}
+	if b.Score <= 0 || b.Time <= 0 {
+		return errors.New("балл и время вопроса должны быть положительными")
+	}
+	b.Tags = NormalizeTags(b.Tags)
+	question := b.Snapshot(1)
+	return question.Validate()
+}
+
+// NormalizeTags теги без регистра и пробелов по краям, без повторов
+func NormalizeTags(tags []string) pq.StringArray {
+	seen := make(map[string]bool, len(tags))
+	normalized := pq.StringArray{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+func (b *BankQuestion) BeforeCreate(tx *gorm.DB) (err error) {
+	return b.Validate()
+}
+
+func (r RepoImpl) CreateBankQuestion(question *BankQuestion) error {
+	return r.db.Create(question).Error
+}
+
+func (r RepoImpl) GetBankQuestion(questionID int64) (question *BankQuestion, err error) {
+	err = r.db.Preload("Answers").First(&question, questionID).Error
+	return
+}
+
+func (r RepoImpl) GetBankQuestions(questionIDs []int64) (questions []BankQuestion, err error) {
+	err = r.db.Preload("Answers").Where("id IN ?", questionIDs).Find(&questions).Error
+	return
+}
+
+// UpdateBankQuestion ответы банка никто не выбирал, поэтому заменяются целиком
+func (r RepoImpl) UpdateBankQuestion(question BankQuestion) (*BankQuestion, error) {
+	if err := question.Validate(); err != nil {
+		return nil, err
+	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&BankQuestion{ID: question.ID}).
+			Select("title", "type", "partial_credit", "tolerance", "score", "time", "difficulty", "tags", "updated_at").
+			Updates(&question)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := tx.Where("bank_question_id = ?", question.ID).Delete(&BankAnswer{}).Error; err != nil {
+			return err
+		}
+		for i := range question.Answers {
+			question.Answers[i].ID = 0
+			question.Answers[i].BankQuestionID = question.ID
+		}
+		if len(question.Answers) == 0 {
+			return nil
+		}
+		return tx.Create(&question.Answers).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetBankQuestion(question.ID)
+}
+
+func (r RepoImpl) DeleteBankQuestion(questionID int64) error {
+	result := r.db.Delete(&BankQuestion{ID: questionID})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r RepoImpl) SearchBankQuestions(filter BankFilter, pagination *Pagination) (*Pagination, error) {
+	query := r.db.Model(&BankQuestion{}).Scopes(bankFilter(filter))
+	var totalRows int64
+	if err := query.Count(&totalRows).Error; err != nil {
+		return nil, err
+	}
+
+	questions := new([]BankQuestion)
+	err := r.db.Scopes(bankFilter(filter), Paginate(pagination)).Preload("Answers").Find(questions).Error
+	if err != nil {
+		return nil, err
+	}
+	pagination.Records = questions
+	pagination.TotalRows = totalRows
+	pagination.TotalPages = int(totalRows / int64(pagination.Limit))
+	if totalRows%int64(pagination.Limit) > 0 {
+		pagination.TotalPages++
+	}
+	return pagination, nil
+}
+
+// DrawBankQuestions count случайных вопросов, excludeIDs - уже выбранные для этого конкурса
+func (r RepoImpl) DrawBankQuestions(filter BankFilter, count int, excludeIDs []int64) (questions []BankQuestion, err error) {
+	query := r.db.Scopes(bankFilter(filter)).Preload("Answers")
+	if len(excludeIDs) > 0 {
+		query = query.Where("id NOT IN ?", excludeIDs)
+	}
+	err = query.Order("random()").Limit(count).Find(&questions).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(questions) < count {
+		return nil, fmt.Errorf("%w: want %d, found %d", ErrNotEnoughBankQuestions, count, len(questions))
+	}
+	return questions, nil
+}
+
+func (r RepoImpl) GetBankTags() (tags []BankTag, err error) {
+	err = r.db.Raw("SELECT tag, count(*) AS count FROM bank_questions, unnest(tags) AS tag GROUP BY tag ORDER BY count DESC, tag").
+		Scan(&tags).Error
+	return
+}
+
+func bankFilter(filter BankFilter) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if tags := NormalizeTags(filter.Tags); len(tags) > 0 {
+			db = db.Where("tags @> ?::text[]", tags)
+		}
+		if filter.Type != "" {
+			db = db.Where("type = ?", filter.Type)
+		}
+		if filter.MinDifficulty > 0 {
+			db = db.Where("difficulty >= ?", filter.MinDifficulty)
+		}
+		if filter.MaxDifficulty > 0 {
+			db = db.Where("difficulty <= ?", filter.MaxDifficulty)
+		}
+		if filter.Query != "" {
+			db = db.Where("title ILIKE ?", "%"+filter.Query+"%")
+		}
+		return db
+	}
+}
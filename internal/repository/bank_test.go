@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBankFilterDrawQuery(t *testing.T) {
+	var questions []BankQuestion
+	stmt := dryRunDB(t).Scopes(bankFilter(BankFilter{Tags: []string{" История ", "история", "Россия"}, MinDifficulty: 2, MaxDifficulty: 2})).
+		Where("id NOT IN ?", []int64{4, 5}).Order("random()").Limit(3).Find(&questions).Statement
+	sql := stmt.SQL.String()
+	for _, want := range []string{"id NOT IN ($1,$2)", "tags @> $3::text[]", "difficulty >= $4", "difficulty <= $5", "ORDER BY random() LIMIT 3"} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("query %q has no %q", sql, want)
+		}
+	}
+	//теги нормализуются, вопрос должен иметь их все
+	if !reflect.DeepEqual(stmt.Vars[2], NormalizeTags([]string{"история", "россия"})) {
+		t.Errorf("tags var = %#v, want normalized tags", stmt.Vars[2])
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	got := NormalizeTags([]string{" Кино ", "", "кино", "Музыка "})
+	if !reflect.DeepEqual([]string(got), []string{"кино", "музыка"}) {
+		t.Fatalf("NormalizeTags = %q", got)
+	}
+}
@@ -0,0 +1,71 @@
+package repository
+
+// Pending фото по подписанной ссылке, которое клиент еще не загрузил
+func (p *Photo) Pending() bool {
+	return p.StorageKey != "" && (p.Uploaded == nil || !*p.Uploaded)
+}
+
+// Clone глубокая копия конкурса для нового черновика: без id, участников и состояния жизненного цикла.
+// Фото копируются ссылками без StorageKey - файлы в хранилище принадлежат исходным фото
+func (c *Contest) Clone() Contest {
+	clone := *c
+	clone.ID = 0
+	clone.PlayersCount = nil
+	clone.CreatedBy = ""
+	clone.Bank = nil
+	clone.TemplateID = nil
+	clone.ScheduleID = nil
+	clone.Status = ""
+	clone.ScheduledAt, clone.StartedAt, clone.FinishedAt = nil, nil, nil
+	clone.CancelledAt, clone.ArchivedAt, clone.CreatedAt = nil, nil, nil
+	clone.Photos = clonePhotos(c.Photos)
+
+	clone.Questions = make([]Question, len(c.Questions))
+	for i, question := range c.Questions {
+		question.ID = 0
+		question.ContestID = 0
+		question.UserAnswer = nil
+		question.Points = nil
+		question.Voided = false
+		question.Photos = clonePhotos(question.Photos)
+		if question.BankQuestionID != nil {
+			bankQuestionID := *question.BankQuestionID
+			question.BankQuestionID = &bankQuestionID
+		}
+		answers := make([]Answer, len(question.Answers))
+		for j, answer := range question.Answers {
+			answer.ID = 0
+			answer.QuestionID = 0
+			answer.ChooseTime = 0
+			answer.Photos = clonePhotos(answer.Photos)
+			if answer.IsCorrect != nil {
+				isCorrect := *answer.IsCorrect
+				answer.IsCorrect = &isCorrect
+			}
+			answers[j] = answer
+		}
+		question.Answers = answers
+		clone.Questions[i] = question
+	}
+	return clone
+}
+
+// clonePhotos незагруженные фото пропускаются, у них еще нет файла
+func clonePhotos(photos []Photo) []Photo {
+	var clones []Photo
+	for _, photo := range photos {
+		if photo.Pending() {
+			continue
+		}
+		photo.ID = 0
+		photo.OwnerID = 0
+		photo.OwnerType = ""
+		photo.StorageKey = ""
+		if photo.Uploaded != nil {
+			uploaded := *photo.Uploaded
+			photo.Uploaded = &uploaded
+		}
+		clones = append(clones, photo)
+	}
+	return clones
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"testing"
+	"time"
+)
+
+func TestContestClone(t *testing.T) {
+	correct, uploaded, bankID := true, true, int64(9)
+	now := time.Now()
+	players := int64(40)
+	source := Contest{
+		ID: 3, Title: "Квиз", Status: StatusFinished, CreatedBy: "admin", PlayersCount: &players,
+		StartedAt: &now, FinishedAt: &now,
+		Photos: []Photo{
+			{ID: 1, OwnerID: 3, OwnerType: "contests", Link: "/a.jpg", StorageKey: "contests/3/a.jpg", Uploaded: &uploaded},
+			{ID: 2, OwnerID: 3, OwnerType: "contests", StorageKey: "contests/3/b.jpg"},
+		},
+		Questions: []Question{{
+			ID: 5, ContestID: 3, Title: "Вопрос", Voided: true, BankQuestionID: &bankID,
+			Answers: []Answer{{ID: 7, QuestionID: 5, Title: "Да", IsCorrect: &correct, ChooseTime: 3}},
+		}},
+	}
+	clone := source.Clone()
+
+	if clone.ID != 0 || clone.Status != "" || clone.CreatedBy != "" || clone.PlayersCount != nil ||
+		clone.StartedAt != nil || clone.FinishedAt != nil || clone.Title != "Квиз" {
+		t.Fatalf("clone keeps the source state: %+v", clone)
+	}
+	//незагруженное фото не копируется, у загруженного нет своего файла
+	if len(clone.Photos) != 1 || clone.Photos[0].ID != 0 || clone.Photos[0].StorageKey != "" || clone.Photos[0].Link != "/a.jpg" {
+		t.Fatalf("clone photos = %+v", clone.Photos)
+	}
+	question := clone.Questions[0]
+	if question.ID != 0 || question.ContestID != 0 || question.Voided || *question.BankQuestionID != 9 {
+		t.Fatalf("clone question = %+v", question)
+	}
+	answer := question.Answers[0]
+	if answer.ID != 0 || answer.QuestionID != 0 || answer.ChooseTime != 0 || !answer.Correct() {
+		t.Fatalf("clone answer = %+v", answer)
+	}
+
+	//правка копии не задевает исходный конкурс
+	*clone.Questions[0].Answers[0].IsCorrect = false
+	*clone.Questions[0].BankQuestionID = 1
+	*clone.Photos[0].Uploaded = false
+	clone.Questions[0].Answers[0].Title = "Нет"
+	if !source.Questions[0].Answers[0].Correct() || *source.Questions[0].BankQuestionID != 9 ||
+		!*source.Photos[0].Uploaded || source.Questions[0].Answers[0].Title != "Да" {
+		t.Fatal("clone shares data with the source")
+	}
+}
+
+func TestTemplateContest(t *testing.T) {
+	template := ContestTemplate{
+		ID: 4, TitlePattern: "Квиз №{n} от {date}", Price: 50, Timezone: "Asia/Dushanbe", Issued: 11,
+		Slots: TemplateSlots{{Tags: []string{"кино"}, Count: 5}, {IDs: []int64{1, 2}}},
+	}
+	//22:00 UTC в Душанбе уже следующий день
+	start := time.Date(2024, 3, 9, 22, 0, 0, 0, time.UTC)
+	contest := template.Contest(start)
+	if contest.Title != "Квиз №12 от 10.03.2024" {
+		t.Errorf("title = %q", contest.Title)
+	}
+	if contest.Status != StatusDraft || *contest.TemplateID != 4 || contest.Price != 50 || !contest.StartTime.Equal(start) {
+		t.Errorf("contest = %+v", contest)
+	}
+	if len(contest.Bank) != 2 || contest.Bank[0].Count != 5 {
+		t.Fatalf("bank = %+v", contest.Bank)
+	}
+	contest.Bank[0].Count = 1
+	if template.Slots[0].Count != 5 {
+		t.Fatal("contest shares slots with the template")
+	}
+}
@@ -3,12 +3,16 @@ package repository
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 
 	"gorm.io/gorm/clause"
 )
 
+// listedStatuses конкурсы в этих статусах видны участникам и доступны для покупки
+var listedStatuses = []ContestStatus{StatusScheduled, StatusLive}
+
 func (r RepoImpl) CreateContest(contest Contest) (*Contest, error) {
 	err := r.db.Create(&contest).Error
 	if err != nil {
@@ -34,7 +38,7 @@ func (r RepoImpl) GetAllContest(pagination *Pagination) (*Pagination, error) {
 	}
 
 	contest := new([]Contest)
-	err = r.db.Scopes(Paginate(pagination)).Preload("Questions.Answers").Preload("Photos").Find(&contest).Error
+	err = r.db.Scopes(Paginate(pagination), preloadContestTree).Find(&contest).Error
 	if err != nil {
 		return nil, err
 	}
@@ -50,7 +54,7 @@ func (r RepoImpl) GetAllContest(pagination *Pagination) (*Pagination, error) {
 func (r RepoImpl) GetAllContestByUserID(userID int64, pagination *Pagination) (*Pagination, error) {
 
 	var totalRows int64
-	err := r.db.Model(Contest{}).Where("NOT is_end and active").Count(&totalRows).Error
+	err := r.db.Model(Contest{}).Where("status IN ?", listedStatuses).Count(&totalRows).Error
 	if err != nil {
 		return nil, err
 	}
@@ -62,7 +66,12 @@ func (r RepoImpl) GetAllContestByUserID(userID int64, pagination *Pagination) (*
 			"c.title AS title,"+
 			"c.price AS price,"+
 			"c.start_time AS start_time,"+
-			"c.is_end AS is_end,"+
+			"c.timezone AS timezone,"+
+			"c.status AS status,"+
+			"c.mode AS mode,"+
+			"c.attempt_window AS attempt_window,"+
+			"c.teams AS teams,"+
+			"c.team_size AS team_size,"+
 			//находим количество уникальных вопросов для каждого конкурса
 			"COUNT(DISTINCT q.id) AS questions_count,"+
 			//суммируем времена ответов на каждый из вопросов конкурса и дели на количесво фоток конкурса для устранения повторного суммирования, при делении обрабатываем случаё деления на 0
@@ -70,17 +79,22 @@ func (r RepoImpl) GetAllContestByUserID(userID int64, pagination *Pagination) (*
 			//тут мы находим все линки ан фотки каждого конкурса, для случая когда фоток у конкурса нет то возвращаем {} инча Scan не сработает
 			"CASE WHEN COUNT(DISTINCT p.id) = 0 THEN '{}' else ARRAY_AGG(DISTINCT p.link) END AS photos_links,"+
 			"uc.created_at AS purchase_date,"+
-			"uc.price AS purchase_price").
+			"uc.price AS purchase_price,"+
+			"uc.payment_status AS payment_status").
 		Joins("LEFT OUTER JOIN questions q ON q.contest_id = c.id").
-		Joins("LEFT OUTER JOIN photos p ON p.owner_id = c.id AND p.owner_type = ?", "contests").
+		Joins("LEFT OUTER JOIN photos p ON p.owner_id = c.id AND p.owner_type = ?", PhotoOwnerContests).
 		Joins("LEFT OUTER JOIN user_contests uc ON  uc.contest_id = c.id AND uc.user_id = ?", userID).
-		Where("NOT c.is_end and c.active").
-		Group("c.title, c.id, uc.created_at, c.price, c.start_time, c.is_end, uc.price").
+		Where("c.status IN ?", listedStatuses).
+		Group("c.title, c.id, uc.created_at, c.price, c.start_time, c.timezone, c.status, c.mode, c.attempt_window, c.teams, c.team_size, uc.price, uc.payment_status").
 		Order("uc.created_at ASC").Scopes(Paginate(pagination)).
 		Scan(&userContestResp).Error
 	if err != nil {
 		return nil, err
 	}
+	for i := range *userContestResp {
+		resp := &(*userContestResp)[i]
+		resp.StartTime = resp.StartTime.In(displayLocation(resp.Timezone))
+	}
 	pagination.Records = userContestResp
 	pagination.TotalRows = totalRows
 	pagination.TotalPages = int(pagination.TotalRows / int64(pagination.Limit))
@@ -90,99 +104,112 @@ func (r RepoImpl) GetAllContestByUserID(userID int64, pagination *Pagination) (*
 	return pagination, nil
 }
 
-func (r RepoImpl) GetContestFullStatsForUser(contestID, userID int64, currentQuestionOrder int) (contest *Contest, err error) {
+// GetContestFullStatsForUser конкурс с ответами участника, в командном конкурсе - с ответами всей его команды;
+// какие вопросы ему уже можно показать, решает сервис
+func (r RepoImpl) GetContestFullStatsForUser(contestID, userID int64, teamID *int64) (contest *Contest, err error) {
 	var (
-		query            = r.db
 		questionPosition int
-		answerPosition   int
-		i, j             int
+		i                int
 		question         Question
-		answer           Answer
 	)
-	if currentQuestionOrder > 0 {
-		query = query.Preload("Questions", "order < ?", currentQuestionOrder)
-	}
-	query = query.Preload("Questions.Answers").Preload("Photos")
-
-	if err = query.Find(&contest, contestID).Error; err != nil {
+	if err = r.db.Scopes(preloadContestTree).Find(&contest, contestID).Error; err != nil {
 		return
 	}
 
 	var userAnswers []UserAnswers
-	err = r.db.Table("user_answers").Where("contest_id = ? AND user_id = ?", contestID, userID).Scan(&userAnswers).Error
+	query := r.db.Table("user_answers").Where("contest_id = ?", contestID)
+	if teamID != nil {
+		query = query.Where("user_id IN (?)", r.db.Model(&UserContests{}).Select("user_id").
+			Where("contest_id = ? AND team_id = ?", contestID, *teamID))
+	} else {
+		query = query.Where("user_id = ?", userID)
+	}
+	err = query.Scan(&userAnswers).Error
 	if err != nil {
 		return
 	}
-	for _, userAnswer := range userAnswers {
+	for k := range userAnswers {
+		userAnswer := &userAnswers[k]
 		questionPosition = -1
-		answerPosition = -1
 		for i, question = range contest.Questions {
 			if question.ID == userAnswer.QuestionID {
 				questionPosition = i
-				for j, answer = range question.Answers {
-					if answer.ID == userAnswer.AnswerID {
-						answerPosition = j
-						break
-					}
-				}
 				break
 			}
 		}
-		if questionPosition < 0 || answerPosition < 0 {
-			err = errors.New(fmt.Sprintf("Check questionID = %v, answerID = %v availability in contestID = %v", userAnswer.QuestionID, userAnswer.AnswerID, contestID))
-			return
+		//вопрос уже удален из конкурса
+		if questionPosition < 0 {
+			continue
+		}
+		question := &contest.Questions[questionPosition]
+		question.UserAnswer = userAnswer
+		for _, answerID := range userAnswer.ChosenIDs() {
+			found := false
+			for j := range question.Answers {
+				if question.Answers[j].ID == answerID {
+					question.Answers[j].ChooseTime = userAnswer.Time
+					found = true
+					break
+				}
+			}
+			if !found {
+				err = errors.New(fmt.Sprintf("Check questionID = %v, answerID = %v availability in contestID = %v", userAnswer.QuestionID, answerID, contestID))
+				return
+			}
 		}
-		contest.Questions[questionPosition].Answers[answerPosition].ChooseTime = userAnswer.Time
 	}
 	return
 }
 
-func (r RepoImpl) prepareContestStarQuery(contestID, currentQuestionID int64) *gorm.DB {
-	/*return r.db.Table("user_contests uc").
-	Select("row_number() over () AS rank,"+
-		"uc.user_id AS user_id,"+
-		"uc.user_name AS user_name,"+
-		"COUNT(CASE is_correct WHEN true THEN 1 END ) AS total_correct,"+
-		"SUM(CASE is_correct WHEN true THEN q.score ELSE 0 END) AS total_score,"+
-		"SUM(CASE is_correct WHEN true THEN ua.time ELSE 0 END) AS total_time").
-	Joins("LEFT OUTER JOIN user_answers ua ON uc.user_id = ua.user_id and ua.contest_id = uc.contest_id").
-	Joins("LEFT OUTER JOIN answers a ON ua.answer_id = a.id AND ua.question_id = a.question_id AND ua.question_id <> ?", currentQuestionID).
-	Joins("LEFT OUTER JOIN questions q ON q.id = ua.question_id").
-	Where("uc.contest_id = ?", contestID).
-	Group("uc.user_id, uc.user_name").
-	Order("total_score DESC, total_time ASC")*/
-	query := r.db.Table("user_contests uc").
-		Select("uc.user_id AS user_id,"+
-			"uc.user_name AS user_name,"+
-			"COUNT(CASE is_correct WHEN true THEN 1 END ) AS total_correct,"+
-			"SUM(CASE is_correct WHEN true THEN q.score ELSE 0 END) AS total_score,"+
-			"SUM(CASE is_correct WHEN true THEN ua.time ELSE 0 END) AS total_time").
-		Joins("LEFT OUTER JOIN user_answers ua ON uc.user_id = ua.user_id and ua.contest_id = uc.contest_id").
-		Joins("LEFT OUTER JOIN answers a ON ua.answer_id = a.id AND ua.question_id = a.question_id AND ua.question_id <> ?", currentQuestionID).
-		Joins("LEFT OUTER JOIN questions q ON q.id = ua.question_id").
-		Where("uc.contest_id = ?", contestID).
-		Group("uc.user_id, uc.user_name").
-		Order("total_score DESC, total_time ASC")
-	return r.db.Table("(?) as a", query).Select("row_number() over () AS rank, a.*")
+// GetContestParticipants все купившие конкурс, независимо от факта участия; неоплаченные подписки не в счет
+func (r RepoImpl) GetContestParticipants(contestID int64) (participants []UserContests, err error) {
+	err = r.db.Where("contest_id = ? AND payment_status = ?", contestID, PaymentPaid).Find(&participants).Error
+	return
+}
+
+func (r RepoImpl) GetContestUserAnswers(contestID int64) (userAnswers []UserAnswers, err error) {
+	err = r.db.Where("contest_id = ?", contestID).Find(&userAnswers).Error
+	return
 }
 
-func (r RepoImpl) GetContestStatsForUser(contestID, userID, currentQuestionID int64) (contestStatsResp *ContestStats, err error) {
-	err = r.db.Table("(?) as b", r.prepareContestStarQuery(contestID, currentQuestionID)).Where("b.user_id = ?", userID).Scan(&contestStatsResp).Error
+func (r RepoImpl) GetUserAnswers(contestID, userID int64) (userAnswers []UserAnswers, err error) {
+	err = r.db.Where("contest_id = ? AND user_id = ?", contestID, userID).Find(&userAnswers).Error
 	return
 }
 
-func (r RepoImpl) GetContestStatsById(contestID, currentQuestionID int64, pagination *Pagination) (*Pagination, error) {
+// SaveContestResults фиксирует итоговую таблицу, повторный вызов перезаписывает ее целиком
+func (r RepoImpl) SaveContestResults(contestID int64, results []ContestStats) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("contest_id = ?", contestID).Delete(&ContestResult{}).Error
+		if err != nil {
+			return err
+		}
+		if len(results) == 0 {
+			return nil
+		}
+		rows := make([]ContestResult, 0, len(results))
+		for _, result := range results {
+			rows = append(rows, ContestResult{ContestID: contestID, ContestStats: result})
+		}
+		return tx.CreateInBatches(rows, 500).Error
+	})
+}
 
+func (r RepoImpl) CountContestResults(contestID int64) (count int64, err error) {
+	err = r.db.Model(&ContestResult{}).Where("contest_id = ?", contestID).Count(&count).Error
+	return
+}
+
+func (r RepoImpl) GetContestResults(contestID int64, pagination *Pagination) (*Pagination, error) {
 	var totalRows int64
-	//считаем общее количество пользователей купивших данный конкурс
-	err := r.db.Model(UserContests{}).Where("contest_id = ?", contestID).Count(&totalRows).Error
+	err := r.db.Model(&ContestResult{}).Where("contest_id = ?", contestID).Count(&totalRows).Error
 	if err != nil {
 		return nil, err
 	}
 
 	contestStatsResp := new([]ContestStats)
-	//запрос для отображения результатов ВСЕХ пользователей купивших данный конкурс, независимо от факта участия
-	err = r.prepareContestStarQuery(contestID, currentQuestionID).Scopes(Paginate(pagination)).Scan(&contestStatsResp).Error
+	err = r.db.Model(&ContestResult{}).Where("contest_id = ?", contestID).
+		Scopes(Paginate(pagination)).Order("rank").Scan(contestStatsResp).Error
 	if err != nil {
 		return nil, err
 	}
@@ -196,8 +223,15 @@ func (r RepoImpl) GetContestStatsById(contestID, currentQuestionID int64, pagina
 	return pagination, nil
 }
 
+// GetContestResultForUser строка участника, в командном конкурсе userID = 0 и строка команды teamID
+func (r RepoImpl) GetContestResultForUser(contestID, userID, teamID int64) (result *ContestStats, err error) {
+	err = r.db.Model(&ContestResult{}).Where("contest_id = ? AND user_id = ? AND team_id = ?", contestID, userID, teamID).
+		Limit(1).Scan(&result).Error
+	return
+}
+
 func (r RepoImpl) GetContest(contestID int64) (contest *Contest, err error) {
-	err = r.db.Preload("Questions.Answers").Preload("Photos").Last(&contest, contestID).Error
+	err = r.db.Scopes(preloadContestTree).Last(&contest, contestID).Error
 	return
 }
 
@@ -206,24 +240,59 @@ func (r RepoImpl) GetContestInfo(contestID int64) (contest *Contest, err error)
 	return
 }
 
-func (r RepoImpl) UpdateContest(contest Contest) (*Contest, error) {
-	err := r.db.Select("Photos").Delete(&contest).Error
-	if err != nil {
-		return nil, err
+// GetContestsToSchedule все запланированные и идущие конкурсы вместе с вопросами
+func (r RepoImpl) GetContestsToSchedule() (contests []Contest, err error) {
+	err = r.db.Preload("Questions").Where("status IN ?", []ContestStatus{StatusScheduled, StatusLive}).Find(&contests).Error
+	return
+}
+
+var ErrStatusChanged = errors.New("contest status was changed concurrently")
+
+// UpdateContestStatus меняет статус, только если конкурс все еще в статусе from, и проставляет время перехода
+func (r RepoImpl) UpdateContestStatus(contestID int64, from, to ContestStatus, at time.Time) error {
+	values := map[string]interface{}{"status": to}
+	if column := to.TimestampColumn(); column != "" {
+		values[column] = at
 	}
-	err = r.db.Create(&contest).Error
-	if err != nil {
-		return nil, err
+	res := r.db.Model(&Contest{}).Where("id = ? AND status = ?", contestID, from).Updates(values)
+	if res.Error != nil {
+		return res.Error
 	}
-	return &contest, nil
+	if res.RowsAffected == 0 {
+		return ErrStatusChanged
+	}
+	return nil
 }
 
 func (r RepoImpl) ChangeContestInfo(contest *Contest) error {
 	return r.db.Updates(&contest).Error
 }
 
+// SubscribeContest в командном конкурсе вместе с подпиской участник вступает в команду или создает ее.
+// Одновременные подписки одного участника упираются в первичный ключ: пройдет только одна
 func (r RepoImpl) SubscribeContest(userContest *UserContests) error {
-	return r.db.Create(&userContest).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if userContest.TeamID != nil || userContest.TeamName != "" {
+			if err := joinTeam(tx, userContest); err != nil {
+				return err
+			}
+		}
+		if userContest.TicketID != nil {
+			if err := redeemTicket(tx, *userContest.TicketID); err != nil {
+				return err
+			}
+		}
+		if userContest.PromoCode != "" {
+			if err := usePromo(tx, userContest.PromoCode, userContest.ContestID); err != nil {
+				return err
+			}
+		}
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&userContest)
+		if res.Error == nil && res.RowsAffected == 0 {
+			return ErrAlreadySubscribed
+		}
+		return res.Error
+	})
 }
 
 func (r RepoImpl) ContestAvailability(contestID int64, userID int64) (contest *Contest, err error) {
@@ -234,30 +303,45 @@ func (r RepoImpl) ContestAvailability(contestID int64, userID int64) (contest *C
 		return
 	}
 	if userContests != 0 {
-		err = errors.New("already subscribed earlier")
+		err = ErrAlreadySubscribed
 		return
 	}
 
-	err = r.db.Table("contests").Where("active AND NOT is_end AND id = ?", contestID).Last(&contest).Error
+	err = r.db.Table("contests").Where("status IN ? AND id = ?", listedStatuses, contestID).Last(&contest).Error
 	return
 }
 
 func (r RepoImpl) GetUserContest(contestID int64, userID int64) (userContest *UserContests, err error) {
-	err = r.db.Where("user_id = ? and contest_id = ?", userID, contestID).Find(&userContest).Error
+	err = r.db.Where("user_id = ? and contest_id = ? and payment_status = ?", userID, contestID, PaymentPaid).Find(&userContest).Error
+	return
+}
+
+func (r RepoImpl) GetQuestion(questionID int64) (question *Question, err error) {
+	err = r.db.Preload("Answers").Last(&question, questionID).Error
+	return
+}
+
+func (r RepoImpl) GetAnswer(answerID int64) (answer *Answer, err error) {
+	err = r.db.Last(&answer, answerID).Error
 	return
 }
 
+// preloadContestTree вопросы, ответы и фото всех уровней конкурса
+func preloadContestTree(db *gorm.DB) *gorm.DB {
+	return db.Preload("Questions.Answers.Photos").Preload("Questions.Photos").Preload("Photos")
+}
+
 func (r RepoImpl) SubmitAnswer(userAnswer *UserAnswers) (err error) {
-	var count int64
+	var previous []UserAnswers
 
-	err = r.db.Model(UserAnswers{}).Where("user_id = ? and contest_id = ? and question_id = ? and answer_id = ?",
-		userAnswer.UserID, userAnswer.ContestID, userAnswer.QuestionID, userAnswer.AnswerID).
-		Count(&count).Error
+	err = r.db.Where("user_id = ? and contest_id = ? and question_id = ?",
+		userAnswer.UserID, userAnswer.ContestID, userAnswer.QuestionID).
+		Limit(1).Find(&previous).Error
 	if err != nil {
 		return
 	}
 	//усли участник уже так ответил ранееб не обновляем ничего
-	if count != 0 {
+	if len(previous) != 0 && previous[0].sameAnswer(userAnswer) {
 		return
 	}
 
@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrDestructiveChange = errors.New("destructive change of a started contest")
+
+var (
+	contestUpdateColumns  = []string{"title", "price", "players_count", "start_time", "timezone", "scoring_decay", "scoring_half_life", "scoring_min_fraction", "scoring_wrong_penalty", "scoring_streak_bonus", "scoring_streak_cap", "shuffle_answers", "shuffle_questions", "mode", "attempt_window", "teams", "captain_answers", "team_size", "prizes"}
+	questionUpdateColumns = []string{"title", "type", "partial_credit", "tolerance", "score", "sort_order", "time"}
+	answerUpdateColumns   = []string{"title", "is_correct", "position"}
+	photoUpdateColumns    = []string{"file_name", "uploaded", "link"}
+)
+
+// UpdateContest применяет к сохраненному конкурсу только отличия, id вопросов и ответов сохраняются.
+// После старта конкурса удаление вопросов/ответов и смена правильных ответов запрещены без force;
+// ответы участников на вопросы с измененной проверкой пересчитываются в той же транзакции, regraded - были ли такие вопросы
+func (r RepoImpl) UpdateContest(contest Contest, force bool) (updated *Contest, regraded bool, err error) {
+	if err = contest.Validate(); err != nil {
+		return nil, false, err
+	}
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		var stored Contest
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Scopes(preloadContestTree).
+			First(&stored, contest.ID).Error
+		if err != nil {
+			return err
+		}
+
+		diff := contestDiff{tx: tx, started: startedContest(&stored), regrade: map[int64]bool{}}
+		if !contest.StartTime.Truncate(time.Second).Equal(stored.StartTime) {
+			diff.destructive("start time changed")
+		}
+		if contest.Mode != stored.Mode {
+			diff.destructive("mode changed")
+		}
+		if contest.Teams != stored.Teams {
+			diff.destructive("teams changed")
+		}
+		if !contest.Prizes.Equal(stored.Prizes) {
+			diff.destructive("prizes changed")
+		}
+		if err = diff.questions(stored.ID, stored.Questions, contest.Questions); err != nil {
+			return err
+		}
+		if err = diff.photos(stored.ID, PhotoOwnerContests, stored.Photos, contest.Photos); err != nil {
+			return err
+		}
+		if len(diff.reasons) > 0 && !force {
+			return fmt.Errorf("%w: %s", ErrDestructiveChange, strings.Join(diff.reasons, "; "))
+		}
+
+		for questionID := range diff.regrade {
+			if err = regradeAnswers(tx, questionID); err != nil {
+				return err
+			}
+		}
+		regraded = len(diff.regrade) > 0
+		return tx.Model(&Contest{ID: stored.ID}).Select(contestUpdateColumns).Updates(&contest).Error
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	updated, err = r.GetContest(contest.ID)
+	return updated, regraded, err
+}
+
+// startedContest у участников уже могут быть ответы: статус после старта или наступившее время начала.
+// У конкурсов, закрытых миграцией 0004, и у тех, что планировщик еще не перевел в live, started_at пустой
+func startedContest(contest *Contest) bool {
+	switch contest.Status {
+	case StatusLive, StatusFinished, StatusArchived, StatusCancelled:
+		return true
+	}
+	return contest.StartedAt != nil || contest.Started()
+}
+
+type contestDiff struct {
+	tx      *gorm.DB
+	started bool
+	reasons []string
+	regrade map[int64]bool // вопросы, проверка которых изменилась
+}
+
+func (d *contestDiff) destructive(format string, args ...interface{}) {
+	if d.started {
+		d.reasons = append(d.reasons, fmt.Sprintf(format, args...))
+	}
+}
+
+func (d *contestDiff) questions(contestID int64, stored, incoming []Question) error {
+	storedByID := make(map[int64]Question, len(stored))
+	for _, question := range stored {
+		storedByID[question.ID] = question
+	}
+	for _, question := range incoming {
+		old, ok := storedByID[question.ID]
+		if !ok {
+			question.ContestID = contestID
+			resetQuestionIDs(&question)
+			if err := d.tx.Create(&question).Error; err != nil {
+				return err
+			}
+			continue
+		}
+		delete(storedByID, question.ID)
+
+		if old.Kind() != question.Kind() || old.Tolerance != question.Tolerance || old.PartialCredit != question.PartialCredit {
+			d.destructive("question %d grading changed", question.ID)
+			d.regrade[question.ID] = true
+		}
+		err := d.tx.Model(&Question{ID: question.ID}).Select(questionUpdateColumns).Updates(&question).Error
+		if err != nil {
+			return err
+		}
+		if err = d.answers(question.ID, old.Answers, question.Answers); err != nil {
+			return err
+		}
+		if err = d.photos(question.ID, PhotoOwnerQuestions, old.Photos, question.Photos); err != nil {
+			return err
+		}
+	}
+	for id := range storedByID {
+		d.destructive("question %d removed", id)
+		if err := d.tx.Where("question_id = ?", id).Delete(&UserAnswers{}).Error; err != nil {
+			return err
+		}
+		if err := d.tx.Delete(&Question{ID: id, ContestID: contestID}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *contestDiff) answers(questionID int64, stored, incoming []Answer) error {
+	storedByID := make(map[int64]Answer, len(stored))
+	for _, answer := range stored {
+		storedByID[answer.ID] = answer
+	}
+	for _, answer := range incoming {
+		old, ok := storedByID[answer.ID]
+		if !ok {
+			answer.QuestionID = questionID
+			resetAnswerIDs(&answer)
+			if answer.Correct() {
+				d.destructive("question %d got a new correct answer", questionID)
+				d.regrade[questionID] = true
+			}
+			if err := d.tx.Create(&answer).Error; err != nil {
+				return err
+			}
+			continue
+		}
+		delete(storedByID, answer.ID)
+
+		if old.Correct() != answer.Correct() || old.Position != answer.Position {
+			d.destructive("answer %d correctness changed", answer.ID)
+			d.regrade[questionID] = true
+		}
+		if answer.IsCorrect == nil {
+			answer.IsCorrect = new(bool)
+		}
+		err := d.tx.Model(&Answer{ID: answer.ID}).Select(answerUpdateColumns).Updates(&answer).Error
+		if err != nil {
+			return err
+		}
+		if err = d.photos(answer.ID, PhotoOwnerAnswers, old.Photos, answer.Photos); err != nil {
+			return err
+		}
+	}
+	for id := range storedByID {
+		d.destructive("answer %d removed", id)
+		d.regrade[questionID] = true
+		if err := d.tx.Delete(&Answer{ID: id, QuestionID: questionID}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *contestDiff) photos(ownerID int64, ownerType string, stored, incoming []Photo) error {
+	storedByID := make(map[int64]Photo, len(stored))
+	for _, photo := range stored {
+		storedByID[photo.ID] = photo
+	}
+	for _, photo := range incoming {
+		old, ok := storedByID[photo.ID]
+		if !ok {
+			photo.ID = 0
+			photo.OwnerID = ownerID
+			photo.OwnerType = ownerType
+			if err := d.tx.Create(&photo).Error; err != nil {
+				return err
+			}
+			continue
+		}
+		delete(storedByID, photo.ID)
+		//у загруженного через хранилище файла ссылки выставляет сервис, а не клиент
+		columns := photoUpdateColumns
+		if old.StorageKey != "" {
+			columns = []string{"file_name"}
+		}
+		err := d.tx.Model(&Photo{ID: photo.ID}).Select(columns).Updates(&photo).Error
+		if err != nil {
+			return err
+		}
+	}
+	for id := range storedByID {
+		if err := d.tx.Delete(&Photo{ID: id}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resetQuestionIDs новый вопрос создается целиком, id из запроса не должны указывать на чужие записи
+func resetQuestionIDs(question *Question) {
+	question.ID = 0
+	for i := range question.Answers {
+		resetAnswerIDs(&question.Answers[i])
+	}
+	for i := range question.Photos {
+		question.Photos[i].ID = 0
+	}
+}
+
+func resetAnswerIDs(answer *Answer) {
+	answer.ID = 0
+	for i := range answer.Photos {
+		answer.Photos[i].ID = 0
+	}
+}
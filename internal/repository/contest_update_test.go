@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// dryRunDB gorm без базы: запросы строятся, но не выполняются
+func dryRunDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(postgres.Open("host=localhost dbname=contests"), &gorm.Config{
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+		Logger:                 logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestStartedContest(t *testing.T) {
+	past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	tests := []struct {
+		name    string
+		contest Contest
+		want    bool
+	}{
+		{name: "draft", contest: Contest{Status: StatusDraft, StartTime: future}},
+		{name: "scheduled", contest: Contest{Status: StatusScheduled, StartTime: future}},
+		{name: "scheduled with passed start time", contest: Contest{Status: StatusScheduled, StartTime: past}, want: true},
+		{name: "live", contest: Contest{Status: StatusLive, StartTime: future}, want: true},
+		{name: "finished without started_at", contest: Contest{Status: StatusFinished, StartTime: future}, want: true},
+		{name: "archived", contest: Contest{Status: StatusArchived, StartTime: future}, want: true},
+		{name: "cancelled", contest: Contest{Status: StatusCancelled, StartTime: future}, want: true},
+		{name: "started_at set", contest: Contest{Status: StatusDraft, StartTime: future, StartedAt: &past}, want: true},
+	}
+	for _, tt := range tests {
+		if got := startedContest(&tt.contest); got != tt.want {
+			t.Errorf("%s: startedContest = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestContestDiffQuestions(t *testing.T) {
+	correct, wrong := true, false
+	stored := func() []Question {
+		return []Question{
+			{ID: 1, Title: "Первый", Score: 1, Time: 10, Answers: []Answer{
+				{ID: 11, QuestionID: 1, Title: "Да", IsCorrect: &correct},
+				{ID: 12, QuestionID: 1, Title: "Нет", IsCorrect: &wrong},
+			}},
+			{ID: 2, Title: "Второй", Score: 1, Time: 10, Answers: []Answer{
+				{ID: 21, QuestionID: 2, Title: "Да", IsCorrect: &correct},
+			}},
+		}
+	}
+	tests := []struct {
+		name        string
+		started     bool
+		edit        func(questions []Question) []Question
+		wantReasons []string
+		wantRegrade []int64
+	}{
+		{
+			name: "titles, score and time",
+			edit: func(questions []Question) []Question {
+				questions[0].Title, questions[0].Score, questions[0].Time = "Новый", 5, 20
+				questions[0].Answers[1].Title = "Конечно нет"
+				return questions
+			},
+			started: true,
+		},
+		{
+			name:    "new question",
+			started: true,
+			edit: func(questions []Question) []Question {
+				return append(questions, Question{Title: "Третий", Score: 1, Time: 10, Answers: []Answer{{Title: "Да", IsCorrect: &correct}}})
+			},
+		},
+		{
+			name:    "new wrong answer",
+			started: true,
+			edit: func(questions []Question) []Question {
+				questions[0].Answers = append(questions[0].Answers, Answer{Title: "Может быть", IsCorrect: &wrong})
+				return questions
+			},
+		},
+		{
+			name:    "question removed",
+			started: true,
+			edit: func(questions []Question) []Question {
+				return questions[:1]
+			},
+			wantReasons: []string{"question 2 removed"},
+		},
+		{
+			name:    "correct answer changed",
+			started: true,
+			edit: func(questions []Question) []Question {
+				questions[0].Answers[0].IsCorrect, questions[0].Answers[1].IsCorrect = &wrong, &correct
+				return questions
+			},
+			wantReasons: []string{"answer 11 correctness changed", "answer 12 correctness changed"},
+			wantRegrade: []int64{1},
+		},
+		{
+			name:    "new correct answer",
+			started: true,
+			edit: func(questions []Question) []Question {
+				questions[1].Answers = append(questions[1].Answers, Answer{Title: "Ага", IsCorrect: &correct})
+				return questions
+			},
+			wantReasons: []string{"question 2 got a new correct answer"},
+			wantRegrade: []int64{2},
+		},
+		{
+			name:    "answer removed",
+			started: true,
+			edit: func(questions []Question) []Question {
+				questions[0].Answers = questions[0].Answers[:1]
+				return questions
+			},
+			wantReasons: []string{"answer 12 removed"},
+			wantRegrade: []int64{1},
+		},
+		{
+			name:    "question type changed",
+			started: true,
+			edit: func(questions []Question) []Question {
+				questions[0].Type, questions[0].PartialCredit = QuestionMulti, true
+				return questions
+			},
+			wantReasons: []string{"question 1 grading changed"},
+			wantRegrade: []int64{1},
+		},
+		{
+			name: "not started contest is regraded without reasons",
+			edit: func(questions []Question) []Question {
+				questions[0].Answers[0].IsCorrect, questions[0].Answers[1].IsCorrect = &wrong, &correct
+				return questions[:1]
+			},
+			wantRegrade: []int64{1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := contestDiff{tx: dryRunDB(t), started: tt.started, regrade: map[int64]bool{}}
+			if err := diff.questions(7, stored(), tt.edit(stored())); err != nil {
+				t.Fatal(err)
+			}
+			sort.Strings(diff.reasons)
+			if !reflect.DeepEqual(diff.reasons, tt.wantReasons) {
+				t.Errorf("reasons = %q, want %q", diff.reasons, tt.wantReasons)
+			}
+			var regrade []int64
+			for id := range diff.regrade {
+				regrade = append(regrade, id)
+			}
+			sort.Slice(regrade, func(i, j int) bool { return regrade[i] < regrade[j] })
+			if !reflect.DeepEqual(regrade, tt.wantRegrade) {
+				t.Errorf("regrade = %v, want %v", regrade, tt.wantRegrade)
+			}
+		})
+	}
+}
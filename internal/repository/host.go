@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// HostAction действие ведущего над идущим вопросом живого конкурса
+type HostAction string
+
+const (
+	HostPause  HostAction = "pause"
+	HostResume HostAction = "resume"
+	HostSkip   HostAction = "skip"   // закрыть вопрос досрочно
+	HostExtend HostAction = "extend" // добавить Seconds ко времени вопроса
+	HostVoid   HostAction = "void"   // закрыть вопрос и не засчитывать его никому
+)
+
+func (a HostAction) Valid() bool {
+	switch a {
+	case HostPause, HostResume, HostSkip, HostExtend, HostVoid:
+		return true
+	}
+	return false
+}
+
+// HostEvent запись журнала ведущего; QuestionID - вопрос, который шел в момент действия
+type HostEvent struct {
+	ID         int64      `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	ContestID  int64      `json:"contest_id" gorm:"column:contest_id"`
+	Action     HostAction `json:"action" binding:"required" gorm:"column:action"`
+	QuestionID int64      `json:"question_id" gorm:"column:question_id"`
+	Seconds    int64      `json:"seconds,omitempty" gorm:"column:seconds"`
+	CreatedBy  string     `json:"created_by,omitempty" gorm:"column:created_by"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"column:created_at"`
+}
+
+func (HostEvent) TableName() string {
+	return "contest_host_events"
+}
+
+// AddHostEvent аннулирование записывается вместе с флагом вопроса, по нему считаются баллы
+func (r RepoImpl) AddHostEvent(event *HostEvent) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(event).Error; err != nil {
+			return err
+		}
+		if event.Action != HostVoid {
+			return nil
+		}
+		return tx.Table("questions").Where("id = ? AND contest_id = ?", event.QuestionID, event.ContestID).
+			Update("voided", true).Error
+	})
+}
+
+// GetHostEvents журнал ведущего в порядке действий
+func (r RepoImpl) GetHostEvents(contestID int64) (events []HostEvent, err error) {
+	err = r.db.Where("contest_id = ?", contestID).Order("created_at, id").Find(&events).Error
+	return
+}
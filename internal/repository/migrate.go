@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dwnGnL/pg-contests/lib/goerrors"
+	"gorm.io/gorm"
+)
+
+// migrationLockID ключ advisory-lock, чтобы два инстанса не накатывали миграции одновременно
+const migrationLockID = 7345120931
+
+//go:embed migrations/*.sql
+var sqlMigrationsFS embed.FS
+
+// goMigrations миграции, которые нельзя выразить чистым SQL (например перенос данных с разбором в Go)
+var goMigrations []Migration
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+type MigrationStatus struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at"`
+}
+
+type schemaMigration struct {
+	Version   int64     `gorm:"column:version;primaryKey"`
+	Name      string    `gorm:"column:name"`
+	AppliedAt time.Time `gorm:"column:applied_at"`
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrate накатывает все еще не примененные миграции, данные не трогает
+func (r RepoImpl) Migrate() error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+	if err = r.ensureMigrationsTable(); err != nil {
+		return err
+	}
+	for _, m := range migrations {
+		applied, err := r.applyMigration(m)
+		if err != nil {
+			return fmt.Errorf("migration %d_%s up err: %w", m.Version, m.Name, err)
+		}
+		if applied {
+			goerrors.Log().Infof("migration %d_%s applied", m.Version, m.Name)
+		}
+	}
+	return nil
+}
+
+// MigrateDown откатывает последние steps примененных миграций
+func (r RepoImpl) MigrateDown(steps int) error {
+	if steps <= 0 {
+		return errors.New("steps must be positive")
+	}
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+	if err = r.ensureMigrationsTable(); err != nil {
+		return err
+	}
+	byVersion := make(map[int64]Migration, len(migrations))
+	for _, m := range migrations {
+		byVersion[m.Version] = m
+	}
+
+	var applied []schemaMigration
+	err = r.db.Order("version DESC").Limit(steps).Find(&applied).Error
+	if err != nil {
+		return err
+	}
+	for _, a := range applied {
+		m, ok := byVersion[a.Version]
+		if !ok {
+			return fmt.Errorf("migration %d_%s is applied but unknown to this build", a.Version, a.Name)
+		}
+		if m.Down == nil {
+			return fmt.Errorf("migration %d_%s is irreversible", m.Version, m.Name)
+		}
+		err = r.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockID).Error; err != nil {
+				return err
+			}
+			if err := m.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&schemaMigration{}, m.Version).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migration %d_%s down err: %w", m.Version, m.Name, err)
+		}
+		goerrors.Log().Infof("migration %d_%s rolled back", m.Version, m.Name)
+	}
+	return nil
+}
+
+// MigrationStatus возвращает все известные миграции с датой применения (nil - еще не применена)
+func (r RepoImpl) MigrationStatus() ([]MigrationStatus, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	if err = r.ensureMigrationsTable(); err != nil {
+		return nil, err
+	}
+	var applied []schemaMigration
+	if err = r.db.Find(&applied).Error; err != nil {
+		return nil, err
+	}
+	appliedAt := make(map[int64]time.Time, len(applied))
+	for _, a := range applied {
+		appliedAt[a.Version] = a.AppliedAt
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		status := MigrationStatus{Version: m.Version, Name: m.Name}
+		if at, ok := appliedAt[m.Version]; ok {
+			status.AppliedAt = &at
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func (r RepoImpl) ensureMigrationsTable() error {
+	return r.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    bigint PRIMARY KEY,
+		name       text NOT NULL,
+		applied_at timestamptz NOT NULL DEFAULT now()
+	)`).Error
+}
+
+func (r RepoImpl) applyMigration(m Migration) (applied bool, err error) {
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockID).Error; err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&schemaMigration{}).Where("version = ?", m.Version).Count(&count).Error; err != nil {
+			return err
+		}
+		//уже применена (возможно другим инстансом пока мы ждали блокировку)
+		if count != 0 {
+			return nil
+		}
+		if err := m.Up(tx); err != nil {
+			return err
+		}
+		applied = true
+		return tx.Create(&schemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+	})
+	return
+}
+
+// loadMigrations собирает SQL миграции из migrations/NNNN_name.(up|down).sql и Go миграции в один отсортированный список
+func loadMigrations() ([]Migration, error) {
+	return readMigrations(sqlMigrationsFS, goMigrations)
+}
+
+func readMigrations(sqlFS fs.FS, inGo []Migration) ([]Migration, error) {
+	byVersion := make(map[int64]*Migration)
+	files, err := fs.Glob(sqlFS, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		name := strings.TrimSuffix(path.Base(file), ".sql")
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("migration file %s must end with .up.sql or .down.sql", file)
+		}
+		name = strings.TrimSuffix(name, "."+direction)
+		versionStr, title, ok := strings.Cut(name, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration file %s must be named NNNN_name", file)
+		}
+		version, err := strconv.ParseInt(versionStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration file %s has bad version: %w", file, err)
+		}
+		body, err := fs.ReadFile(sqlFS, file)
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: title}
+			byVersion[version] = m
+		}
+		if m.Name != title {
+			return nil, fmt.Errorf("migration %d has different names: %s and %s", version, m.Name, title)
+		}
+		switch direction {
+		case "up":
+			m.Up = execSQL(string(body))
+		case "down":
+			m.Down = execSQL(string(body))
+		}
+	}
+	for i := range inGo {
+		if _, ok := byVersion[inGo[i].Version]; ok {
+			return nil, fmt.Errorf("migration %d defined twice", inGo[i].Version)
+		}
+		byVersion[inGo[i].Version] = &inGo[i]
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == nil {
+			return nil, fmt.Errorf("migration %d_%s has no up step", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+func execSQL(query string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		return tx.Exec(query).Error
+	}
+}
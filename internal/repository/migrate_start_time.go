@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// форматы, в которых start_time хранился строкой до перехода на timestamptz
+const (
+	legacyLayout    = "2006-01-02T15:04Z07:00"
+	legacyLayoutOld = "2006-01-02T15:04"
+)
+
+func init() {
+	goMigrations = append(goMigrations, Migration{
+		Version: 5,
+		Name:    "start_time_timestamptz",
+		Up:      migrateStartTimeUp,
+		Down:    migrateStartTimeDown,
+	})
+}
+
+type legacyStartTime struct {
+	ID        int64
+	StartTime string
+}
+
+func migrateStartTimeUp(tx *gorm.DB) error {
+	var rows []legacyStartTime
+	if err := tx.Raw("SELECT id, start_time FROM contests").Scan(&rows).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("ALTER TABLE contests ADD COLUMN start_at timestamptz").Error; err != nil {
+		return err
+	}
+	for _, row := range rows {
+		startTime, err := parseLegacyStartTime(row.StartTime)
+		if err != nil {
+			return fmt.Errorf("contest %d start_time %q: %w", row.ID, row.StartTime, err)
+		}
+		err = tx.Exec("UPDATE contests SET start_at = ? WHERE id = ?", startTime.UTC(), row.ID).Error
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Exec(`ALTER TABLE contests DROP COLUMN start_time;
+		ALTER TABLE contests RENAME COLUMN start_at TO start_time;
+		ALTER TABLE contests ADD COLUMN timezone text NOT NULL DEFAULT ''`).Error
+}
+
+func migrateStartTimeDown(tx *gorm.DB) error {
+	var rows []struct {
+		ID        int64
+		StartTime time.Time
+	}
+	if err := tx.Raw("SELECT id, start_time FROM contests").Scan(&rows).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("ALTER TABLE contests ADD COLUMN start_text text").Error; err != nil {
+		return err
+	}
+	for _, row := range rows {
+		err := tx.Exec("UPDATE contests SET start_text = ? WHERE id = ?", row.StartTime.UTC().Format(legacyLayout), row.ID).Error
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Exec(`ALTER TABLE contests DROP COLUMN timezone;
+		ALTER TABLE contests DROP COLUMN start_time;
+		ALTER TABLE contests RENAME COLUMN start_text TO start_time`).Error
+}
+
+// parseLegacyStartTime строки без зоны раньше разбирались time.Parse, то есть как UTC
+func parseLegacyStartTime(value string) (time.Time, error) {
+	for _, layout := range []string{legacyLayout, legacyLayoutOld, time.RFC3339} {
+		if startTime, err := time.Parse(layout, value); err == nil {
+			return startTime, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unknown start_time format")
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLegacyStartTime(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{value: "2024-03-10T18:30+05:00", want: time.Date(2024, 3, 10, 13, 30, 0, 0, time.UTC)},
+		{value: "2024-03-10T18:30Z", want: time.Date(2024, 3, 10, 18, 30, 0, 0, time.UTC)},
+		//старый формат без зоны всегда читался как UTC
+		{value: "2024-03-10T18:30", want: time.Date(2024, 3, 10, 18, 30, 0, 0, time.UTC)},
+		{value: "2024-03-10T18:30:15-03:00", want: time.Date(2024, 3, 10, 21, 30, 15, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parseLegacyStartTime(tt.value)
+		if err != nil {
+			t.Errorf("parseLegacyStartTime(%q): %v", tt.value, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseLegacyStartTime(%q) = %v, want %v", tt.value, got.UTC(), tt.want)
+		}
+		//откат пишет строку, которую снова разберет накат
+		back, err := parseLegacyStartTime(got.UTC().Format(legacyLayout))
+		if err != nil || !back.Equal(got.Truncate(time.Minute)) {
+			t.Errorf("round trip of %q = %v, %v", tt.value, back, err)
+		}
+	}
+	for _, value := range []string{"", "10.03.2024 18:30", "2024-03-10"} {
+		if _, err := parseLegacyStartTime(value); err == nil {
+			t.Errorf("parseLegacyStartTime(%q) must fail", value)
+		}
+	}
+}
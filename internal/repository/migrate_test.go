@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"gorm.io/gorm"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	//SQL и Go миграции идут одним списком по версиям, без пропусков
+	for i, m := range migrations {
+		if m.Version != int64(i+1) {
+			t.Fatalf("migration #%d has version %d", i, m.Version)
+		}
+		if m.Up == nil || m.Down == nil {
+			t.Errorf("migration %d_%s has no up or down step", m.Version, m.Name)
+		}
+	}
+	if m := migrations[4]; m.Name != "start_time_timestamptz" {
+		t.Fatalf("migration 5 = %s, want the Go start_time migration", m.Name)
+	}
+}
+
+func TestReadMigrations(t *testing.T) {
+	file := func(body string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(body)} }
+	up := func(tx *gorm.DB) error { return nil }
+
+	migrations, err := readMigrations(fstest.MapFS{
+		"migrations/0010_ten.up.sql":   file("SELECT 10"),
+		"migrations/0002_two.up.sql":   file("SELECT 2"),
+		"migrations/0002_two.down.sql": file("SELECT -2"),
+	}, []Migration{{Version: 3, Name: "in_go", Up: up}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, m := range migrations {
+		names = append(names, m.Name)
+	}
+	//версии сравниваются числами, а не строками
+	if strings.Join(names, ",") != "two,in_go,ten" {
+		t.Fatalf("order = %v", names)
+	}
+	if migrations[0].Down == nil || migrations[2].Down != nil {
+		t.Fatal("down steps are mixed up")
+	}
+
+	invalid := []struct {
+		name  string
+		files fstest.MapFS
+		inGo  []Migration
+		want  string
+	}{
+		{name: "no direction", files: fstest.MapFS{"migrations/0001_a.sql": file("")}, want: ".up.sql or .down.sql"},
+		{name: "no name", files: fstest.MapFS{"migrations/0001.up.sql": file("")}, want: "NNNN_name"},
+		{name: "bad version", files: fstest.MapFS{"migrations/v1_a.up.sql": file("")}, want: "bad version"},
+		{name: "different names", files: fstest.MapFS{"migrations/0001_a.up.sql": file(""), "migrations/0001_b.down.sql": file("")}, want: "different names"},
+		{name: "down only", files: fstest.MapFS{"migrations/0001_a.down.sql": file("")}, want: "no up step"},
+		{name: "sql and go", files: fstest.MapFS{"migrations/0001_a.up.sql": file("")}, inGo: []Migration{{Version: 1, Up: up}}, want: "defined twice"},
+	}
+	for _, tt := range invalid {
+		if _, err := readMigrations(tt.files, tt.inGo); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: readMigrations = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS user_answers;
+DROP TABLE IF EXISTS user_contests;
+DROP TABLE IF EXISTS user_tickets;
+DROP TABLE IF EXISTS photos;
+DROP TABLE IF EXISTS answers;
+DROP TABLE IF EXISTS questions;
+DROP TABLE IF EXISTS contests;
@@ -0,0 +1,65 @@
+-- схема, которую раньше создавал AutoMigrate; IF NOT EXISTS позволяет принять уже существующую базу
+CREATE TABLE IF NOT EXISTS contests (
+    id            bigserial PRIMARY KEY,
+    title         text,
+    price         decimal,
+    players_count bigint,
+    start_time    text,
+    created_by    text,
+    active        boolean DEFAULT true,
+    is_end        boolean DEFAULT false,
+    created_at    timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS questions (
+    id         bigserial PRIMARY KEY,
+    contest_id bigint,
+    title      text,
+    score      bigint,
+    sort_order bigint,
+    time       bigint,
+    CONSTRAINT fk_contests_questions FOREIGN KEY (contest_id) REFERENCES contests (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS answers (
+    id          bigserial PRIMARY KEY,
+    question_id bigint,
+    title       text,
+    is_correct  boolean DEFAULT false,
+    choose_time bigint,
+    CONSTRAINT fk_questions_answers FOREIGN KEY (question_id) REFERENCES questions (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS photos (
+    id         bigserial PRIMARY KEY,
+    file_name  text,
+    uploaded   boolean DEFAULT false,
+    link       text,
+    owner_id   bigint,
+    owner_type text
+);
+
+CREATE TABLE IF NOT EXISTS user_tickets (
+    user_id    bigint,
+    contest_id bigint,
+    canseled   boolean DEFAULT false
+);
+
+CREATE TABLE IF NOT EXISTS user_contests (
+    user_id    bigint,
+    contest_id bigint,
+    user_name  text,
+    email      text,
+    price      decimal,
+    created_at timestamptz,
+    PRIMARY KEY (user_id, contest_id)
+);
+
+CREATE TABLE IF NOT EXISTS user_answers (
+    user_id     bigint,
+    contest_id  bigint,
+    question_id bigint,
+    answer_id   bigint,
+    time        bigint,
+    PRIMARY KEY (user_id, contest_id, question_id)
+);
@@ -0,0 +1,9 @@
+-- события таймлайна конкурса для рассылки между инстансами (payload NOTIFY ограничен 8000 байтами)
+CREATE TABLE IF NOT EXISTS contest_events (
+    id         bigserial PRIMARY KEY,
+    contest_id bigint      NOT NULL,
+    payload    jsonb       NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_contest_events_created_at ON contest_events (created_at);
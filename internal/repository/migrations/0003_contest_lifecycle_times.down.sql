@@ -0,0 +1,2 @@
+ALTER TABLE contests DROP COLUMN IF EXISTS finished_at;
+ALTER TABLE contests DROP COLUMN IF EXISTS started_at;
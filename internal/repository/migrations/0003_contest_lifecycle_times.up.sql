@@ -0,0 +1,2 @@
+ALTER TABLE contests ADD COLUMN IF NOT EXISTS started_at timestamptz;
+ALTER TABLE contests ADD COLUMN IF NOT EXISTS finished_at timestamptz;
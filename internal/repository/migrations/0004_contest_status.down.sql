@@ -0,0 +1,12 @@
+ALTER TABLE contests ADD COLUMN active boolean DEFAULT true;
+ALTER TABLE contests ADD COLUMN is_end boolean DEFAULT false;
+
+UPDATE contests SET
+    active = status IN ('scheduled', 'live', 'finished'),
+    is_end = status IN ('finished', 'cancelled', 'archived');
+
+DROP INDEX IF EXISTS idx_contests_status;
+ALTER TABLE contests DROP COLUMN archived_at;
+ALTER TABLE contests DROP COLUMN cancelled_at;
+ALTER TABLE contests DROP COLUMN scheduled_at;
+ALTER TABLE contests DROP COLUMN status;
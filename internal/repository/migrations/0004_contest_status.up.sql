@@ -0,0 +1,17 @@
+ALTER TABLE contests ADD COLUMN IF NOT EXISTS status text NOT NULL DEFAULT 'draft';
+ALTER TABLE contests ADD COLUMN IF NOT EXISTS scheduled_at timestamptz;
+ALTER TABLE contests ADD COLUMN IF NOT EXISTS cancelled_at timestamptz;
+ALTER TABLE contests ADD COLUMN IF NOT EXISTS archived_at timestamptz;
+
+UPDATE contests SET status = CASE
+        WHEN is_end THEN 'finished'
+        WHEN NOT active THEN 'draft'
+        WHEN started_at IS NOT NULL THEN 'live'
+        ELSE 'scheduled'
+    END;
+UPDATE contests SET scheduled_at = created_at WHERE status <> 'draft';
+
+ALTER TABLE contests DROP COLUMN active;
+ALTER TABLE contests DROP COLUMN is_end;
+
+CREATE INDEX IF NOT EXISTS idx_contests_status ON contests (status);
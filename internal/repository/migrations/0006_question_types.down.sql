@@ -0,0 +1,9 @@
+ALTER TABLE user_answers DROP COLUMN IF EXISTS credit;
+ALTER TABLE user_answers DROP COLUMN IF EXISTS value;
+ALTER TABLE user_answers DROP COLUMN IF EXISTS answer_ids;
+
+ALTER TABLE answers DROP COLUMN IF EXISTS position;
+
+ALTER TABLE questions DROP COLUMN IF EXISTS tolerance;
+ALTER TABLE questions DROP COLUMN IF EXISTS partial_credit;
+ALTER TABLE questions DROP COLUMN IF EXISTS type;
@@ -0,0 +1,14 @@
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS type text NOT NULL DEFAULT 'single';
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS partial_credit boolean NOT NULL DEFAULT false;
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS tolerance double precision NOT NULL DEFAULT 0;
+
+ALTER TABLE answers ADD COLUMN IF NOT EXISTS position bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE user_answers ADD COLUMN IF NOT EXISTS answer_ids bigint[];
+ALTER TABLE user_answers ADD COLUMN IF NOT EXISTS value text NOT NULL DEFAULT '';
+ALTER TABLE user_answers ADD COLUMN IF NOT EXISTS credit double precision NOT NULL DEFAULT 0;
+
+-- до этой миграции были только вопросы с одним ответом
+UPDATE user_answers ua SET credit = 1
+FROM answers a
+WHERE a.id = ua.answer_id AND a.question_id = ua.question_id AND a.is_correct;
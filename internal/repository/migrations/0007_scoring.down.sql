@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS contest_results;
+
+ALTER TABLE contests DROP COLUMN IF EXISTS scoring_streak_cap;
+ALTER TABLE contests DROP COLUMN IF EXISTS scoring_streak_bonus;
+ALTER TABLE contests DROP COLUMN IF EXISTS scoring_wrong_penalty;
+ALTER TABLE contests DROP COLUMN IF EXISTS scoring_min_fraction;
+ALTER TABLE contests DROP COLUMN IF EXISTS scoring_half_life;
+ALTER TABLE contests DROP COLUMN IF EXISTS scoring_decay;
@@ -0,0 +1,18 @@
+ALTER TABLE contests ADD COLUMN IF NOT EXISTS scoring_decay text NOT NULL DEFAULT '';
+ALTER TABLE contests ADD COLUMN IF NOT EXISTS scoring_half_life double precision NOT NULL DEFAULT 0;
+ALTER TABLE contests ADD COLUMN IF NOT EXISTS scoring_min_fraction double precision NOT NULL DEFAULT 0;
+ALTER TABLE contests ADD COLUMN IF NOT EXISTS scoring_wrong_penalty double precision NOT NULL DEFAULT 0;
+ALTER TABLE contests ADD COLUMN IF NOT EXISTS scoring_streak_bonus double precision NOT NULL DEFAULT 0;
+ALTER TABLE contests ADD COLUMN IF NOT EXISTS scoring_streak_cap bigint NOT NULL DEFAULT 0;
+
+-- итоговая таблица, фиксируется при завершении конкурса
+CREATE TABLE IF NOT EXISTS contest_results (
+    contest_id    bigint           NOT NULL,
+    user_id       bigint           NOT NULL,
+    user_name     text,
+    rank          bigint           NOT NULL,
+    total_score   double precision NOT NULL,
+    total_time    bigint           NOT NULL,
+    total_correct bigint           NOT NULL,
+    PRIMARY KEY (contest_id, user_id)
+);
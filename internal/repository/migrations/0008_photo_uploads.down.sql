@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_photos_owner;
+
+ALTER TABLE photos DROP COLUMN IF EXISTS size;
+ALTER TABLE photos DROP COLUMN IF EXISTS content_type;
+ALTER TABLE photos DROP COLUMN IF EXISTS thumbnail_link;
+ALTER TABLE photos DROP COLUMN IF EXISTS storage_key;
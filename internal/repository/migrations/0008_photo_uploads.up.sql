@@ -0,0 +1,6 @@
+ALTER TABLE photos ADD COLUMN IF NOT EXISTS storage_key text NOT NULL DEFAULT '';
+ALTER TABLE photos ADD COLUMN IF NOT EXISTS thumbnail_link text NOT NULL DEFAULT '';
+ALTER TABLE photos ADD COLUMN IF NOT EXISTS content_type text NOT NULL DEFAULT '';
+ALTER TABLE photos ADD COLUMN IF NOT EXISTS size bigint NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_photos_owner ON photos (owner_type, owner_id);
@@ -0,0 +1,4 @@
+ALTER TABLE questions DROP COLUMN IF EXISTS bank_question_id;
+
+DROP TABLE IF EXISTS bank_answers;
+DROP TABLE IF EXISTS bank_questions;
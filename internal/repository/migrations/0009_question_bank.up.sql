@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS bank_questions (
+    id             bigserial PRIMARY KEY,
+    title          text             NOT NULL,
+    type           text             NOT NULL DEFAULT 'single',
+    partial_credit boolean          NOT NULL DEFAULT false,
+    tolerance      double precision NOT NULL DEFAULT 0,
+    score          bigint           NOT NULL,
+    time           bigint           NOT NULL,
+    difficulty     bigint           NOT NULL,
+    tags           text[]           NOT NULL DEFAULT '{}',
+    created_by     text,
+    created_at     timestamptz      NOT NULL DEFAULT now(),
+    updated_at     timestamptz      NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_bank_questions_tags ON bank_questions USING gin (tags);
+CREATE INDEX IF NOT EXISTS idx_bank_questions_difficulty ON bank_questions (difficulty);
+
+CREATE TABLE IF NOT EXISTS bank_answers (
+    id               bigserial PRIMARY KEY,
+    bank_question_id bigint  NOT NULL REFERENCES bank_questions (id) ON DELETE CASCADE,
+    title            text    NOT NULL,
+    is_correct       boolean NOT NULL DEFAULT false,
+    position         bigint  NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_bank_answers_question ON bank_answers (bank_question_id);
+
+-- из какого вопроса банка сделан снимок, без внешнего ключа: банк можно чистить, конкурсы остаются как были
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS bank_question_id bigint;
@@ -0,0 +1,2 @@
+ALTER TABLE contests DROP COLUMN IF EXISTS shuffle_questions;
+ALTER TABLE contests DROP COLUMN IF EXISTS shuffle_answers;
@@ -0,0 +1,2 @@
+ALTER TABLE contests ADD COLUMN IF NOT EXISTS shuffle_answers boolean NOT NULL DEFAULT false;
+ALTER TABLE contests ADD COLUMN IF NOT EXISTS shuffle_questions boolean NOT NULL DEFAULT false;
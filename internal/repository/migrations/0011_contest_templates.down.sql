@@ -0,0 +1,2 @@
+ALTER TABLE contests DROP COLUMN IF EXISTS template_id;
+DROP TABLE IF EXISTS contest_templates;
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS contest_templates (
+    id                    bigserial PRIMARY KEY,
+    name                  text             NOT NULL,
+    title_pattern         text             NOT NULL,
+    price                 double precision NOT NULL DEFAULT 0,
+    timezone              text,
+    scoring_decay         text             NOT NULL DEFAULT '',
+    scoring_half_life     double precision NOT NULL DEFAULT 0,
+    scoring_min_fraction  double precision NOT NULL DEFAULT 0,
+    scoring_wrong_penalty double precision NOT NULL DEFAULT 0,
+    scoring_streak_bonus  double precision NOT NULL DEFAULT 0,
+    scoring_streak_cap    bigint           NOT NULL DEFAULT 0,
+    shuffle_answers       boolean          NOT NULL DEFAULT false,
+    slots                 jsonb            NOT NULL DEFAULT '[]',
+    issued                bigint           NOT NULL DEFAULT 0,
+    created_by            text,
+    created_at            timestamptz      NOT NULL DEFAULT now(),
+    updated_at            timestamptz      NOT NULL DEFAULT now()
+);
+
+-- из какого шаблона создан конкурс, без внешнего ключа: шаблон можно удалить, конкурсы остаются
+ALTER TABLE contests ADD COLUMN IF NOT EXISTS template_id bigint;
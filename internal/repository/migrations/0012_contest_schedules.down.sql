@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_contests_schedule_start;
+ALTER TABLE contests DROP COLUMN IF EXISTS schedule_id;
+DROP TABLE IF EXISTS contest_schedules;
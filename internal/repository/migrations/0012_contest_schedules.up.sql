@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS contest_schedules (
+    id              bigserial PRIMARY KEY,
+    name            text             NOT NULL,
+    cron            text             NOT NULL,
+    timezone        text,
+    template_id     bigint REFERENCES contest_templates (id) ON DELETE RESTRICT,
+    title_pattern   text             NOT NULL DEFAULT '',
+    price           double precision NOT NULL DEFAULT 0,
+    bank            jsonb            NOT NULL DEFAULT '[]',
+    publish         boolean          NOT NULL DEFAULT false,
+    paused          boolean          NOT NULL DEFAULT false,
+    issued          bigint           NOT NULL DEFAULT 0,
+    last_start_time timestamptz,
+    last_error      text             NOT NULL DEFAULT '',
+    created_by      text,
+    created_at      timestamptz      NOT NULL DEFAULT now(),
+    updated_at      timestamptz      NOT NULL DEFAULT now()
+);
+
+-- какое расписание создало конкурс; уникальность защищает от двойного создания несколькими экземплярами сервиса
+ALTER TABLE contests ADD COLUMN IF NOT EXISTS schedule_id bigint;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_contests_schedule_start ON contests (schedule_id, start_time) WHERE schedule_id IS NOT NULL;
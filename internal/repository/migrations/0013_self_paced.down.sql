@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS contest_attempts;
+ALTER TABLE contests DROP COLUMN IF EXISTS attempt_window;
+ALTER TABLE contests DROP COLUMN IF EXISTS mode;
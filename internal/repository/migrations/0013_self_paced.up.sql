@@ -0,0 +1,10 @@
+-- live: один таймлайн для всех от start_time; self_paced: попытку можно начать в течение attempt_window секунд после start_time
+ALTER TABLE contests ADD COLUMN IF NOT EXISTS mode text NOT NULL DEFAULT 'live';
+ALTER TABLE contests ADD COLUMN IF NOT EXISTS attempt_window bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS contest_attempts (
+    contest_id bigint      NOT NULL REFERENCES contests (id) ON DELETE CASCADE,
+    user_id    bigint      NOT NULL,
+    started_at timestamptz NOT NULL,
+    PRIMARY KEY (contest_id, user_id)
+);
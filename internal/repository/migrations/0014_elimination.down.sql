@@ -0,0 +1,2 @@
+ALTER TABLE contest_results DROP COLUMN IF EXISTS eliminated;
+UPDATE contests SET mode = 'live' WHERE mode = 'elimination';
@@ -0,0 +1,2 @@
+-- в конкурсе на выбывание (mode = 'elimination') приз делят не выбывшие до конца участники
+ALTER TABLE contest_results ADD COLUMN IF NOT EXISTS eliminated boolean NOT NULL DEFAULT false;
@@ -0,0 +1,12 @@
+DELETE FROM contest_results WHERE team_id <> 0;
+ALTER TABLE contest_results DROP CONSTRAINT IF EXISTS contest_results_pkey;
+ALTER TABLE contest_results ADD PRIMARY KEY (contest_id, user_id);
+ALTER TABLE contest_results DROP COLUMN IF EXISTS team_name;
+ALTER TABLE contest_results DROP COLUMN IF EXISTS team_id;
+
+ALTER TABLE user_contests DROP COLUMN IF EXISTS team_id;
+DROP TABLE IF EXISTS contest_teams;
+
+ALTER TABLE contests DROP COLUMN IF EXISTS team_size;
+ALTER TABLE contests DROP COLUMN IF EXISTS captain_answers;
+ALTER TABLE contests DROP COLUMN IF EXISTS teams;
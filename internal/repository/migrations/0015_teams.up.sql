@@ -0,0 +1,20 @@
+ALTER TABLE contests ADD COLUMN IF NOT EXISTS teams boolean NOT NULL DEFAULT false;
+ALTER TABLE contests ADD COLUMN IF NOT EXISTS captain_answers boolean NOT NULL DEFAULT false;
+ALTER TABLE contests ADD COLUMN IF NOT EXISTS team_size bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS contest_teams (
+    id         bigserial PRIMARY KEY,
+    contest_id bigint      NOT NULL REFERENCES contests (id) ON DELETE CASCADE,
+    name       text        NOT NULL,
+    captain_id bigint      NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_contest_teams_name ON contest_teams (contest_id, lower(name));
+
+ALTER TABLE user_contests ADD COLUMN IF NOT EXISTS team_id bigint;
+
+-- в командном конкурсе строка итоговой таблицы - команда: team_id заполнен, user_id = 0
+ALTER TABLE contest_results ADD COLUMN IF NOT EXISTS team_id bigint NOT NULL DEFAULT 0;
+ALTER TABLE contest_results ADD COLUMN IF NOT EXISTS team_name text;
+ALTER TABLE contest_results DROP CONSTRAINT IF EXISTS contest_results_pkey;
+ALTER TABLE contest_results ADD PRIMARY KEY (contest_id, team_id, user_id);
@@ -0,0 +1,2 @@
+ALTER TABLE questions DROP COLUMN IF EXISTS voided;
+DROP TABLE IF EXISTS contest_host_events;
@@ -0,0 +1,14 @@
+-- журнал действий ведущего живого конкурса: таймлайн пересчитывается из start_time, длительности вопросов и этих событий
+CREATE TABLE IF NOT EXISTS contest_host_events (
+    id          bigserial PRIMARY KEY,
+    contest_id  bigint      NOT NULL REFERENCES contests (id) ON DELETE CASCADE,
+    action      text        NOT NULL,
+    question_id bigint      NOT NULL DEFAULT 0,
+    seconds     bigint      NOT NULL DEFAULT 0,
+    created_by  text        NOT NULL DEFAULT '',
+    created_at  timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS contest_host_events_contest_idx ON contest_host_events (contest_id, created_at, id);
+
+-- аннулированный вопрос не дает баллов никому
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS voided boolean NOT NULL DEFAULT false;
@@ -0,0 +1,12 @@
+-- правки проверки вопросов после начала конкурса: кто, когда, что поменял и сколько ответов пересчитано
+CREATE TABLE IF NOT EXISTS contest_audit (
+    id              bigserial PRIMARY KEY,
+    contest_id      bigint      NOT NULL REFERENCES contests (id) ON DELETE CASCADE,
+    question_id     bigint      NOT NULL,
+    action          text        NOT NULL,
+    details         jsonb       NOT NULL DEFAULT '{}',
+    changed_answers bigint      NOT NULL DEFAULT 0,
+    created_by      text        NOT NULL DEFAULT '',
+    created_at      timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS contest_audit_contest_idx ON contest_audit (contest_id, id);
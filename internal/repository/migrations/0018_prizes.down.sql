@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS contest_payouts;
+ALTER TABLE contests DROP COLUMN IF EXISTS prizes;
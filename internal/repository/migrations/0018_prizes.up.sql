@@ -0,0 +1,19 @@
+-- призы по местам: фиксированные суммы или проценты от собранного с участников фонда
+ALTER TABLE contests ADD COLUMN IF NOT EXISTS prizes jsonb NOT NULL DEFAULT '{}';
+
+CREATE TABLE IF NOT EXISTS contest_payouts (
+    contest_id      bigint           NOT NULL REFERENCES contests (id) ON DELETE CASCADE,
+    user_id         bigint           NOT NULL,
+    team_id         bigint           NOT NULL DEFAULT 0,
+    place           integer          NOT NULL,
+    amount          double precision NOT NULL,
+    status          text             NOT NULL DEFAULT 'pending',
+    idempotency_key text             NOT NULL,
+    attempts        integer          NOT NULL DEFAULT 0,
+    last_error      text             NOT NULL DEFAULT '',
+    paid_at         timestamptz,
+    created_at      timestamptz      NOT NULL DEFAULT now(),
+    updated_at      timestamptz      NOT NULL DEFAULT now(),
+    PRIMARY KEY (contest_id, user_id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS contest_payouts_key_idx ON contest_payouts (idempotency_key);
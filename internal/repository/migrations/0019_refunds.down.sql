@@ -0,0 +1 @@
+DROP TABLE IF EXISTS contest_refunds;
@@ -0,0 +1,15 @@
+-- возвраты оплаты отмененного или удаленного конкурса; без внешнего ключа - переживают удаление конкурса
+CREATE TABLE IF NOT EXISTS contest_refunds (
+    contest_id      bigint           NOT NULL,
+    user_id         bigint           NOT NULL,
+    amount          double precision NOT NULL,
+    status          text             NOT NULL DEFAULT 'pending',
+    idempotency_key text             NOT NULL,
+    attempts        integer          NOT NULL DEFAULT 0,
+    last_error      text             NOT NULL DEFAULT '',
+    refunded_at     timestamptz,
+    created_at      timestamptz      NOT NULL DEFAULT now(),
+    updated_at      timestamptz      NOT NULL DEFAULT now(),
+    PRIMARY KEY (contest_id, user_id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS contest_refunds_key_idx ON contest_refunds (idempotency_key);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS user_contests_user_contest_idx;
+DROP INDEX IF EXISTS user_contests_pending_idx;
+DROP INDEX IF EXISTS user_contests_payment_key_idx;
+ALTER TABLE user_contests DROP COLUMN IF EXISTS payment_key, DROP COLUMN IF EXISTS payment_status;
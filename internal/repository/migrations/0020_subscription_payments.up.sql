@@ -0,0 +1,22 @@
+-- подписка создается до списания денег в статусе pending и подтверждается после него;
+-- уже существующие подписки оплачены
+ALTER TABLE user_contests
+    ADD COLUMN IF NOT EXISTS payment_status text NOT NULL DEFAULT 'paid',
+    ADD COLUMN IF NOT EXISTS payment_key    text;
+CREATE UNIQUE INDEX IF NOT EXISTS user_contests_payment_key_idx ON user_contests (payment_key);
+CREATE INDEX IF NOT EXISTS user_contests_pending_idx ON user_contests (created_at) WHERE payment_status = 'pending';
+-- таблица могла быть создана до миграций без первичного ключа; повторная подписка должна быть невозможна
+-- дубликаты - это двойные списания, удалять их молча нельзя: миграция останавливается, их разбирают вручную
+DO $$
+DECLARE
+    duplicates bigint;
+BEGIN
+    SELECT count(*) INTO duplicates
+    FROM (SELECT 1 FROM user_contests GROUP BY user_id, contest_id HAVING count(*) > 1) d;
+    IF duplicates > 0 THEN
+        RAISE EXCEPTION 'user_contests has % duplicated (user_id, contest_id) subscriptions, refund and remove extra rows before migrating', duplicates
+            USING HINT = 'SELECT user_id, contest_id, count(*) FROM user_contests GROUP BY 1, 2 HAVING count(*) > 1';
+    END IF;
+END
+$$;
+CREATE UNIQUE INDEX IF NOT EXISTS user_contests_user_contest_idx ON user_contests (user_id, contest_id);
@@ -0,0 +1,8 @@
+ALTER TABLE user_contests DROP COLUMN IF EXISTS promo_code, DROP COLUMN IF EXISTS ticket_id;
+DROP TABLE IF EXISTS promo_codes;
+DROP INDEX IF EXISTS user_tickets_user_contest_idx;
+ALTER TABLE user_tickets
+    DROP COLUMN IF EXISTS created_at,
+    DROP COLUMN IF EXISTS redeemed_at,
+    DROP COLUMN IF EXISTS created_by,
+    DROP COLUMN IF EXISTS id;
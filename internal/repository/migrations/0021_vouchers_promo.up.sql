@@ -0,0 +1,26 @@
+-- билеты: бесплатные места, выданные админом; отмененные при возврате оплаты билеты тоже здесь
+ALTER TABLE user_tickets
+    ADD COLUMN IF NOT EXISTS id          bigserial PRIMARY KEY,
+    ADD COLUMN IF NOT EXISTS created_by  text,
+    ADD COLUMN IF NOT EXISTS redeemed_at timestamptz,
+    ADD COLUMN IF NOT EXISTS created_at  timestamptz NOT NULL DEFAULT now();
+CREATE INDEX IF NOT EXISTS user_tickets_user_contest_idx ON user_tickets (user_id, contest_id);
+
+CREATE TABLE IF NOT EXISTS promo_codes (
+    id         bigserial PRIMARY KEY,
+    code       text             NOT NULL,
+    contest_id bigint REFERENCES contests (id) ON DELETE CASCADE, -- NULL - действует на любой конкурс
+    kind       text             NOT NULL,
+    value      double precision NOT NULL,
+    max_uses   integer          NOT NULL DEFAULT 0,
+    used       integer          NOT NULL DEFAULT 0,
+    expires_at timestamptz,
+    created_by text,
+    created_at timestamptz      NOT NULL DEFAULT now()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS promo_codes_code_idx ON promo_codes (lower(code));
+
+-- чем оплачено место: билетом или со скидкой по промокоду
+ALTER TABLE user_contests
+    ADD COLUMN IF NOT EXISTS ticket_id  bigint,
+    ADD COLUMN IF NOT EXISTS promo_code text;
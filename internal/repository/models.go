@@ -5,57 +5,131 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/dwnGnL/pg-contests/internal/scoring"
 	"github.com/dwnGnL/pg-contests/lib/goerrors"
 	"github.com/lib/pq"
 	"gorm.io/gorm"
 )
 
-var layout = "2006-01-02T15:04Z07:00"
-var layoutOld = "2006-01-02T15:04"
-
 type UserContestResp struct {
 	ID             int64          `json:"id" gorm:"column:id"`
 	Title          string         `json:"title" gorm:"column:title"`
 	Price          float64        `json:"price" gorm:"column:price"`
-	StartTime      string         `json:"start_time" gorm:"column:start_time"`
+	StartTime      time.Time      `json:"start_time" gorm:"column:start_time"`
+	Timezone       string         `json:"timezone,omitempty" gorm:"column:timezone"`
 	PhotosLinks    pq.StringArray `json:"photos_links" gorm:"column:photos_links"`
 	QuestionsCount int64          `json:"questions_count" gorm:"column:questions_count"`
 	ContestLength  int64          `json:"contest_length" gorm:"column:contest_length"`
-	IsEnd          *bool          `json:"is_end" gorm:"column:is_end"`
+	Status         ContestStatus  `json:"status" gorm:"column:status"`
+	Mode           ContestMode    `json:"mode" gorm:"column:mode"`
+	AttemptWindow  int64          `json:"attempt_window,omitempty" gorm:"column:attempt_window"`
+	Teams          bool           `json:"teams" gorm:"column:teams"`
+	TeamSize       int            `json:"team_size,omitempty" gorm:"column:team_size"`
 	PurchaseDate   *time.Time     `json:"purchase_date" gorm:"column:purchase_date"`
 	PurchasePrice  *float64       `json:"purchase_price" gorm:"column:purchase_price"`
+	PaymentStatus  *PaymentStatus `json:"payment_status,omitempty" gorm:"column:payment_status"`
 }
 
 type Contest struct {
-	ID           int64      `json:"id" gorm:"column:id;primary_key;autoIncrement"`
-	Title        string     `json:"title" binding:"required" gorm:"column:title"`
-	Price        float64    `json:"price" binding:"required" gorm:"column:price"`
-	PlayersCount *int64     `json:"players_count" gorm:"players_count"`
-	StartTime    string     `json:"start_time" binding:"required" gorm:"column:start_time"`
-	CreatedBy    string     `json:"created_by" gorm:"column:created_by"`
-	Photos       []Photo    `json:"photos" gorm:"polymorphic:Owner;constraint:OnDelete:CASCADE;"`
-	Questions    []Question `json:"questions" gorm:"foreignKey:ContestID;constraint:OnDelete:CASCADE"`
-	Active       *bool      `json:"active" gorm:"column:active;default:true"`
-	IsEnd        *bool      `json:"is_end" gorm:"column:is_end;default:false"`
-	CreatedAt    *time.Time `json:"created_at" gorm:"autoCreateTime"`
+	ID               int64          `json:"id" gorm:"column:id;primary_key;autoIncrement"`
+	Title            string         `json:"title" binding:"required" gorm:"column:title"`
+	Price            float64        `json:"price" binding:"min=0" gorm:"column:price"` // 0 - бесплатный конкурс
+	PlayersCount     *int64         `json:"players_count" gorm:"players_count"`
+	StartTime        time.Time      `json:"start_time" binding:"required" gorm:"column:start_time"`
+	Timezone         string         `json:"timezone,omitempty" gorm:"column:timezone"` // IANA зона для отображения StartTime, пусто - UTC
+	Scoring          scoring.Policy `json:"scoring" gorm:"embedded;embeddedPrefix:scoring_"`
+	ShuffleAnswers   bool           `json:"shuffle_answers" gorm:"column:shuffle_answers"`     // свой порядок вариантов у каждого участника
+	ShuffleQuestions bool           `json:"shuffle_questions" gorm:"column:shuffle_questions"` // свой порядок вопросов, только для конкурсов в своем темпе
+	Mode             ContestMode    `json:"mode" gorm:"column:mode;default:live"`
+	AttemptWindow    int64          `json:"attempt_window,omitempty" gorm:"column:attempt_window"`   // self_paced: сколько секунд после StartTime можно начать попытку
+	Teams            bool           `json:"teams" gorm:"column:teams"`                               // участники играют командами, таблица по командам
+	CaptainAnswers   bool           `json:"captain_answers,omitempty" gorm:"column:captain_answers"` // за команду отвечает только капитан, иначе любой участник
+	TeamSize         int            `json:"team_size,omitempty" gorm:"column:team_size"`             // максимум участников в команде, 0 - без ограничения
+	Prizes           PrizeConfig    `json:"prizes" gorm:"column:prizes;type:jsonb"`                  // призы по местам, выплачиваются после окончания
+	CreatedBy        string         `json:"created_by" gorm:"column:created_by"`
+	Photos           []Photo        `json:"photos" gorm:"polymorphic:Owner;constraint:OnDelete:CASCADE;"`
+	Questions        []Question     `json:"questions" gorm:"foreignKey:ContestID;constraint:OnDelete:CASCADE"`
+	Bank             []BankDraw     `json:"bank,omitempty" gorm:"-"`                         // вопросы из банка, снимки добавляются к Questions при создании
+	TemplateID       *int64         `json:"template_id,omitempty" gorm:"column:template_id"` // из какого шаблона создан
+	ScheduleID       *int64         `json:"schedule_id,omitempty" gorm:"column:schedule_id"` // какое расписание его создало
+	Status           ContestStatus  `json:"status" gorm:"column:status;default:draft"`
+	ScheduledAt      *time.Time     `json:"scheduled_at" gorm:"column:scheduled_at"`
+	StartedAt        *time.Time     `json:"started_at" gorm:"column:started_at"`
+	FinishedAt       *time.Time     `json:"finished_at" gorm:"column:finished_at"`
+	CancelledAt      *time.Time     `json:"cancelled_at" gorm:"column:cancelled_at"`
+	ArchivedAt       *time.Time     `json:"archived_at" gorm:"column:archived_at"`
+	CreatedAt        *time.Time     `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// ContestMode live - все отвечают на вопросы одновременно от StartTime, self_paced - каждый участник по своей попытке,
+// elimination - как live, но неверный или пропущенный ответ выбивает участника из игры
+type ContestMode string
+
+const (
+	ModeLive        ContestMode = "live"
+	ModeSelfPaced   ContestMode = "self_paced"
+	ModeElimination ContestMode = "elimination"
+)
+
+type ContestStatus string
+
+const (
+	StatusDraft     ContestStatus = "draft"
+	StatusScheduled ContestStatus = "scheduled"
+	StatusLive      ContestStatus = "live"
+	StatusFinished  ContestStatus = "finished"
+	StatusCancelled ContestStatus = "cancelled"
+	StatusArchived  ContestStatus = "archived"
+)
+
+// TimestampColumn колонка, в которой хранится время перехода в этот статус
+func (s ContestStatus) TimestampColumn() string {
+	switch s {
+	case StatusScheduled:
+		return "scheduled_at"
+	case StatusLive:
+		return "started_at"
+	case StatusFinished:
+		return "finished_at"
+	case StatusCancelled:
+		return "cancelled_at"
+	case StatusArchived:
+		return "archived_at"
+	}
+	return ""
+}
+
+// Closed конкурс больше не идет и не начнется
+func (s ContestStatus) Closed() bool {
+	return s == StatusFinished || s == StatusCancelled || s == StatusArchived
 }
 
 type Question struct {
-	ID        int64    `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
-	ContestID int64    `json:"contest_id" binding:"required" gorm:"column:contest_id"`
-	Answers   []Answer `json:"answers" gorm:"foreignKey:QuestionID;constraint:OnDelete:CASCADE;"`
-	Title     string   `json:"title" binding:"required" gorm:"column:title"`
-	Score     int      `json:"score" binding:"required" gorm:"column:score"`
-	Order     int      `json:"order" binding:"required" gorm:"column:sort_order"`
-	Time      int64    `json:"time" binding:"required" gorm:"column:time"`
+	ID             int64        `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	ContestID      int64        `json:"contest_id" binding:"required" gorm:"column:contest_id"`
+	Answers        []Answer     `json:"answers" gorm:"foreignKey:QuestionID;constraint:OnDelete:CASCADE;"`
+	Photos         []Photo      `json:"photos" gorm:"polymorphic:Owner;constraint:OnDelete:CASCADE;"`
+	Title          string       `json:"title" binding:"required" gorm:"column:title"`
+	Type           QuestionType `json:"type" gorm:"column:type;default:single"`
+	PartialCredit  bool         `json:"partial_credit" gorm:"column:partial_credit"` // частичный балл для multi и ordering
+	Tolerance      float64      `json:"tolerance" gorm:"column:tolerance"`           // допуск для numeric
+	Score          int          `json:"score" binding:"required" gorm:"column:score"`
+	Order          int          `json:"order" binding:"required" gorm:"column:sort_order"`
+	Time           int64        `json:"time" binding:"required" gorm:"column:time"`
+	BankQuestionID *int64       `json:"bank_question_id,omitempty" gorm:"column:bank_question_id"` // снимок какого вопроса банка
+	Voided         bool         `json:"voided,omitempty" gorm:"column:voided;->"`                  // аннулирован, баллов не дает; пишут только AddHostEvent (void) и RegradeQuestion
+	UserAnswer     *UserAnswers `json:"user_answer,omitempty" gorm:"-"`                            // ответ участника в полной статистике
+	Points         *float64     `json:"points,omitempty" gorm:"-"`                                 // баллы участника за вопрос в полной статистике
 }
 
 type Answer struct {
-	ID         int64  `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
-	QuestionID int64  `json:"question_id" binding:"required" gorm:"column:question_id"`
-	Title      string `json:"title" binding:"required" gorm:"column:title"`
-	IsCorrect  *bool  `json:"is_correct" gorm:"column:is_correct;default:false"`
-	ChooseTime int64  `json:"choose_time,omitempty"` // время ответа в секундах //gorm:"column:choose_time;-:migration;->"
+	ID         int64   `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	QuestionID int64   `json:"question_id" binding:"required" gorm:"column:question_id"`
+	Title      string  `json:"title" binding:"required" gorm:"column:title"`
+	IsCorrect  *bool   `json:"is_correct" gorm:"column:is_correct;default:false"`
+	Position   int     `json:"position,omitempty" gorm:"column:position"` // правильная позиция для ordering
+	Photos     []Photo `json:"photos" gorm:"polymorphic:Owner;constraint:OnDelete:CASCADE;"`
+	ChooseTime int64   `json:"choose_time,omitempty"` // время ответа в секундах //gorm:"column:choose_time;-:migration;->"
 }
 
 type Photo struct {
@@ -65,6 +139,11 @@ type Photo struct {
 	Link      string `json:"link,omitempty" gorm:"column:link"`
 	OwnerID   int64  `json:"owner_id,omitempty" gorm:"column:owner_id"`
 	OwnerType string `json:"owner_type,omitempty" gorm:"column:owner_type"`
+	//заполняются при загрузке файла через хранилище
+	StorageKey    string `json:"-" gorm:"column:storage_key"`
+	ThumbnailLink string `json:"thumbnail_link,omitempty" gorm:"column:thumbnail_link"`
+	ContentType   string `json:"content_type,omitempty" gorm:"column:content_type"`
+	Size          int64  `json:"size,omitempty" gorm:"column:size"`
 }
 
 type UserContests struct {
@@ -73,30 +152,54 @@ type UserContests struct {
 	UserName  string     `json:"user_name,omitempty" gorm:"column:user_name"`
 	Email     string     `json:"email,omitempty" gorm:"column:email"`
 	Price     float64    `json:"price" gorm:"column:price"`
+	TeamID    *int64     `json:"team_id,omitempty" gorm:"column:team_id"` // команда в командном конкурсе
+	TeamName  string     `json:"team_name,omitempty" gorm:"-"`            // при подписке: создать команду с этим названием и стать ее капитаном
 	CreatedAt *time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	PaymentStatus PaymentStatus `json:"payment_status,omitempty" gorm:"column:payment_status"`
+	PaymentKey    string        `json:"-" gorm:"column:payment_key"` // ключ идемпотентности списания
+	TicketID      *int64        `json:"-" gorm:"column:ticket_id"`   // место по билету, без оплаты
+	PromoCode     string        `json:"promo_code,omitempty" gorm:"column:promo_code"`
 }
 
 type UserAnswers struct {
-	UserID     int64 `json:"user_id" gorm:"column:user_id;primaryKey"`
-	ContestID  int64 `json:"contest_id" gorm:"column:contest_id;primaryKey"`
-	QuestionID int64 `json:"question_id" gorm:"column:question_id;primaryKey"`
-	AnswerID   int64 `json:"answer_id" gorm:"column:answer_id"`
-	Time       int64 `json:"time" gorm:"column:time"`
+	UserID     int64         `json:"user_id" gorm:"column:user_id;primaryKey"`
+	ContestID  int64         `json:"contest_id" gorm:"column:contest_id;primaryKey"`
+	QuestionID int64         `json:"question_id" gorm:"column:question_id;primaryKey"`
+	AnswerID   int64         `json:"answer_id" gorm:"column:answer_id"`
+	AnswerIDs  pq.Int64Array `json:"answer_ids,omitempty" gorm:"column:answer_ids;type:bigint[]"` // multi и ordering (в порядке участника)
+	Value      string        `json:"value,omitempty" gorm:"column:value"`                         // numeric и text
+	Credit     float64       `json:"credit" gorm:"column:credit"`                                 // доля от Score, считается при ответе
+	Time       int64         `json:"time" gorm:"column:time"`
 }
 
 type ContestStats struct {
-	Rank         int64  `json:"rank" gorm:"column:rank"`
-	UserID       int64  `json:"user_id" gorm:"column:user_id"`
-	UserName     string `json:"user_name" gorm:"column:user_name"`
-	TotalScore   int    `json:"total_score" gorm:"column:total_score"`
-	TotalTime    int64  `json:"total_time" gorm:"column:total_time"`
-	TotalCorrect int64  `json:"total_correct" gorm:"column:total_correct"`
+	Rank         int64   `json:"rank" gorm:"column:rank"`
+	UserID       int64   `json:"user_id" gorm:"column:user_id"`
+	UserName     string  `json:"user_name" gorm:"column:user_name"`
+	TotalScore   float64 `json:"total_score" gorm:"column:total_score"`
+	TotalTime    int64   `json:"total_time" gorm:"column:total_time"`
+	TotalCorrect int64   `json:"total_correct" gorm:"column:total_correct"`
+	Eliminated   bool    `json:"eliminated,omitempty" gorm:"column:eliminated"` // выбыл из конкурса на выбывание
+	TeamID       int64   `json:"team_id,omitempty" gorm:"column:team_id"`       // строка команды, UserID тогда 0
+	TeamName     string  `json:"team_name,omitempty" gorm:"column:team_name"`
+}
+
+// ContestResult зафиксированная строка итоговой таблицы
+type ContestResult struct {
+	ContestID    int64 `gorm:"column:contest_id;primaryKey"`
+	ContestStats `gorm:"embedded"`
 }
 
+// UserTickets билет на место в конкурсе: выданный админом дает место бесплатно, при возврате оплаты билет отменяется
 type UserTickets struct {
-	UserID    int64 `gorm:"column:user_id"`
-	ContestID int64 `gorm:"column:contest_id"`
-	Canseled  bool  `gorm:"column:canseled;default:false"`
+	ID         int64      `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	UserID     int64      `json:"user_id" gorm:"column:user_id"`
+	ContestID  int64      `json:"contest_id" gorm:"column:contest_id"`
+	Canseled   bool       `json:"canceled" gorm:"column:canseled;default:false"`
+	CreatedBy  string     `json:"created_by,omitempty" gorm:"column:created_by"`
+	RedeemedAt *time.Time `json:"redeemed_at" gorm:"column:redeemed_at"`
+	CreatedAt  *time.Time `json:"created_at" gorm:"autoCreateTime"`
 }
 
 type ErrorResponse struct {
@@ -108,63 +211,147 @@ type ErrorStruct struct {
 }
 
 func (c *Contest) Validate() error {
-	_, err := time.Parse(layout, c.StartTime)
+	if c.StartTime.IsZero() {
+		err := errors.New("start_time is required")
+		goerrors.Log().Warnln(err)
+		return err
+	}
+	if c.Price < 0 {
+		return errors.New("price can't be negative")
+	}
+	_, err := time.LoadLocation(c.Timezone)
 	if err != nil {
-		goerrors.Log().Warnln("err on contest startTime Parse ", err)
+		goerrors.Log().Warnln("err on contest timezone load ", err)
+		return err
+	}
+	if err = c.Scoring.Validate(); err != nil {
+		goerrors.Log().Warnln("err on contest scoring validate ", err)
+		return err
+	}
+	if err = c.validateMode(); err != nil {
+		goerrors.Log().Warnln(err)
+		return err
+	}
+	if err = c.validateTeams(); err != nil {
+		goerrors.Log().Warnln(err)
+		return err
+	}
+	if err = c.Prizes.Validate(); err != nil {
+		goerrors.Log().Warnln(err)
 		return err
 	}
 	for i, question := range c.Questions {
-		if len(question.Answers) < 1 {
-			err = errors.New(fmt.Sprintf("Попытка добавления вопроса №%d без ответа", i))
+		if err = question.Validate(); err != nil {
+			err = fmt.Errorf("Попытка добавления вопроса №%d: %w", i, err)
 			goerrors.Log().Warnln(err)
 			return err
 		}
-		correctAnsExist := false
-		for _, answer := range question.Answers {
-			if *answer.IsCorrect {
-				correctAnsExist = true
-				break
-			}
+	}
+	return nil
+}
+
+// validateMode пустой режим - живой конкурс, как было до появления режимов
+func (c *Contest) validateMode() error {
+	if c.Mode == "" {
+		c.Mode = ModeLive
+	}
+	switch c.Mode {
+	case ModeLive, ModeElimination:
+		if c.ShuffleQuestions {
+			//в живом конкурсе все отвечают на один и тот же вопрос одновременно
+			return errors.New("shuffle_questions is available for self-paced contests only")
 		}
-		if !correctAnsExist {
-			err = errors.New(fmt.Sprintf("Попытка добавления вопроса №%d c без правильных ответов", i))
-			goerrors.Log().Warnln(err)
-			return err
+		if c.AttemptWindow != 0 {
+			return errors.New("attempt_window is available for self-paced contests only")
 		}
+	case ModeSelfPaced:
+		if c.AttemptWindow <= 0 {
+			return errors.New("attempt_window is required for self-paced contests")
+		}
+	default:
+		return fmt.Errorf("unknown contest mode %q", c.Mode)
 	}
 	return nil
 }
 
-func (c *Contest) Started() (bool, error) {
-	startTime, err := time.Parse(layout, c.StartTime)
-	if err != nil {
-		startTime, err = time.Parse(layoutOld, c.StartTime)
-		if err != nil {
-			goerrors.Log().Warnln("err on contest startTime Parse ", err)
-			return false, err
+func (c *Contest) validateTeams() error {
+	if c.TeamSize < 0 {
+		return errors.New("team_size can't be negative")
+	}
+	if !c.Teams {
+		if c.CaptainAnswers || c.TeamSize != 0 {
+			return errors.New("captain_answers and team_size are available for team contests only")
 		}
+		return nil
 	}
-	startTimeUnix := startTime.Unix()
-	now := time.Now().Unix()
-	remained := now - startTimeUnix
-	if remained >= 0 {
-		return true, nil
+	//ответ команды общий, поэтому все ее участники должны быть на одном и том же вопросе
+	if c.Mode != ModeLive {
+		return errors.New("teams are available for live contests only")
 	}
-	return false, nil
+	return nil
+}
+
+func (c *Contest) SelfPaced() bool {
+	return c.Mode == ModeSelfPaced
+}
+
+func (c *Contest) Elimination() bool {
+	return c.Mode == ModeElimination
+}
+
+// Duration длительность всех вопросов, то есть одного прохождения
+func (c *Contest) Duration() time.Duration {
+	var totalTime int64
+	for _, question := range c.Questions {
+		totalTime += question.Time
+	}
+	return time.Duration(totalTime) * time.Second
+}
+
+// AttemptsUntil до этого момента можно начать попытку конкурса в своем темпе
+func (c *Contest) AttemptsUntil() time.Time {
+	return c.StartTime.Add(time.Duration(c.AttemptWindow) * time.Second)
+}
+
+// EndAt время окончания последнего вопроса; в своем темпе - последней попытки, начатой в конце окна
+func (c *Contest) EndAt() time.Time {
+	if c.SelfPaced() {
+		return c.AttemptsUntil().Add(c.Duration())
+	}
+	return c.StartTime.Add(c.Duration())
+}
+
+func (c *Contest) Started() bool {
+	return !time.Now().Before(c.StartTime)
+}
+
+// displayLocation зона для отображения StartTime, неизвестная зона отображается как UTC
+func displayLocation(timezone string) *time.Location {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
 }
 
 func (c *Contest) BeforeDelete(tx *gorm.DB) (err error) {
 	fmt.Println("BEFORE DELETE---------------------", c.ID, "---", c.StartTime)
-	err = tx.Where("owner_id = ? and owner_type = ?", c.ID, "contests").Delete(&Photo{}).Error
+	err = tx.Where("owner_id = ? and owner_type = ?", c.ID, PhotoOwnerContests).Delete(&Photo{}).Error
 	if err != nil {
 		return err
 	}
-	// started, err := c.Started()
-	// if err != nil {
-	// 	goerrors.Log().Warnln("BEFORE DELETE check contest if started error: ", err)
-	// 	return err
-	// }
-	// if started {
+	//вопросы и ответы удаляются каскадом в базе, их хуки не вызываются
+	err = tx.Where("owner_type = ? and owner_id IN (SELECT a.id FROM answers a JOIN questions q ON q.id = a.question_id WHERE q.contest_id = ?)",
+		PhotoOwnerAnswers, c.ID).Delete(&Photo{}).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Where("owner_type = ? and owner_id IN (SELECT id FROM questions WHERE contest_id = ?)", PhotoOwnerQuestions, c.ID).
+		Delete(&Photo{}).Error
+	if err != nil {
+		return err
+	}
+	// if c.Started() {
 	// 	err = errors.New(fmt.Sprintf("Конкурс №%d уже начался", c.ID))
 	// 	goerrors.Log().Warnln("BEFORE DELETE ", err)
 	// 	return err
@@ -172,6 +359,17 @@ func (c *Contest) BeforeDelete(tx *gorm.DB) (err error) {
 	return
 }
 
+// BeforeSave точность StartTime до секунды, в базе храним в UTC
+func (c *Contest) BeforeSave(tx *gorm.DB) (err error) {
+	c.StartTime = c.StartTime.Truncate(time.Second).UTC()
+	return
+}
+
+func (c *Contest) AfterFind(tx *gorm.DB) (err error) {
+	c.StartTime = c.StartTime.In(displayLocation(c.Timezone))
+	return
+}
+
 func (c *Contest) BeforeCreate(tx *gorm.DB) (err error) {
 	//fmt.Println("BEFORE Create---------------------", c.ID)
 	err = c.Validate()
@@ -182,6 +380,20 @@ func (c *Contest) BeforeCreate(tx *gorm.DB) (err error) {
 	return
 }
 
+// BeforeDelete ответы удаляются каскадом в базе, поэтому их фото удаляем здесь же
+func (q *Question) BeforeDelete(tx *gorm.DB) (err error) {
+	err = tx.Where("owner_type = ? and owner_id IN (SELECT id FROM answers WHERE question_id = ?)", PhotoOwnerAnswers, q.ID).
+		Delete(&Photo{}).Error
+	if err != nil {
+		return err
+	}
+	return tx.Where("owner_id = ? and owner_type = ?", q.ID, PhotoOwnerQuestions).Delete(&Photo{}).Error
+}
+
+func (a *Answer) BeforeDelete(tx *gorm.DB) (err error) {
+	return tx.Where("owner_id = ? and owner_type = ?", a.ID, PhotoOwnerAnswers).Delete(&Photo{}).Error
+}
+
 type NullString64Array struct {
 	StringArray pq.StringArray
 	Valid       bool
@@ -0,0 +1,25 @@
+package repository
+
+// owner_type фото - имя таблицы владельца, так его проставляет gorm
+const (
+	PhotoOwnerContests  = "contests"
+	PhotoOwnerQuestions = "questions"
+	PhotoOwnerAnswers   = "answers"
+)
+
+func (r RepoImpl) CreatePhoto(photo *Photo) error {
+	return r.db.Create(photo).Error
+}
+
+func (r RepoImpl) GetPhoto(photoID int64) (photo *Photo, err error) {
+	err = r.db.First(&photo, photoID).Error
+	return
+}
+
+func (r RepoImpl) UpdatePhoto(photo *Photo) error {
+	return r.db.Save(photo).Error
+}
+
+func (r RepoImpl) DeletePhoto(photoID int64) error {
+	return r.db.Delete(&Photo{ID: photoID}).Error
+}
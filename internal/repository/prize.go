@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TieRule как делить призы между участниками с одинаковыми баллами и временем
+type TieRule string
+
+const (
+	TieSplit TieRule = "split" // призы всех занятых ими мест делятся поровну
+	TieRank  TieRule = "rank"  // места по порядку таблицы, как при обычном подсчете
+)
+
+// Prize приз места: фиксированная сумма Amount или Percent процентов от фонда
+type Prize struct {
+	Place   int     `json:"place"`
+	Amount  float64 `json:"amount,omitempty"`
+	Percent float64 `json:"percent,omitempty"`
+}
+
+// PrizeConfig призы конкурса, хранятся в jsonb. Фонд - сумма, которую заплатили участники
+type PrizeConfig struct {
+	Places []Prize `json:"places,omitempty"`
+	Ties   TieRule `json:"ties,omitempty"`
+}
+
+func (p PrizeConfig) Value() (driver.Value, error) {
+	data, err := json.Marshal(p)
+	return string(data), err
+}
+
+func (p *PrizeConfig) Scan(value interface{}) error {
+	switch data := value.(type) {
+	case nil:
+		*p = PrizeConfig{}
+		return nil
+	case []byte:
+		return json.Unmarshal(data, p)
+	case string:
+		return json.Unmarshal([]byte(data), p)
+	}
+	return fmt.Errorf("unsupported prizes value %T", value)
+}
+
+// Validate пустое правило ничьих - split
+func (p *PrizeConfig) Validate() error {
+	switch p.Ties {
+	case "":
+		p.Ties = TieSplit
+	case TieSplit, TieRank:
+	default:
+		return fmt.Errorf("unknown prize ties rule %q", p.Ties)
+	}
+	seen := make(map[int]bool, len(p.Places))
+	var percent float64
+	for _, prize := range p.Places {
+		if prize.Place < 1 || seen[prize.Place] {
+			return errors.New("prize places must be unique and start from 1")
+		}
+		seen[prize.Place] = true
+		if prize.Amount < 0 || prize.Percent < 0 || (prize.Amount > 0) == (prize.Percent > 0) {
+			return fmt.Errorf("prize for place %d needs either amount or percent", prize.Place)
+		}
+		percent += prize.Percent
+	}
+	if percent > 100 {
+		return errors.New("prize percents exceed the pool")
+	}
+	return nil
+}
+
+// Equal одинаковые призы; пустое правило ничьих то же, что split
+func (p PrizeConfig) Equal(other PrizeConfig) bool {
+	ties := func(rule TieRule) TieRule {
+		if rule == "" {
+			return TieSplit
+		}
+		return rule
+	}
+	if ties(p.Ties) != ties(other.Ties) || len(p.Places) != len(other.Places) {
+		return false
+	}
+	for i := range p.Places {
+		if p.Places[i] != other.Places[i] {
+			return false
+		}
+	}
+	return true
+}
+
+type PayoutStatus string
+
+const (
+	PayoutPending    PayoutStatus = "pending"
+	PayoutProcessing PayoutStatus = "processing"
+	PayoutPaid       PayoutStatus = "paid"
+	PayoutFailed     PayoutStatus = "failed"
+)
+
+// Payout выплата приза игроку; приз команды делится между ее участниками
+type Payout struct {
+	ContestID      int64        `json:"contest_id" gorm:"column:contest_id;primaryKey"`
+	UserID         int64        `json:"user_id" gorm:"column:user_id;primaryKey"`
+	TeamID         int64        `json:"team_id,omitempty" gorm:"column:team_id"`
+	Place          int          `json:"place" gorm:"column:place"`
+	Amount         float64      `json:"amount" gorm:"column:amount"`
+	Status         PayoutStatus `json:"status" gorm:"column:status"`
+	IdempotencyKey string       `json:"idempotency_key" gorm:"column:idempotency_key"`
+	Attempts       int          `json:"attempts" gorm:"column:attempts"`
+	LastError      string       `json:"last_error,omitempty" gorm:"column:last_error"`
+	PaidAt         *time.Time   `json:"paid_at" gorm:"column:paid_at"`
+	CreatedAt      *time.Time   `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      *time.Time   `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (Payout) TableName() string {
+	return "contest_payouts"
+}
+
+// CreatePayouts выплаты считаются один раз: если у конкурса они уже есть, ничего не меняется
+func (r RepoImpl) CreatePayouts(contestID int64, payouts []Payout) error {
+	if len(payouts) == 0 {
+		return nil
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		//фиксация итогов может случиться на двух инстансах одновременно
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", contestID).Take(&Contest{}).Error
+		if err != nil {
+			return err
+		}
+		var count int64
+		if err = tx.Model(&Payout{}).Where("contest_id = ?", contestID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+		return tx.Create(&payouts).Error
+	})
+}
+
+func (r RepoImpl) GetContestPayouts(contestID int64) (payouts []Payout, err error) {
+	err = r.db.Where("contest_id = ?", contestID).Order("place, user_id").Find(&payouts).Error
+	return
+}
+
+// payoutStuckAfter выплата в работе дольше этого, скорее всего, брошена упавшим инстансом
+const payoutStuckAfter = 10 * time.Minute
+
+// ClaimPayout берет выплату в работу, false - ее уже выплатил или выплачивает кто-то другой.
+// Брошенную выплату можно взять повторно: ключ идемпотентности не даст зачислить ее дважды
+func (r RepoImpl) ClaimPayout(contestID, userID int64) (bool, error) {
+	res := r.db.Model(&Payout{}).
+		Where("contest_id = ? AND user_id = ?", contestID, userID).
+		Where("status IN ? OR (status = ? AND updated_at < ?)", []PayoutStatus{PayoutPending, PayoutFailed},
+			PayoutProcessing, time.Now().Add(-payoutStuckAfter)).
+		Updates(map[string]interface{}{"status": PayoutProcessing, "attempts": gorm.Expr("attempts + 1")})
+	return res.RowsAffected == 1, res.Error
+}
+
+// FinishPayout итог попытки выплаты: payErr == nil - выплачено
+func (r RepoImpl) FinishPayout(contestID, userID int64, payErr error) error {
+	updates := map[string]interface{}{"status": PayoutPaid, "last_error": "", "paid_at": time.Now()}
+	if payErr != nil {
+		updates = map[string]interface{}{"status": PayoutFailed, "last_error": payErr.Error()}
+	}
+	return r.db.Model(&Payout{}).Where("contest_id = ? AND user_id = ?", contestID, userID).Updates(updates).Error
+}
+
+// GetFinalResults вся зафиксированная таблица по местам
+func (r RepoImpl) GetFinalResults(contestID int64) (results []ContestStats, err error) {
+	err = r.db.Model(&ContestResult{}).Where("contest_id = ?", contestID).Order("rank").Scan(&results).Error
+	return
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPromoNotFound    = errors.New("promo code not found")
+	ErrPromoUnavailable = errors.New("promo code is expired, used up or not valid for this contest")
+	ErrPromoCodeTaken   = errors.New("promo code already exists")
+)
+
+type PromoKind string
+
+const (
+	PromoPercent PromoKind = "percent" // скидка Value процентов
+	PromoFixed   PromoKind = "fixed"   // скидка Value в деньгах, цена не уходит ниже 0
+)
+
+// PromoCode скидка на оплату участия; код сравнивается без учета регистра
+type PromoCode struct {
+	ID        int64      `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	Code      string     `json:"code" binding:"required" gorm:"column:code"`
+	ContestID *int64     `json:"contest_id,omitempty" gorm:"column:contest_id"` // nil - на любой конкурс
+	Kind      PromoKind  `json:"kind" binding:"required" gorm:"column:kind"`
+	Value     float64    `json:"value" gorm:"column:value"`
+	MaxUses   int        `json:"max_uses" gorm:"column:max_uses"` // 0 - без ограничения
+	Used      int        `json:"used" gorm:"column:used"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" gorm:"column:expires_at"`
+	CreatedBy string     `json:"created_by" gorm:"column:created_by"`
+	CreatedAt *time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (p *PromoCode) Validate() error {
+	p.Code = strings.TrimSpace(p.Code)
+	if p.Code == "" {
+		return errors.New("code is required")
+	}
+	switch p.Kind {
+	case PromoPercent:
+		if p.Value <= 0 || p.Value > 100 {
+			return errors.New("percent discount must be in (0, 100]")
+		}
+	case PromoFixed:
+		if p.Value <= 0 {
+			return errors.New("fixed discount must be positive")
+		}
+	default:
+		return errors.New("kind must be percent or fixed")
+	}
+	if p.MaxUses < 0 {
+		return errors.New("max_uses can't be negative")
+	}
+	return nil
+}
+
+// Available можно ли применить код к конкурсу сейчас; окончательно лимит проверяется при записи подписки
+func (p *PromoCode) Available(contestID int64, now time.Time) error {
+	if p.ContestID != nil && *p.ContestID != contestID {
+		return ErrPromoUnavailable
+	}
+	if p.ExpiresAt != nil && !now.Before(*p.ExpiresAt) {
+		return ErrPromoUnavailable
+	}
+	if p.MaxUses > 0 && p.Used >= p.MaxUses {
+		return ErrPromoUnavailable
+	}
+	return nil
+}
+
+// Apply цена со скидкой, до копеек
+func (p *PromoCode) Apply(price float64) float64 {
+	switch p.Kind {
+	case PromoPercent:
+		price -= price * p.Value / 100
+	case PromoFixed:
+		price -= p.Value
+	}
+	return math.Max(0, math.Round(price*100)/100)
+}
+
+func (r RepoImpl) CreatePromoCode(promo *PromoCode) error {
+	if err := promo.Validate(); err != nil {
+		return err
+	}
+	var taken int64
+	if err := r.db.Model(&PromoCode{}).Where("lower(code) = lower(?)", promo.Code).Count(&taken).Error; err != nil {
+		return err
+	}
+	if taken > 0 {
+		return ErrPromoCodeTaken
+	}
+	return r.db.Create(promo).Error
+}
+
+func (r RepoImpl) GetPromoCodes() (promos []PromoCode, err error) {
+	err = r.db.Order("id").Find(&promos).Error
+	return
+}
+
+func (r RepoImpl) GetPromoCode(code string) (*PromoCode, error) {
+	var promo PromoCode
+	err := r.db.Where("lower(code) = lower(?)", strings.TrimSpace(code)).Take(&promo).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPromoNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &promo, nil
+}
+
+func (r RepoImpl) DeletePromoCode(promoID int64) error {
+	res := r.db.Delete(&PromoCode{ID: promoID})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// usePromo использование кода считается в транзакции подписки, поэтому лимит не превысить одновременными подписками
+func usePromo(tx *gorm.DB, code string, contestID int64) error {
+	res := tx.Model(&PromoCode{}).
+		Where("lower(code) = lower(?) AND (contest_id IS NULL OR contest_id = ?)", code, contestID).
+		Where("(max_uses = 0 OR used < max_uses) AND (expires_at IS NULL OR expires_at > now())").
+		UpdateColumn("used", gorm.Expr("used + 1"))
+	if res.Error == nil && res.RowsAffected == 0 {
+		return ErrPromoUnavailable
+	}
+	return res.Error
+}
+
+// releasePromo использование кода возвращается, если место так и не было оплачено
+func releasePromo(tx *gorm.DB, code string) error {
+	return tx.Model(&PromoCode{}).Where("lower(code) = lower(?) AND used > 0", code).
+		UpdateColumn("used", gorm.Expr("used - 1")).Error
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPromoCodeAvailable(t *testing.T) {
+	now := time.Now()
+	contestID, expired, later := int64(5), now.Add(-time.Minute), now.Add(time.Hour)
+	tests := []struct {
+		name  string
+		promo PromoCode
+		ok    bool
+	}{
+		{name: "any contest, no limits", promo: PromoCode{}, ok: true},
+		{name: "this contest", promo: PromoCode{ContestID: &contestID}, ok: true},
+		{name: "other contest", promo: PromoCode{ContestID: new(int64)}},
+		{name: "not expired", promo: PromoCode{ExpiresAt: &later}, ok: true},
+		{name: "expired", promo: PromoCode{ExpiresAt: &expired}},
+		{name: "uses left", promo: PromoCode{MaxUses: 3, Used: 2}, ok: true},
+		{name: "used up", promo: PromoCode{MaxUses: 3, Used: 3}},
+		{name: "unlimited uses", promo: PromoCode{Used: 1000}, ok: true},
+	}
+	for _, tt := range tests {
+		err := tt.promo.Available(contestID, now)
+		if tt.ok && err != nil || !tt.ok && !errors.Is(err, ErrPromoUnavailable) {
+			t.Errorf("%s: Available = %v", tt.name, err)
+		}
+	}
+}
+
+func TestPromoCodeApply(t *testing.T) {
+	tests := []struct {
+		promo PromoCode
+		price float64
+		want  float64
+	}{
+		{promo: PromoCode{Kind: PromoPercent, Value: 25}, price: 100, want: 75},
+		{promo: PromoCode{Kind: PromoPercent, Value: 100}, price: 100, want: 0},
+		{promo: PromoCode{Kind: PromoPercent, Value: 33}, price: 9.99, want: 6.69},
+		{promo: PromoCode{Kind: PromoFixed, Value: 30}, price: 100, want: 70},
+		{promo: PromoCode{Kind: PromoFixed, Value: 150}, price: 100, want: 0},
+	}
+	for _, tt := range tests {
+		if got := tt.promo.Apply(tt.price); got != tt.want {
+			t.Errorf("%s %v off %v = %v, want %v", tt.promo.Kind, tt.promo.Value, tt.price, got, tt.want)
+		}
+	}
+}
+
+func TestPromoCodeValidate(t *testing.T) {
+	valid := PromoCode{Code: "  SPRING ", Kind: PromoPercent, Value: 10}
+	if err := valid.Validate(); err != nil || valid.Code != "SPRING" {
+		t.Fatalf("Validate = %v, code %q", err, valid.Code)
+	}
+	for _, promo := range []PromoCode{
+		{Code: " ", Kind: PromoPercent, Value: 10},
+		{Code: "A", Kind: PromoPercent, Value: 101},
+		{Code: "A", Kind: PromoFixed},
+		{Code: "A", Kind: "gift", Value: 1},
+		{Code: "A", Kind: PromoFixed, Value: 1, MaxUses: -1},
+	} {
+		if err := promo.Validate(); err == nil {
+			t.Errorf("promo %+v must be invalid", promo)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type QuestionType string
+
+const (
+	QuestionSingle   QuestionType = "single"   // один правильный вариант
+	QuestionMulti    QuestionType = "multi"    // несколько правильных вариантов
+	QuestionNumeric  QuestionType = "numeric"  // число, правильное значение в Title правильного ответа
+	QuestionText     QuestionType = "text"     // короткий текст, правильные ответы - допустимые варианты
+	QuestionOrdering QuestionType = "ordering" // расставить варианты по Position
+)
+
+// Kind тип вопроса, пустой тип у вопросов созданных до появления типов
+func (q *Question) Kind() QuestionType {
+	if q.Type == "" {
+		return QuestionSingle
+	}
+	return q.Type
+}
+
+// HidesAnswers варианты ответа таких вопросов нельзя отдавать участникам, это и есть правильные ответы
+func (q *Question) HidesAnswers() bool {
+	kind := q.Kind()
+	return kind == QuestionNumeric || kind == QuestionText
+}
+
+func (a *Answer) Correct() bool {
+	return a.IsCorrect != nil && *a.IsCorrect
+}
+
+func (q *Question) Validate() error {
+	correct := 0
+	for _, answer := range q.Answers {
+		if answer.Correct() {
+			correct++
+		}
+	}
+	switch q.Kind() {
+	case QuestionSingle:
+		if len(q.Answers) < 1 {
+			return errors.New("вопрос без ответа")
+		}
+		if correct == 0 {
+			return errors.New("вопрос без правильных ответов")
+		}
+	case QuestionMulti:
+		if len(q.Answers) < 2 {
+			return errors.New("вопрос с несколькими ответами должен иметь минимум 2 варианта")
+		}
+		if correct == 0 {
+			return errors.New("вопрос без правильных ответов")
+		}
+	case QuestionNumeric:
+		if correct == 0 {
+			return errors.New("числовой вопрос без правильного значения")
+		}
+		for _, answer := range q.Answers {
+			if _, err := parseNumber(answer.Title); answer.Correct() && err != nil {
+				return fmt.Errorf("правильный ответ %q не число", answer.Title)
+			}
+		}
+		if q.Tolerance < 0 {
+			return errors.New("допуск не может быть отрицательным")
+		}
+	case QuestionText:
+		for _, answer := range q.Answers {
+			if answer.Correct() && normalizeText(answer.Title) == "" {
+				return errors.New("пустой правильный ответ")
+			}
+		}
+		if correct == 0 {
+			return errors.New("текстовый вопрос без правильных ответов")
+		}
+	case QuestionOrdering:
+		if len(q.Answers) < 2 {
+			return errors.New("вопрос на порядок должен иметь минимум 2 варианта")
+		}
+		seen := make(map[int]bool, len(q.Answers))
+		for _, answer := range q.Answers {
+			if answer.Position < 1 || answer.Position > len(q.Answers) || seen[answer.Position] {
+				return errors.New("позиции вариантов должны быть перестановкой 1..N")
+			}
+			seen[answer.Position] = true
+		}
+	default:
+		return fmt.Errorf("неизвестный тип вопроса %q", q.Type)
+	}
+	return nil
+}
+
+// Grade доля от Score (0..1), которую заслуживает ответ участника
+func (q *Question) Grade(ua *UserAnswers) float64 {
+	switch q.Kind() {
+	case QuestionSingle:
+		chosen := ua.AnswerID
+		if chosen == 0 && len(ua.AnswerIDs) > 0 {
+			chosen = ua.AnswerIDs[0]
+		}
+		for _, answer := range q.Answers {
+			if answer.ID == chosen && answer.Correct() {
+				return 1
+			}
+		}
+	case QuestionMulti:
+		return q.gradeMulti(ua.ChosenIDs())
+	case QuestionNumeric:
+		value, err := parseNumber(ua.Value)
+		if err != nil {
+			return 0
+		}
+		for _, answer := range q.Answers {
+			expected, err := parseNumber(answer.Title)
+			if answer.Correct() && err == nil && math.Abs(value-expected) <= q.Tolerance {
+				return 1
+			}
+		}
+	case QuestionText:
+		value := normalizeText(ua.Value)
+		if value == "" {
+			return 0
+		}
+		for _, answer := range q.Answers {
+			if answer.Correct() && normalizeText(answer.Title) == value {
+				return 1
+			}
+		}
+	case QuestionOrdering:
+		return q.gradeOrdering(ua.AnswerIDs)
+	}
+	return 0
+}
+
+func (q *Question) gradeMulti(chosen []int64) float64 {
+	correct := make(map[int64]bool)
+	for _, answer := range q.Answers {
+		if answer.Correct() {
+			correct[answer.ID] = true
+		}
+	}
+	hits, misses := 0, 0
+	seen := make(map[int64]bool, len(chosen))
+	for _, id := range chosen {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if correct[id] {
+			hits++
+		} else {
+			misses++
+		}
+	}
+	if hits == len(correct) && misses == 0 {
+		return 1
+	}
+	if !q.PartialCredit || len(correct) == 0 {
+		return 0
+	}
+	//каждый неверно отмеченный вариант отменяет один верный
+	return math.Max(0, float64(hits-misses)/float64(len(correct)))
+}
+
+func (q *Question) gradeOrdering(order []int64) float64 {
+	answers := make([]Answer, len(q.Answers))
+	copy(answers, q.Answers)
+	sort.Slice(answers, func(i, j int) bool {
+		return answers[i].Position < answers[j].Position
+	})
+	if len(order) != len(answers) || len(answers) == 0 {
+		return 0
+	}
+	inPlace := 0
+	for i, answer := range answers {
+		if order[i] == answer.ID {
+			inPlace++
+		}
+	}
+	if inPlace == len(answers) {
+		return 1
+	}
+	if !q.PartialCredit {
+		return 0
+	}
+	return float64(inPlace) / float64(len(answers))
+}
+
+// ChosenIDs выбранные варианты независимо от того, в каком поле их прислали
+func (ua *UserAnswers) ChosenIDs() []int64 {
+	if len(ua.AnswerIDs) > 0 {
+		return ua.AnswerIDs
+	}
+	if ua.AnswerID != 0 {
+		return []int64{ua.AnswerID}
+	}
+	return nil
+}
+
+func (ua *UserAnswers) sameAnswer(other *UserAnswers) bool {
+	if ua.AnswerID != other.AnswerID || ua.Value != other.Value || len(ua.AnswerIDs) != len(other.AnswerIDs) {
+		return false
+	}
+	for i := range ua.AnswerIDs {
+		if ua.AnswerIDs[i] != other.AnswerIDs[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func parseNumber(value string) (float64, error) {
+	value = strings.ReplaceAll(strings.TrimSpace(value), ",", ".")
+	return strconv.ParseFloat(value, 64)
+}
+
+// normalizeText регистр, пунктуация, лишние пробелы и ё/е не влияют на совпадение текстового ответа
+func normalizeText(value string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(value) {
+		switch {
+		case r == 'ё':
+			r = 'е'
+		case unicode.IsSpace(r):
+			space = b.Len() > 0
+			continue
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			continue
+		}
+		if space {
+			b.WriteRune(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"math"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func testAnswer(id int64, title string, correct bool, position int) Answer {
+	return Answer{ID: id, Title: title, IsCorrect: &correct, Position: position}
+}
+
+func TestGrade(t *testing.T) {
+	single := Question{Answers: []Answer{testAnswer(1, "3", false, 0), testAnswer(2, "4", true, 0)}}
+	multi := Question{Type: QuestionMulti, Answers: []Answer{
+		testAnswer(1, "2", true, 0), testAnswer(2, "3", true, 0), testAnswer(3, "4", false, 0), testAnswer(4, "5", true, 0),
+	}}
+	partialMulti := multi
+	partialMulti.PartialCredit = true
+	numeric := Question{Type: QuestionNumeric, Tolerance: 0.01, Answers: []Answer{testAnswer(1, "3.14", true, 0), testAnswer(2, "3", false, 0)}}
+	text := Question{Type: QuestionText, Answers: []Answer{testAnswer(1, "Ёж Колючий", true, 0), testAnswer(2, "Заяц", false, 0)}}
+	ordering := Question{Type: QuestionOrdering, Answers: []Answer{
+		testAnswer(1, "десять", false, 2), testAnswer(2, "один", false, 1), testAnswer(3, "сто", false, 3),
+	}}
+	partialOrdering := ordering
+	partialOrdering.PartialCredit = true
+
+	tests := []struct {
+		name     string
+		question Question
+		answer   UserAnswers
+		want     float64
+	}{
+		{name: "single correct", question: single, answer: UserAnswers{AnswerID: 2}, want: 1},
+		{name: "single wrong", question: single, answer: UserAnswers{AnswerID: 1}},
+		{name: "single unknown answer", question: single, answer: UserAnswers{AnswerID: 9}},
+		{name: "single in answer_ids", question: single, answer: UserAnswers{AnswerIDs: pq.Int64Array{2}}, want: 1},
+		{name: "single no answer", question: single},
+
+		{name: "multi all correct", question: multi, answer: UserAnswers{AnswerIDs: pq.Int64Array{4, 1, 2}}, want: 1},
+		{name: "multi duplicates are ignored", question: multi, answer: UserAnswers{AnswerIDs: pq.Int64Array{1, 1, 2, 4}}, want: 1},
+		{name: "multi missing one without partial credit", question: multi, answer: UserAnswers{AnswerIDs: pq.Int64Array{1, 2}}},
+		{name: "multi extra one without partial credit", question: multi, answer: UserAnswers{AnswerIDs: pq.Int64Array{1, 2, 3, 4}}},
+		{name: "multi missing one", question: partialMulti, answer: UserAnswers{AnswerIDs: pq.Int64Array{1, 2}}, want: 2.0 / 3},
+		{name: "multi wrong cancels a hit", question: partialMulti, answer: UserAnswers{AnswerIDs: pq.Int64Array{1, 2, 3}}, want: 1.0 / 3},
+		{name: "multi only wrong", question: partialMulti, answer: UserAnswers{AnswerIDs: pq.Int64Array{3}}},
+		{name: "multi single answer_id", question: partialMulti, answer: UserAnswers{AnswerID: 1}, want: 1.0 / 3},
+
+		{name: "numeric exact", question: numeric, answer: UserAnswers{Value: "3.14"}, want: 1},
+		{name: "numeric within tolerance and comma", question: numeric, answer: UserAnswers{Value: " 3,149 "}, want: 1},
+		{name: "numeric outside tolerance", question: numeric, answer: UserAnswers{Value: "3.16"}},
+		{name: "numeric incorrect answer value", question: numeric, answer: UserAnswers{Value: "3"}},
+		{name: "numeric not a number", question: numeric, answer: UserAnswers{Value: "пи"}},
+
+		{name: "text normalized", question: text, answer: UserAnswers{Value: "  ЕЖ,   колючий! "}, want: 1},
+		{name: "text incorrect answer", question: text, answer: UserAnswers{Value: "заяц"}},
+		{name: "text empty", question: text, answer: UserAnswers{Value: " ?! "}},
+
+		{name: "ordering correct", question: ordering, answer: UserAnswers{AnswerIDs: pq.Int64Array{2, 1, 3}}, want: 1},
+		{name: "ordering wrong without partial credit", question: ordering, answer: UserAnswers{AnswerIDs: pq.Int64Array{1, 2, 3}}},
+		{name: "ordering partial", question: partialOrdering, answer: UserAnswers{AnswerIDs: pq.Int64Array{1, 2, 3}}, want: 1.0 / 3},
+		{name: "ordering incomplete", question: partialOrdering, answer: UserAnswers{AnswerIDs: pq.Int64Array{2, 1}}},
+
+		{name: "unknown type", question: Question{Type: "essay", Answers: single.Answers}, answer: UserAnswers{AnswerID: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.question.Grade(&tt.answer); math.Abs(got-tt.want) > 1e-9 {
+				t.Fatalf("Grade = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{in: "", want: ""},
+		{in: "Париж", want: "париж"},
+		{in: "  Санкт -  Петербург ", want: "санкт петербург"},
+		{in: "Ёлка", want: "елка"},
+		{in: "\"Война и мир\".", want: "война и мир"},
+		{in: "R2-D2", want: "r2d2"},
+		{in: "!?", want: ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeText(tt.in); got != tt.want {
+			t.Errorf("normalizeText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RefundStatus string
+
+const (
+	RefundPending    RefundStatus = "pending"
+	RefundProcessing RefundStatus = "processing"
+	RefundDone       RefundStatus = "refunded"
+	RefundFailed     RefundStatus = "failed"
+)
+
+// Refund возврат игроку того, что он заплатил за участие (UserContests.Price)
+type Refund struct {
+	ContestID      int64        `json:"contest_id" gorm:"column:contest_id;primaryKey"`
+	UserID         int64        `json:"user_id" gorm:"column:user_id;primaryKey"`
+	Amount         float64      `json:"amount" gorm:"column:amount"`
+	Status         RefundStatus `json:"status" gorm:"column:status"`
+	IdempotencyKey string       `json:"idempotency_key" gorm:"column:idempotency_key"`
+	Attempts       int          `json:"attempts" gorm:"column:attempts"`
+	LastError      string       `json:"last_error,omitempty" gorm:"column:last_error"`
+	RefundedAt     *time.Time   `json:"refunded_at" gorm:"column:refunded_at"`
+	CreatedAt      *time.Time   `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      *time.Time   `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (Refund) TableName() string {
+	return "contest_refunds"
+}
+
+// CreateRefunds возвраты всем заплатившим участникам конкурса или, если userID != 0, одному из них.
+// Уже созданные возвраты не меняются, поэтому повторная отмена не вернет деньги дважды
+func (r RepoImpl) CreateRefunds(contestID, userID int64) error {
+	query := r.db.Table("user_contests").
+		Select("contest_id, user_id, price, ?, 'contest-' || contest_id || '-refund-' || user_id", RefundPending).
+		Where("contest_id = ? AND price > 0 AND payment_status = ?", contestID, PaymentPaid)
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	return r.db.Exec("INSERT INTO contest_refunds (contest_id, user_id, amount, status, idempotency_key) ? ON CONFLICT DO NOTHING", query).Error
+}
+
+func (r RepoImpl) GetContestRefunds(contestID int64) (refunds []Refund, err error) {
+	err = r.db.Where("contest_id = ?", contestID).Order("user_id").Find(&refunds).Error
+	return
+}
+
+// refundStuckAfter возврат в работе дольше этого, скорее всего, брошен упавшим инстансом
+const refundStuckAfter = 10 * time.Minute
+
+// ClaimRefund берет возврат в работу, false - его уже вернул или возвращает кто-то другой
+func (r RepoImpl) ClaimRefund(contestID, userID int64) (bool, error) {
+	res := r.db.Model(&Refund{}).
+		Where("contest_id = ? AND user_id = ?", contestID, userID).
+		Where("status IN ? OR (status = ? AND updated_at < ?)", []RefundStatus{RefundPending, RefundFailed},
+			RefundProcessing, time.Now().Add(-refundStuckAfter)).
+		Updates(map[string]interface{}{"status": RefundProcessing, "attempts": gorm.Expr("attempts + 1")})
+	return res.RowsAffected == 1, res.Error
+}
+
+// FinishRefund итог попытки возврата; после возврата место игрока отменяется, а его билет на конкурс, если он был, гасится
+func (r RepoImpl) FinishRefund(contestID, userID int64, refundErr error) error {
+	if refundErr != nil {
+		return r.db.Model(&Refund{}).Where("contest_id = ? AND user_id = ?", contestID, userID).
+			Updates(map[string]interface{}{"status": RefundFailed, "last_error": refundErr.Error()}).Error
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&Refund{}).Where("contest_id = ? AND user_id = ?", contestID, userID).
+			Updates(map[string]interface{}{"status": RefundDone, "last_error": "", "refunded_at": time.Now()}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&UserContests{}).Where("contest_id = ? AND user_id = ?", contestID, userID).
+			Update("payment_status", PaymentRefund).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&UserTickets{}).Where("user_id = ? AND contest_id = ?", userID, contestID).Update("canseled", true).Error
+	})
+}
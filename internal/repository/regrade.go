@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidRegrade = errors.New("invalid regrade")
+
+// QuestionRegrade правка проверки вопроса, после которой ответы участников пересчитываются
+type QuestionRegrade struct {
+	Voided     *bool         `json:"voided,omitempty"`             // аннулировать вопрос или вернуть его в зачет
+	CorrectIDs []int64       `json:"correct_answer_ids,omitempty"` // варианты, которые теперь верные, остальные становятся неверными
+	Accept     []string      `json:"accept,omitempty"`             // numeric и text: дополнительные допустимые ответы
+	Positions  map[int64]int `json:"positions,omitempty"`          // ordering: правильная позиция варианта по его id
+	Reason     string        `json:"reason,omitempty"`
+}
+
+func (g QuestionRegrade) Value() (driver.Value, error) {
+	data, err := json.Marshal(g)
+	return string(data), err
+}
+
+func (g *QuestionRegrade) Scan(value interface{}) error {
+	switch data := value.(type) {
+	case nil:
+		*g = QuestionRegrade{}
+		return nil
+	case []byte:
+		return json.Unmarshal(data, g)
+	case string:
+		return json.Unmarshal([]byte(data), g)
+	}
+	return fmt.Errorf("unsupported regrade value %T", value)
+}
+
+// Apply меняет проверку вопроса q; новые допустимые ответы добавляются без id
+func (g *QuestionRegrade) Apply(q *Question) error {
+	if g.Voided == nil && g.CorrectIDs == nil && len(g.Accept) == 0 && g.Positions == nil {
+		return fmt.Errorf("%w: nothing to change", ErrInvalidRegrade)
+	}
+	if g.Voided != nil {
+		q.Voided = *g.Voided
+	}
+	if g.CorrectIDs != nil {
+		if q.Kind() == QuestionOrdering {
+			return fmt.Errorf("%w: ordering question is regraded by positions", ErrInvalidRegrade)
+		}
+		correct := make(map[int64]bool, len(g.CorrectIDs))
+		for _, id := range g.CorrectIDs {
+			correct[id] = true
+		}
+		for i := range q.Answers {
+			isCorrect := correct[q.Answers[i].ID]
+			q.Answers[i].IsCorrect = &isCorrect
+			delete(correct, q.Answers[i].ID)
+		}
+		if len(correct) > 0 {
+			return fmt.Errorf("%w: answers are not options of question %d", ErrInvalidRegrade, q.ID)
+		}
+	}
+	if len(g.Accept) > 0 {
+		if !q.HidesAnswers() {
+			return fmt.Errorf("%w: accept is for numeric and text questions", ErrInvalidRegrade)
+		}
+		for _, value := range g.Accept {
+			isCorrect := true
+			q.Answers = append(q.Answers, Answer{QuestionID: q.ID, Title: strings.TrimSpace(value), IsCorrect: &isCorrect})
+		}
+	}
+	if g.Positions != nil {
+		if q.Kind() != QuestionOrdering {
+			return fmt.Errorf("%w: positions are for ordering questions", ErrInvalidRegrade)
+		}
+		positions := make(map[int64]int, len(g.Positions))
+		for id, position := range g.Positions {
+			positions[id] = position
+		}
+		for i := range q.Answers {
+			if position, ok := positions[q.Answers[i].ID]; ok {
+				q.Answers[i].Position = position
+				delete(positions, q.Answers[i].ID)
+			}
+		}
+		if len(positions) > 0 {
+			return fmt.Errorf("%w: answers are not options of question %d", ErrInvalidRegrade, q.ID)
+		}
+	}
+	if err := q.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRegrade, err)
+	}
+	return nil
+}
+
+// ContestAudit запись о правке проверки вопроса
+type ContestAudit struct {
+	ID             int64           `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	ContestID      int64           `json:"contest_id" gorm:"column:contest_id"`
+	QuestionID     int64           `json:"question_id" gorm:"column:question_id"`
+	Action         string          `json:"action" gorm:"column:action"`
+	Details        QuestionRegrade `json:"details" gorm:"column:details;type:jsonb"`
+	ChangedAnswers int64           `json:"changed_answers" gorm:"column:changed_answers"` // у скольких ответов поменялась доля верности
+	CreatedBy      string          `json:"created_by" gorm:"column:created_by"`
+	CreatedAt      *time.Time      `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (ContestAudit) TableName() string {
+	return "contest_audit"
+}
+
+// RegradeQuestion сохраняет новую проверку вопроса, пересчитанные доли ответов (по id участника) и запись аудита
+func (r RepoImpl) RegradeQuestion(question *Question, credits map[int64]float64, audit *ContestAudit) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Table("questions").Where("id = ?", question.ID).Update("voided", question.Voided).Error
+		if err != nil {
+			return err
+		}
+		for i := range question.Answers {
+			answer := &question.Answers[i]
+			if answer.ID == 0 {
+				err = tx.Create(answer).Error
+			} else {
+				err = tx.Model(&Answer{ID: answer.ID}).Select("is_correct", "position").Updates(answer).Error
+			}
+			if err != nil {
+				return err
+			}
+		}
+		for userID, credit := range credits {
+			err = tx.Model(&UserAnswers{}).
+				Where("contest_id = ? AND question_id = ? AND user_id = ?", question.ContestID, question.ID, userID).
+				Update("credit", credit).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Create(audit).Error
+	})
+}
+
+func (r RepoImpl) GetContestAudit(contestID int64) (audit []ContestAudit, err error) {
+	err = r.db.Where("contest_id = ?", contestID).Order("id").Find(&audit).Error
+	return
+}
+
+// regradeAnswers пересчитывает доли ответов на вопрос по его сохраненной в tx проверке
+func regradeAnswers(tx *gorm.DB, questionID int64) error {
+	var question Question
+	if err := tx.Preload("Answers").First(&question, questionID).Error; err != nil {
+		return err
+	}
+	var userAnswers []UserAnswers
+	if err := tx.Where("question_id = ?", questionID).Find(&userAnswers).Error; err != nil {
+		return err
+	}
+	for i := range userAnswers {
+		credit := question.Grade(&userAnswers[i])
+		if credit == userAnswers[i].Credit {
+			continue
+		}
+		err := tx.Model(&UserAnswers{}).
+			Where("contest_id = ? AND question_id = ? AND user_id = ?", userAnswers[i].ContestID, questionID, userAnswers[i].UserID).
+			Update("credit", credit).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
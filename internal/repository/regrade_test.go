@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestQuestionRegradeApply(t *testing.T) {
+	void, restore := true, false
+	single := func() Question {
+		return Question{ID: 5, Answers: []Answer{testAnswer(1, "3", false, 0), testAnswer(2, "4", true, 0)}}
+	}
+	text := func() Question {
+		return Question{ID: 5, Type: QuestionText, Answers: []Answer{testAnswer(1, "Москва", true, 0)}}
+	}
+	ordering := func() Question {
+		return Question{ID: 5, Type: QuestionOrdering, Answers: []Answer{
+			testAnswer(1, "один", false, 1), testAnswer(2, "десять", false, 2), testAnswer(3, "сто", false, 3),
+		}}
+	}
+	tests := []struct {
+		name     string
+		question Question
+		regrade  QuestionRegrade
+		wantErr  bool
+		check    func(t *testing.T, q *Question)
+	}{
+		{name: "nothing to change", question: single(), wantErr: true},
+		{name: "reason only", question: single(), regrade: QuestionRegrade{Reason: "опечатка"}, wantErr: true},
+		{
+			name: "void", question: single(), regrade: QuestionRegrade{Voided: &void},
+			check: func(t *testing.T, q *Question) {
+				if !q.Voided {
+					t.Fatal("question is not voided")
+				}
+			},
+		},
+		{
+			name: "restore", question: Question{ID: 5, Voided: true, Answers: single().Answers}, regrade: QuestionRegrade{Voided: &restore},
+			check: func(t *testing.T, q *Question) {
+				if q.Voided {
+					t.Fatal("question is still voided")
+				}
+			},
+		},
+		{
+			name: "correct ids replace correctness", question: single(), regrade: QuestionRegrade{CorrectIDs: []int64{1}},
+			check: func(t *testing.T, q *Question) {
+				if !q.Answers[0].Correct() || q.Answers[1].Correct() {
+					t.Fatalf("correctness = %v, %v, want true, false", q.Answers[0].Correct(), q.Answers[1].Correct())
+				}
+			},
+		},
+		{name: "correct ids with unknown answer", question: single(), regrade: QuestionRegrade{CorrectIDs: []int64{1, 9}}, wantErr: true},
+		{name: "empty correct ids leave no correct answer", question: single(), regrade: QuestionRegrade{CorrectIDs: []int64{}}, wantErr: true},
+		{name: "correct ids on ordering", question: ordering(), regrade: QuestionRegrade{CorrectIDs: []int64{1}}, wantErr: true},
+		{
+			name: "accept on text", question: text(), regrade: QuestionRegrade{Accept: []string{" Мск "}},
+			check: func(t *testing.T, q *Question) {
+				if len(q.Answers) != 2 {
+					t.Fatalf("answers = %d, want 2", len(q.Answers))
+				}
+				added := q.Answers[1]
+				if added.ID != 0 || added.QuestionID != 5 || added.Title != "Мск" || !added.Correct() {
+					t.Fatalf("added answer = %+v", added)
+				}
+			},
+		},
+		{name: "accept not a number", question: Question{ID: 5, Type: QuestionNumeric, Answers: []Answer{testAnswer(1, "3.14", true, 0)}}, regrade: QuestionRegrade{Accept: []string{"пи"}}, wantErr: true},
+		{name: "accept on single", question: single(), regrade: QuestionRegrade{Accept: []string{"5"}}, wantErr: true},
+		{
+			name: "positions", question: ordering(), regrade: QuestionRegrade{Positions: map[int64]int{1: 3, 3: 1}},
+			check: func(t *testing.T, q *Question) {
+				for i, want := range []int{3, 2, 1} {
+					if q.Answers[i].Position != want {
+						t.Fatalf("answer %d position = %d, want %d", q.Answers[i].ID, q.Answers[i].Position, want)
+					}
+				}
+			},
+		},
+		{name: "positions not a permutation", question: ordering(), regrade: QuestionRegrade{Positions: map[int64]int{1: 2}}, wantErr: true},
+		{name: "positions with unknown answer", question: ordering(), regrade: QuestionRegrade{Positions: map[int64]int{9: 1}}, wantErr: true},
+		{name: "positions on single", question: single(), regrade: QuestionRegrade{Positions: map[int64]int{1: 1}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.regrade.Apply(&tt.question)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidRegrade) {
+					t.Fatalf("Apply = %v, want ErrInvalidRegrade", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, &tt.question)
+		})
+	}
+}
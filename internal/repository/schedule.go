@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/dwnGnL/pg-contests/lib/cron"
+	"gorm.io/gorm"
+)
+
+// ContestSchedule регулярный выпуск конкурсов по cron-выражению: по шаблону TemplateID
+// или, без шаблона, по собственным TitlePattern, Price и слотам банка Bank
+type ContestSchedule struct {
+	ID            int64         `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	Name          string        `json:"name" binding:"required" gorm:"column:name"`
+	Cron          string        `json:"cron" binding:"required" gorm:"column:cron"` // "0 20 * * *" - каждый день в 20:00
+	Timezone      string        `json:"timezone,omitempty" gorm:"column:timezone"`  // зона, в которой считается Cron
+	TemplateID    *int64        `json:"template_id,omitempty" gorm:"column:template_id"`
+	TitlePattern  string        `json:"title_pattern,omitempty" gorm:"column:title_pattern"`
+	Price         float64       `json:"price" gorm:"column:price"`
+	Bank          TemplateSlots `json:"bank,omitempty" gorm:"column:bank;type:jsonb"`
+	Publish       bool          `json:"publish" gorm:"column:publish"` // сразу планировать, иначе черновик на проверку
+	Paused        bool          `json:"paused" gorm:"column:paused"`
+	Issued        int64         `json:"issued" gorm:"column:issued"`                   // выпусков без шаблона, для {n}
+	LastStartTime *time.Time    `json:"last_start_time" gorm:"column:last_start_time"` // старт последнего созданного конкурса
+	LastError     string        `json:"last_error,omitempty" gorm:"column:last_error"`
+	NextRuns      []time.Time   `json:"next_runs,omitempty" gorm:"-"`
+	CreatedBy     string        `json:"created_by" gorm:"column:created_by"`
+	CreatedAt     *time.Time    `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     *time.Time    `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+var ErrTemplateInUse = errors.New("template is used by a schedule")
+
+func (s *ContestSchedule) Validate() error {
+	if _, err := cron.Parse(s.Cron); err != nil {
+		return err
+	}
+	if _, err := time.LoadLocation(s.Timezone); err != nil {
+		return err
+	}
+	if s.TemplateID != nil {
+		return nil
+	}
+	template := s.Template()
+	return template.Validate()
+}
+
+// Template шаблон расписания без TemplateID
+func (s *ContestSchedule) Template() ContestTemplate {
+	return ContestTemplate{
+		TitlePattern: s.TitlePattern,
+		Price:        s.Price,
+		Timezone:     s.Timezone,
+		Slots:        s.Bank,
+		Issued:       s.Issued,
+	}
+}
+
+// Runs ближайшие count запусков после after
+func (s *ContestSchedule) Runs(after time.Time, count int) []time.Time {
+	expr, err := cron.Parse(s.Cron)
+	if err != nil {
+		return nil
+	}
+	after = after.In(displayLocation(s.Timezone))
+	var runs []time.Time
+	for len(runs) < count {
+		after = expr.Next(after)
+		if after.IsZero() {
+			break
+		}
+		runs = append(runs, after)
+	}
+	return runs
+}
+
+func (s *ContestSchedule) BeforeCreate(tx *gorm.DB) (err error) {
+	s.Cron = strings.TrimSpace(s.Cron)
+	return s.Validate()
+}
+
+func (r RepoImpl) CreateSchedule(schedule *ContestSchedule) error {
+	return r.db.Create(schedule).Error
+}
+
+func (r RepoImpl) GetSchedule(scheduleID int64) (schedule *ContestSchedule, err error) {
+	err = r.db.First(&schedule, scheduleID).Error
+	return
+}
+
+func (r RepoImpl) GetSchedules(pagination *Pagination) (*Pagination, error) {
+	var totalRows int64
+	if err := r.db.Model(&ContestSchedule{}).Count(&totalRows).Error; err != nil {
+		return nil, err
+	}
+
+	schedules := new([]ContestSchedule)
+	if err := r.db.Scopes(Paginate(pagination)).Find(schedules).Error; err != nil {
+		return nil, err
+	}
+	pagination.Records = schedules
+	pagination.TotalRows = totalRows
+	pagination.TotalPages = int(totalRows / int64(pagination.Limit))
+	if totalRows%int64(pagination.Limit) > 0 {
+		pagination.TotalPages++
+	}
+	return pagination, nil
+}
+
+func (r RepoImpl) GetActiveSchedules() (schedules []ContestSchedule, err error) {
+	err = r.db.Where("paused = false").Find(&schedules).Error
+	return
+}
+
+// UpdateSchedule счетчик выпусков и состояние выпуска меняет только CreateScheduledContest
+func (r RepoImpl) UpdateSchedule(schedule ContestSchedule) (*ContestSchedule, error) {
+	schedule.Cron = strings.TrimSpace(schedule.Cron)
+	if err := schedule.Validate(); err != nil {
+		return nil, err
+	}
+	result := r.db.Model(&ContestSchedule{ID: schedule.ID}).
+		Select("name", "cron", "timezone", "template_id", "title_pattern", "price", "bank", "publish", "paused", "updated_at").
+		Updates(&schedule)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.GetSchedule(schedule.ID)
+}
+
+func (r RepoImpl) SetSchedulePaused(scheduleID int64, paused bool) error {
+	result := r.db.Model(&ContestSchedule{ID: scheduleID}).Updates(map[string]interface{}{"paused": paused, "updated_at": time.Now()})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r RepoImpl) SetScheduleError(scheduleID int64, message string) error {
+	return r.db.Model(&ContestSchedule{ID: scheduleID}).UpdateColumn("last_error", message).Error
+}
+
+// DeleteSchedule созданные по расписанию конкурсы остаются
+func (r RepoImpl) DeleteSchedule(scheduleID int64) error {
+	result := r.db.Delete(&ContestSchedule{ID: scheduleID})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// scheduleLockSpace первый ключ pg_advisory_xact_lock для расписаний, второй - id расписания
+const scheduleLockSpace = 0x5c4e
+
+// CreateScheduledContest создает конкурс расписания, если на это время его еще нет, и сдвигает LastStartTime.
+// Несколько экземпляров сервиса могут выпускать одно расписание одновременно, поэтому все в одной транзакции под блокировкой;
+// nil без ошибки - конкурс уже создан другим экземпляром
+func (r RepoImpl) CreateScheduledContest(contest Contest) (*Contest, error) {
+	if contest.ScheduleID == nil {
+		return nil, errors.New("contest has no schedule")
+	}
+	scheduleID := *contest.ScheduleID
+	created := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?, ?)", scheduleLockSpace, int32(scheduleID)).Error; err != nil {
+			return err
+		}
+		var count int64
+		err := tx.Model(&Contest{}).Where("schedule_id = ? AND start_time = ?", scheduleID, contest.StartTime.UTC()).Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			if err = tx.Create(&contest).Error; err != nil {
+				return err
+			}
+			created = true
+			//номер выпуска ведет шаблон, а если его нет - само расписание
+			if contest.TemplateID != nil {
+				err = tx.Model(&ContestTemplate{ID: *contest.TemplateID}).UpdateColumn("issued", gorm.Expr("issued + 1")).Error
+			} else {
+				err = tx.Model(&ContestSchedule{ID: scheduleID}).UpdateColumn("issued", gorm.Expr("issued + 1")).Error
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Model(&ContestSchedule{ID: scheduleID}).
+			Where("last_start_time IS NULL OR last_start_time < ?", contest.StartTime).
+			UpdateColumn("last_start_time", contest.StartTime).Error
+	})
+	if err != nil || !created {
+		return nil, err
+	}
+	return &contest, nil
+}
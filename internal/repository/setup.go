@@ -5,7 +5,6 @@ import (
 	"github.com/dwnGnL/pg-contests/lib/dbconn"
 
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
 func NewRepository(cfg *config.Config) (*RepoImpl, error) {
@@ -19,30 +18,6 @@ func NewRepository(cfg *config.Config) (*RepoImpl, error) {
 	}, nil
 }
 
-func (r RepoImpl) Migrate() error {
-	for _, model := range []interface{}{
-		(*Contest)(nil),
-		(*Question)(nil),
-		(*Answer)(nil),
-		(*Photo)(nil),
-		(*UserTickets)(nil),
-		(*UserContests)(nil),
-		(*UserAnswers)(nil),
-	} {
-		dbSilent := r.db.Session(&gorm.Session{Logger: logger.Default.LogMode(logger.Silent)})
-
-		err := dbSilent.Migrator().DropTable(model)
-		if err != nil {
-			return err
-		}
-		err = dbSilent.AutoMigrate(model)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
 type RepoImpl struct {
 	db *gorm.DB
 }
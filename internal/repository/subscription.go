@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrAlreadySubscribed = errors.New("already subscribed earlier")
+
+type PaymentStatus string
+
+const (
+	PaymentPending PaymentStatus = "pending" // место занято, списание еще не подтверждено
+	PaymentPaid    PaymentStatus = "paid"
+	PaymentRefund  PaymentStatus = "refunded" // оплата возвращена, места у игрока больше нет
+)
+
+// ConfirmSubscription списание прошло; false - подписки с этим ключом уже нет или она уже подтверждена
+func (r RepoImpl) ConfirmSubscription(contestID, userID int64, paymentKey string) (bool, error) {
+	res := r.db.Model(&UserContests{}).
+		Where("contest_id = ? AND user_id = ? AND payment_key = ? AND payment_status = ?", contestID, userID, paymentKey, PaymentPending).
+		Update("payment_status", PaymentPaid)
+	return res.RowsAffected == 1, res.Error
+}
+
+// CancelSubscription компенсация несостоявшегося списания: место и использование промокода освобождаются, созданная участником команда
+// без других участников удаляется, иначе капитаном становится следующий вступивший
+func (r RepoImpl) CancelSubscription(contestID, userID int64, paymentKey string) (bool, error) {
+	canceled := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var userContests []UserContests
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("contest_id = ? AND user_id = ? AND payment_key = ? AND payment_status = ?", contestID, userID, paymentKey, PaymentPending).
+			Find(&userContests).Error
+		if err != nil || len(userContests) == 0 {
+			return err
+		}
+		err = tx.Where("contest_id = ? AND user_id = ?", contestID, userID).Delete(&UserContests{}).Error
+		if err != nil {
+			return err
+		}
+		canceled = true
+		if code := userContests[0].PromoCode; code != "" {
+			if err = releasePromo(tx, code); err != nil {
+				return err
+			}
+		}
+		teamID := userContests[0].TeamID
+		if teamID == nil {
+			return nil
+		}
+		var next []UserContests
+		err = tx.Where("contest_id = ? AND team_id = ?", contestID, *teamID).Order("created_at").Limit(1).Find(&next).Error
+		if err != nil {
+			return err
+		}
+		if len(next) == 0 {
+			return tx.Delete(&Team{}, *teamID).Error
+		}
+		return tx.Model(&Team{}).Where("id = ? AND captain_id = ?", *teamID, userID).Update("captain_id", next[0].UserID).Error
+	})
+	return canceled, err
+}
+
+// GetPendingSubscriptions подписки, списание по которым не подтверждено с before
+func (r RepoImpl) GetPendingSubscriptions(before time.Time) (userContests []UserContests, err error) {
+	err = r.db.Where("payment_status = ? AND created_at < ?", PaymentPending, before).Order("created_at").Find(&userContests).Error
+	return
+}
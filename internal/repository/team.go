@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrTeamNotFound  = errors.New("team not found in this contest")
+	ErrTeamFull      = errors.New("team is full")
+	ErrTeamNameTaken = errors.New("team name is already taken")
+)
+
+// Team команда командного конкурса, ее создает первый участник и становится капитаном
+type Team struct {
+	ID        int64          `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	ContestID int64          `json:"contest_id" gorm:"column:contest_id"`
+	Name      string         `json:"name" gorm:"column:name"`
+	CaptainID int64          `json:"captain_id" gorm:"column:captain_id"`
+	Members   []UserContests `json:"members,omitempty" gorm:"foreignKey:TeamID"`
+	CreatedAt *time.Time     `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (Team) TableName() string {
+	return "contest_teams"
+}
+
+// MemberIDs участники команды
+func (t *Team) MemberIDs() []int64 {
+	ids := make([]int64, 0, len(t.Members))
+	for _, member := range t.Members {
+		ids = append(ids, member.UserID)
+	}
+	return ids
+}
+
+// preloadTeamMembers о сокомандниках наружу отдаем только id и имя
+func preloadTeamMembers(db *gorm.DB) *gorm.DB {
+	return db.Preload("Members", func(db *gorm.DB) *gorm.DB {
+		return db.Select("user_id", "contest_id", "team_id", "user_name").
+			Where("payment_status = ?", PaymentPaid).Order("created_at")
+	})
+}
+
+func (r RepoImpl) GetTeam(teamID int64) (team *Team, err error) {
+	err = r.db.Scopes(preloadTeamMembers).First(&team, teamID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = ErrTeamNotFound
+	}
+	return
+}
+
+func (r RepoImpl) GetContestTeams(contestID int64) (teams []Team, err error) {
+	err = r.db.Scopes(preloadTeamMembers).Where("contest_id = ?", contestID).Order("id").Find(&teams).Error
+	return
+}
+
+// joinTeam в командном конкурсе участник вступает в команду TeamID или создает команду TeamName.
+// Строка команды блокируется, чтобы одновременные вступления не превысили team_size
+func joinTeam(tx *gorm.DB, userContest *UserContests) error {
+	if name := strings.TrimSpace(userContest.TeamName); name != "" {
+		var taken int64
+		err := tx.Model(&Team{}).Where("contest_id = ? AND lower(name) = lower(?)", userContest.ContestID, name).
+			Count(&taken).Error
+		if err != nil {
+			return err
+		}
+		if taken > 0 {
+			return ErrTeamNameTaken
+		}
+		team := Team{ContestID: userContest.ContestID, Name: name, CaptainID: userContest.UserID}
+		if err = tx.Create(&team).Error; err != nil {
+			return err
+		}
+		userContest.TeamID = &team.ID
+		return nil
+	}
+
+	var team Team
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND contest_id = ?", *userContest.TeamID, userContest.ContestID).Take(&team).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrTeamNotFound
+	}
+	if err != nil {
+		return err
+	}
+	var teamSize, members int64
+	err = tx.Table("contests").Select("team_size").Where("id = ?", userContest.ContestID).Scan(&teamSize).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Model(&UserContests{}).Where("contest_id = ? AND team_id = ?", userContest.ContestID, team.ID).
+		Count(&members).Error
+	if err != nil {
+		return err
+	}
+	if teamSize > 0 && members >= teamSize {
+		return ErrTeamFull
+	}
+	return nil
+}
+
+// SubmitTeamAnswer у команды один ответ на вопрос: ответы остальных участников команды на этот вопрос удаляются
+func (r RepoImpl) SubmitTeamAnswer(userAnswer *UserAnswers, teamID int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		//одновременные ответы двух сокомандников не должны оставить два ответа
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", teamID).Take(&Team{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("contest_id = ? AND question_id = ? AND user_id <> ? AND user_id IN (?)",
+			userAnswer.ContestID, userAnswer.QuestionID, userAnswer.UserID,
+			tx.Model(&UserContests{}).Select("user_id").Where("contest_id = ? AND team_id = ?", userAnswer.ContestID, teamID)).
+			Delete(&UserAnswers{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(userAnswer).Error
+	})
+}
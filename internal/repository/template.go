@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dwnGnL/pg-contests/internal/scoring"
+	"gorm.io/gorm"
+)
+
+// ContestTemplate заготовка регулярного конкурса: вопросы каждый раз свежие из банка по Slots
+type ContestTemplate struct {
+	ID             int64          `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	Name           string         `json:"name" binding:"required" gorm:"column:name"`
+	TitlePattern   string         `json:"title_pattern" binding:"required" gorm:"column:title_pattern"` // {n} - номер выпуска, {date} - дата старта
+	Price          float64        `json:"price" gorm:"column:price"`
+	Timezone       string         `json:"timezone,omitempty" gorm:"column:timezone"`
+	Scoring        scoring.Policy `json:"scoring" gorm:"embedded;embeddedPrefix:scoring_"`
+	ShuffleAnswers bool           `json:"shuffle_answers" gorm:"column:shuffle_answers"`
+	Slots          TemplateSlots  `json:"slots" gorm:"column:slots;type:jsonb"`
+	Issued         int64          `json:"issued" gorm:"column:issued"` // сколько конкурсов создано по шаблону
+	CreatedBy      string         `json:"created_by" gorm:"column:created_by"`
+	CreatedAt      *time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      *time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TemplateSlots слоты вопросов шаблона в порядке конкурса, хранятся в jsonb
+type TemplateSlots []BankDraw
+
+func (s TemplateSlots) Value() (driver.Value, error) {
+	if s == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(s)
+	return string(data), err
+}
+
+func (s *TemplateSlots) Scan(value interface{}) error {
+	switch data := value.(type) {
+	case nil:
+		*s = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(data, s)
+	case string:
+		return json.Unmarshal([]byte(data), s)
+	}
+	return fmt.Errorf("unsupported slots value %T", value)
+}
+
+func (t *ContestTemplate) Validate() error {
+	if strings.TrimSpace(t.TitlePattern) == "" {
+		return errors.New("title_pattern is required")
+	}
+	if _, err := time.LoadLocation(t.Timezone); err != nil {
+		return err
+	}
+	if err := t.Scoring.Validate(); err != nil {
+		return err
+	}
+	if len(t.Slots) == 0 {
+		return errors.New("template without question slots")
+	}
+	for i, slot := range t.Slots {
+		if len(slot.IDs) == 0 && slot.Count <= 0 {
+			return fmt.Errorf("slot #%d: ids or count is required", i)
+		}
+		if slot.Difficulty != 0 && (slot.Difficulty < MinDifficulty || slot.Difficulty > MaxDifficulty) {
+			return fmt.Errorf("slot #%d: difficulty must be from %d to %d", i, MinDifficulty, MaxDifficulty)
+		}
+	}
+	return nil
+}
+
+// Title название выпуска issue, который начнется в startTime
+func (t *ContestTemplate) Title(issue int64, startTime time.Time) string {
+	date := startTime.In(displayLocation(t.Timezone)).Format("02.01.2006")
+	return strings.NewReplacer("{n}", strconv.FormatInt(issue, 10), "{date}", date).Replace(t.TitlePattern)
+}
+
+// Contest черновик следующего выпуска; вопросы набираются из банка по Bank при создании
+func (t *ContestTemplate) Contest(startTime time.Time) Contest {
+	templateID := t.ID
+	return Contest{
+		Title:          t.Title(t.Issued+1, startTime),
+		Price:          t.Price,
+		StartTime:      startTime,
+		Timezone:       t.Timezone,
+		Scoring:        t.Scoring,
+		ShuffleAnswers: t.ShuffleAnswers,
+		Bank:           append([]BankDraw(nil), t.Slots...),
+		TemplateID:     &templateID,
+		Status:         StatusDraft,
+	}
+}
+
+func (t *ContestTemplate) BeforeCreate(tx *gorm.DB) (err error) {
+	return t.Validate()
+}
+
+func (r RepoImpl) CreateTemplate(template *ContestTemplate) error {
+	return r.db.Create(template).Error
+}
+
+func (r RepoImpl) GetTemplate(templateID int64) (template *ContestTemplate, err error) {
+	err = r.db.First(&template, templateID).Error
+	return
+}
+
+func (r RepoImpl) GetTemplates(pagination *Pagination) (*Pagination, error) {
+	var totalRows int64
+	if err := r.db.Model(&ContestTemplate{}).Count(&totalRows).Error; err != nil {
+		return nil, err
+	}
+
+	templates := new([]ContestTemplate)
+	if err := r.db.Scopes(Paginate(pagination)).Find(templates).Error; err != nil {
+		return nil, err
+	}
+	pagination.Records = templates
+	pagination.TotalRows = totalRows
+	pagination.TotalPages = int(totalRows / int64(pagination.Limit))
+	if totalRows%int64(pagination.Limit) > 0 {
+		pagination.TotalPages++
+	}
+	return pagination, nil
+}
+
+// UpdateTemplate счетчик выпусков не меняется, его ведет IncrementTemplateIssued
+func (r RepoImpl) UpdateTemplate(template ContestTemplate) (*ContestTemplate, error) {
+	if err := template.Validate(); err != nil {
+		return nil, err
+	}
+	result := r.db.Model(&ContestTemplate{ID: template.ID}).
+		Select("name", "title_pattern", "price", "timezone", "scoring_decay", "scoring_half_life", "scoring_min_fraction",
+			"scoring_wrong_penalty", "scoring_streak_bonus", "scoring_streak_cap", "shuffle_answers", "slots", "updated_at").
+		Updates(&template)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.GetTemplate(template.ID)
+}
+
+func (r RepoImpl) DeleteTemplate(templateID int64) error {
+	var schedules int64
+	if err := r.db.Model(&ContestSchedule{}).Where("template_id = ?", templateID).Count(&schedules).Error; err != nil {
+		return err
+	}
+	if schedules > 0 {
+		return ErrTemplateInUse
+	}
+	result := r.db.Delete(&ContestTemplate{ID: templateID})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r RepoImpl) IncrementTemplateIssued(templateID int64) error {
+	return r.db.Model(&ContestTemplate{ID: templateID}).
+		UpdateColumn("issued", gorm.Expr("issued + 1")).Error
+}
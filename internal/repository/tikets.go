@@ -1,10 +1,57 @@
 package repository
 
-func (r RepoImpl) GetUserTikets(userID, tiketID int64) (*UserTickets, error) {
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrTicketUnavailable = errors.New("ticket is already redeemed or cancelled")
+
+// GetUserTikets неиспользованный билет участника на конкурс; ID == 0 - билета нет
+func (r RepoImpl) GetUserTikets(userID, contestID int64) (*UserTickets, error) {
 	tikets := new(UserTickets)
-	err := r.db.Where("user_id = ? and contest_id = ?", userID, tiketID).Find(tikets).Error
+	err := r.db.Where("user_id = ? and contest_id = ? and not canseled and redeemed_at is null", userID, contestID).
+		Order("id").Limit(1).Find(tikets).Error
 	if err != nil {
 		return nil, err
 	}
 	return tikets, nil
 }
+
+// IssueTickets id созданных билетов проставляются в tickets
+func (r RepoImpl) IssueTickets(tickets []UserTickets) error {
+	return r.db.Create(&tickets).Error
+}
+
+func (r RepoImpl) GetContestTickets(contestID int64) (tickets []UserTickets, err error) {
+	err = r.db.Where("contest_id = ?", contestID).Order("id").Find(&tickets).Error
+	return
+}
+
+// CancelTicket отменить можно только еще не использованный билет
+func (r RepoImpl) CancelTicket(ticketID int64) (*UserTickets, error) {
+	var ticket UserTickets
+	if err := r.db.First(&ticket, ticketID).Error; err != nil {
+		return nil, err
+	}
+	res := r.db.Model(&ticket).Where("not canseled and redeemed_at is null").Update("canseled", true)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrTicketUnavailable
+	}
+	return &ticket, nil
+}
+
+// redeemTicket билет погашается в транзакции подписки, одновременно его может использовать только одна подписка
+func redeemTicket(tx *gorm.DB, ticketID int64) error {
+	res := tx.Model(&UserTickets{}).Where("id = ? and not canseled and redeemed_at is null", ticketID).
+		Update("redeemed_at", time.Now())
+	if res.Error == nil && res.RowsAffected == 0 {
+		return ErrTicketUnavailable
+	}
+	return res.Error
+}
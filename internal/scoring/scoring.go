@@ -0,0 +1,151 @@
+// Package scoring считает баллы за ответы по правилам конкурса. Пакет без зависимостей от базы,
+// одни и те же правила применяются в живой статистике, полной статистике участника и итоговых результатах
+package scoring
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+type Decay string
+
+const (
+	DecayNone        Decay = ""
+	DecayLinear      Decay = "linear"      // от полного балла в начале вопроса до 0 к концу времени
+	DecayExponential Decay = "exponential" // балл падает вдвое каждые HalfLife от времени вопроса
+)
+
+const defaultHalfLife = 0.5
+
+// Policy правила подсчета баллов конкурса. Нулевое значение - полный Score за верный ответ, как было раньше
+type Policy struct {
+	Decay        Decay   `json:"decay" gorm:"column:decay"`
+	HalfLife     float64 `json:"half_life,omitempty" gorm:"column:half_life"`         // доля времени вопроса (0..1], по умолчанию 0.5
+	MinFraction  float64 `json:"min_fraction,omitempty" gorm:"column:min_fraction"`   // минимальная доля балла за верный ответ после затухания
+	WrongPenalty float64 `json:"wrong_penalty,omitempty" gorm:"column:wrong_penalty"` // доля Score, которая вычитается за неверный ответ
+	StreakBonus  float64 `json:"streak_bonus,omitempty" gorm:"column:streak_bonus"`   // доля Score за каждый верный ответ подряд начиная со второго
+	StreakCap    int     `json:"streak_cap,omitempty" gorm:"column:streak_cap"`       // максимум шагов серии, 0 - без ограничения
+}
+
+// Answer ответ участника на один вопрос
+type Answer struct {
+	Score    int     // максимальный балл вопроса
+	Limit    int64   // время на вопрос, сек
+	Elapsed  int64   // через сколько секунд после начала вопроса ответили
+	Credit   float64 // доля верности 0..1 по правилам типа вопроса
+	Answered bool
+	Voided   bool // аннулированный вопрос не дает баллов никому и не прерывает серию
+}
+
+// Result итог участника
+type Result struct {
+	Score   float64
+	Correct int64
+	Time    int64 // суммарное время верных ответов, для разрешения ничьих
+	Points  []float64
+}
+
+func (p Policy) Validate() error {
+	switch p.Decay {
+	case DecayNone, DecayLinear, DecayExponential:
+	default:
+		return errors.New("unknown scoring decay " + string(p.Decay))
+	}
+	//0 - не задано, берется defaultHalfLife
+	if p.HalfLife < 0 || p.HalfLife > 1 {
+		return errors.New("scoring half_life must be in (0, 1] or 0 for the default")
+	}
+	if p.MinFraction < 0 || p.MinFraction > 1 {
+		return errors.New("scoring min_fraction must be in [0, 1]")
+	}
+	if p.WrongPenalty < 0 || p.StreakBonus < 0 || p.StreakCap < 0 {
+		return errors.New("scoring penalty, streak bonus and streak cap must not be negative")
+	}
+	return nil
+}
+
+// Points баллы за один ответ; streak - сколько верных ответов подряд заканчиваются этим (включая его)
+func (p Policy) Points(a Answer, streak int) float64 {
+	if !a.Answered {
+		return 0
+	}
+	score := float64(a.Score)
+	if a.Credit <= 0 {
+		return -score * p.WrongPenalty
+	}
+	points := score * a.Credit * p.timeFactor(a.Elapsed, a.Limit)
+	if a.Credit >= 1 && streak > 1 {
+		steps := streak - 1
+		if p.StreakCap > 0 && steps > p.StreakCap {
+			steps = p.StreakCap
+		}
+		points += score * p.StreakBonus * float64(steps)
+	}
+	return points
+}
+
+// Total итог по ответам в порядке вопросов конкурса; неотвеченный или неверный вопрос прерывает серию
+func (p Policy) Total(answers []Answer) Result {
+	result := Result{Points: make([]float64, len(answers))}
+	streak := 0
+	for i, a := range answers {
+		if a.Voided {
+			continue
+		}
+		if a.Answered && a.Credit >= 1 {
+			streak++
+		} else {
+			streak = 0
+		}
+		points := p.Points(a, streak)
+		result.Points[i] = points
+		result.Score += points
+		if a.Answered && a.Credit >= 1 {
+			result.Correct++
+		}
+		if a.Answered && a.Credit > 0 {
+			result.Time += a.Elapsed
+		}
+	}
+	return result
+}
+
+func (p Policy) timeFactor(elapsed, limit int64) float64 {
+	if limit <= 0 || p.Decay == DecayNone {
+		return 1
+	}
+	progress := math.Min(math.Max(float64(elapsed)/float64(limit), 0), 1)
+	var factor float64
+	switch p.Decay {
+	case DecayLinear:
+		factor = 1 - progress
+	case DecayExponential:
+		halfLife := p.HalfLife
+		if halfLife == 0 {
+			halfLife = defaultHalfLife
+		}
+		factor = math.Pow(0.5, progress/halfLife)
+	}
+	return math.Max(factor, p.MinFraction)
+}
+
+// Entry участник таблицы результатов
+type Entry struct {
+	ID     int64
+	Result Result
+}
+
+// Rank сортирует по баллам, при равенстве - по меньшему времени, затем по ID для стабильного порядка
+func Rank(entries []Entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i].Result, entries[j].Result
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Time != b.Time {
+			return a.Time < b.Time
+		}
+		return entries[i].ID < entries[j].ID
+	})
+}
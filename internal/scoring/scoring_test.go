@@ -0,0 +1,174 @@
+package scoring
+
+import (
+	"math"
+	"testing"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestPoints(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy Policy
+		answer Answer
+		streak int
+		want   float64
+	}{
+		{
+			name:   "default policy gives full score",
+			answer: Answer{Score: 10, Limit: 20, Elapsed: 15, Credit: 1, Answered: true},
+			streak: 1,
+			want:   10,
+		},
+		{
+			name:   "not answered",
+			policy: Policy{WrongPenalty: 0.5},
+			answer: Answer{Score: 10, Limit: 20},
+			want:   0,
+		},
+		{
+			name:   "partial credit",
+			answer: Answer{Score: 10, Limit: 20, Credit: 0.5, Answered: true},
+			want:   5,
+		},
+		{
+			name:   "linear decay",
+			policy: Policy{Decay: DecayLinear},
+			answer: Answer{Score: 10, Limit: 20, Elapsed: 5, Credit: 1, Answered: true},
+			streak: 1,
+			want:   7.5,
+		},
+		{
+			name:   "linear decay after time limit",
+			policy: Policy{Decay: DecayLinear},
+			answer: Answer{Score: 10, Limit: 20, Elapsed: 25, Credit: 1, Answered: true},
+			streak: 1,
+			want:   0,
+		},
+		{
+			name:   "exponential decay default half life",
+			policy: Policy{Decay: DecayExponential},
+			answer: Answer{Score: 8, Limit: 20, Elapsed: 10, Credit: 1, Answered: true},
+			streak: 1,
+			want:   4,
+		},
+		{
+			name:   "exponential decay custom half life",
+			policy: Policy{Decay: DecayExponential, HalfLife: 0.25},
+			answer: Answer{Score: 8, Limit: 20, Elapsed: 10, Credit: 1, Answered: true},
+			streak: 1,
+			want:   2,
+		},
+		{
+			name:   "floor applies to decay",
+			policy: Policy{Decay: DecayLinear, MinFraction: 0.3},
+			answer: Answer{Score: 10, Limit: 20, Elapsed: 19, Credit: 1, Answered: true},
+			streak: 1,
+			want:   3,
+		},
+		{
+			name:   "wrong answer penalty",
+			policy: Policy{WrongPenalty: 0.25},
+			answer: Answer{Score: 8, Limit: 20, Elapsed: 3, Answered: true},
+			want:   -2,
+		},
+		{
+			name:   "streak bonus",
+			policy: Policy{StreakBonus: 0.1},
+			answer: Answer{Score: 10, Limit: 20, Credit: 1, Answered: true},
+			streak: 3,
+			want:   12,
+		},
+		{
+			name:   "streak bonus capped",
+			policy: Policy{StreakBonus: 0.1, StreakCap: 1},
+			answer: Answer{Score: 10, Limit: 20, Credit: 1, Answered: true},
+			streak: 5,
+			want:   11,
+		},
+		{
+			name:   "no streak bonus for partial credit",
+			policy: Policy{StreakBonus: 0.1},
+			answer: Answer{Score: 10, Limit: 20, Credit: 0.5, Answered: true},
+			streak: 3,
+			want:   5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Points(tt.answer, tt.streak); !almostEqual(got, tt.want) {
+				t.Errorf("Points() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTotal(t *testing.T) {
+	policy := Policy{StreakBonus: 0.5, WrongPenalty: 1}
+	answers := []Answer{
+		{Score: 10, Limit: 10, Elapsed: 2, Credit: 1, Answered: true},
+		{Score: 10, Limit: 10, Elapsed: 3, Credit: 1, Answered: true},
+		{Score: 10, Limit: 10, Elapsed: 1, Credit: 1, Answered: true, Voided: true},
+		{Score: 10, Limit: 10, Elapsed: 4, Answered: true},
+		{Score: 10, Limit: 10},
+		{Score: 10, Limit: 10, Elapsed: 5, Credit: 1, Answered: true},
+	}
+	got := policy.Total(answers)
+
+	wantPoints := []float64{10, 15, 0, -10, 0, 10}
+	for i, want := range wantPoints {
+		if !almostEqual(got.Points[i], want) {
+			t.Errorf("Points[%d] = %v, want %v", i, got.Points[i], want)
+		}
+	}
+	if !almostEqual(got.Score, 25) {
+		t.Errorf("Score = %v, want 25", got.Score)
+	}
+	if got.Correct != 3 {
+		t.Errorf("Correct = %v, want 3", got.Correct)
+	}
+	if got.Time != 10 {
+		t.Errorf("Time = %v, want 10", got.Time)
+	}
+}
+
+func TestRank(t *testing.T) {
+	entries := []Entry{
+		{ID: 1, Result: Result{Score: 10, Time: 5}},
+		{ID: 2, Result: Result{Score: 20, Time: 9}},
+		{ID: 3, Result: Result{Score: 10, Time: 3}},
+		{ID: 4, Result: Result{Score: 10, Time: 3}},
+	}
+	Rank(entries)
+	want := []int64{2, 3, 4, 1}
+	for i, id := range want {
+		if entries[i].ID != id {
+			t.Errorf("entries[%d].ID = %d, want %d", i, entries[i].ID, id)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := (Policy{}).Validate(); err != nil {
+		t.Errorf("zero policy must be valid: %v", err)
+	}
+	if err := (Policy{Decay: DecayExponential, HalfLife: 1}).Validate(); err != nil {
+		t.Errorf("half_life 1 must be valid: %v", err)
+	}
+	invalid := []Policy{
+		{Decay: "quadratic"},
+		{Decay: DecayExponential, HalfLife: 2},
+		{Decay: DecayExponential, HalfLife: -0.5},
+		{MinFraction: -0.1},
+		{WrongPenalty: -1},
+		{StreakCap: -1},
+	}
+	for _, p := range invalid {
+		if err := p.Validate(); err == nil {
+			t.Errorf("policy %+v must be invalid", p)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dwnGnL/pg-contests/internal/api/models"
+	"github.com/dwnGnL/pg-contests/internal/repository"
+	"github.com/dwnGnL/pg-contests/lib/goerrors"
+)
+
+var (
+	ErrAttemptNotStarted   = errors.New("attempt is not started")
+	ErrAttemptWindowClosed = errors.New("attempt window is closed")
+)
+
+// GenerateAttempt таймлайн участника. У конкурса в своем темпе подключение в открытое окно начинает попытку,
+// живой конкурс идет по общему таймлайну
+func (s ServiceImpl) GenerateAttempt(contestID, userID int64) models.WsResponse {
+	contest, err := s.repo.GetContest(contestID)
+	if err != nil {
+		goerrors.Log().Warnln("err on GetContest ", err)
+		return models.WsResponse{}
+	}
+	if !contest.SelfPaced() {
+		return s.Generate(contestID)
+	}
+	attempt, err := s.startAttempt(contest, userID)
+	if errors.Is(err, ErrAttemptNotStarted) {
+		resp := contestTimeline(contest, nil)
+		//окно закрылось, а участник так и не начал
+		if resp.ContestStatus == models.Start {
+			resp.ContestStatus = models.End
+			resp.CountDown = 0
+		}
+		return resp
+	}
+	if err != nil {
+		goerrors.Log().WithError(err).Warnf("start contest %d attempt", contestID)
+		return models.WsResponse{}
+	}
+	now := time.Now()
+	resp := generateTimeline(replayTimeline(userQuestions(contest, userID), attempt.StartedAt, nil, now), attempt.StartedAt, now)
+	resp.ShuffleAnswers = contest.ShuffleAnswers
+	if contest.Status.Closed() && resp.ContestStatus != 0 {
+		resp.ContestStatus = models.End
+	}
+	return resp
+}
+
+// startAttempt начатая попытка участника или новая, если окно попыток открыто
+func (s ServiceImpl) startAttempt(contest *repository.Contest, userID int64) (*repository.Attempt, error) {
+	attempt, err := s.repo.GetAttempt(contest.ID, userID)
+	if err != nil || attempt != nil {
+		return attempt, err
+	}
+	now := time.Now()
+	if contest.Status.Closed() || now.Before(contest.StartTime) || !now.Before(contest.AttemptsUntil()) {
+		return nil, ErrAttemptNotStarted
+	}
+	return s.repo.StartAttempt(contest.ID, userID, now)
+}
+
+// timeline вопросы в порядке участника на его таймлайне: у живого конкурса общий с действиями ведущего,
+// в своем темпе - от начала попытки
+func (s ServiceImpl) timeline(contest *repository.Contest, userID int64, now time.Time) ([]slot, error) {
+	if !contest.SelfPaced() {
+		return s.liveSlots(contest, now)
+	}
+	attempt, err := s.repo.GetAttempt(contest.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if attempt == nil {
+		return nil, ErrAttemptNotStarted
+	}
+	return replayTimeline(userQuestions(contest, userID), attempt.StartedAt, nil, now), nil
+}
+
+// hiddenAnswers ответы на вопросы, которые у участника еще идут: до конца вопроса они не попадают в таблицу
+func (s ServiceImpl) hiddenAnswers(contest *repository.Contest) (func(repository.UserAnswers) bool, error) {
+	if contest.Status.Closed() {
+		return nil, nil
+	}
+	now := time.Now()
+	if !contest.SelfPaced() {
+		slots, err := s.liveSlots(contest, now)
+		if err != nil {
+			return nil, err
+		}
+		current := currentSlot(slots, now).Question
+		return func(userAnswer repository.UserAnswers) bool {
+			return userAnswer.QuestionID == current.ID
+		}, nil
+	}
+	attempts, err := s.repo.GetContestAttempts(contest.ID)
+	if err != nil {
+		return nil, err
+	}
+	current := make(map[int64]int64, len(attempts))
+	for _, attempt := range attempts {
+		slots := replayTimeline(userQuestions(contest, attempt.UserID), attempt.StartedAt, nil, now)
+		current[attempt.UserID] = currentSlot(slots, now).Question.ID
+	}
+	return func(userAnswer repository.UserAnswers) bool {
+		return userAnswer.QuestionID == current[userAnswer.UserID]
+	}, nil
+}
+
+// userQuestions вопросы в том порядке, в котором их видит участник
+func userQuestions(contest *repository.Contest, userID int64) []repository.Question {
+	questions := sortedQuestions(contest)
+	if contest.ShuffleQuestions {
+		questions = shuffleByID(questions, func(q repository.Question) int64 { return q.ID }, shuffleSeed(contest.ID, userID))
+	}
+	return questions
+}
+
+// generateWindow общий таймлайн конкурса в своем темпе: ожидание до StartTime, затем окно попыток
+// и время на прохождение последней из них. Вопросов в нем нет, у каждого участника они свои
+func generateWindow(contest *repository.Contest) models.WsResponse {
+	resp := models.WsResponse{TotalStep: len(contest.Questions)}
+	now := time.Now().Unix()
+	startTimeUnix := contest.StartTime.Unix()
+	endTimeUnix := contest.EndAt().Unix()
+	switch {
+	case now < startTimeUnix:
+		resp.ContestStatus = models.Waiting
+		resp.TotalTime = startTimeUnix
+		resp.CountDown = startTimeUnix - now
+	case now < endTimeUnix:
+		resp.ContestStatus = models.Start
+		resp.TotalTime = endTimeUnix
+		resp.CountDown = endTimeUnix - now
+	default:
+		resp.ContestStatus = models.End
+	}
+	return resp
+}
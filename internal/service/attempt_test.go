@@ -0,0 +1,113 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dwnGnL/pg-contests/internal/config"
+	"github.com/dwnGnL/pg-contests/internal/repository"
+)
+
+// attemptRepo конкурс в своем темпе и попытки участников в памяти
+type attemptRepo struct {
+	repositoryIter
+	contest  repository.Contest
+	attempts map[int64]*repository.Attempt
+}
+
+func (r *attemptRepo) ContestAvailability(int64, int64) (*repository.Contest, error) {
+	contest := r.contest
+	return &contest, nil
+}
+
+func (r *attemptRepo) GetAttempt(_, userID int64) (*repository.Attempt, error) {
+	return r.attempts[userID], nil
+}
+
+func (r *attemptRepo) StartAttempt(contestID, userID int64, at time.Time) (*repository.Attempt, error) {
+	r.attempts[userID] = &repository.Attempt{ContestID: contestID, UserID: userID, StartedAt: at}
+	return r.attempts[userID], nil
+}
+
+func selfPacedContest(start time.Time) repository.Contest {
+	return repository.Contest{
+		ID: 1, Mode: repository.ModeSelfPaced, Status: repository.StatusLive, StartTime: start, AttemptWindow: 3600,
+		Questions: []repository.Question{{ID: 1, Order: 1, Time: 10}, {ID: 2, Order: 2, Time: 10}, {ID: 3, Order: 3, Time: 10}},
+	}
+}
+
+func TestStartAttemptWindow(t *testing.T) {
+	now := time.Now()
+	earlier := now.Add(-2 * time.Hour)
+	tests := []struct {
+		name    string
+		start   time.Time
+		status  repository.ContestStatus
+		started *repository.Attempt
+		wantNew bool
+	}{
+		{name: "window open", start: now.Add(-time.Minute), wantNew: true},
+		{name: "before start", start: now.Add(time.Minute)},
+		{name: "window closed", start: now.Add(-2 * time.Hour)},
+		{name: "contest finished", start: now.Add(-time.Minute), status: repository.StatusFinished},
+		{name: "started attempt outlives the window", start: now.Add(-2 * time.Hour), started: &repository.Attempt{UserID: 7, StartedAt: earlier}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &attemptRepo{contest: selfPacedContest(tt.start), attempts: map[int64]*repository.Attempt{}}
+			if tt.status != "" {
+				repo.contest.Status = tt.status
+			}
+			if tt.started != nil {
+				repo.attempts[7] = tt.started
+			}
+			attempt, err := New(&config.Config{}, repo).startAttempt(&repo.contest, 7)
+			switch {
+			case tt.started != nil:
+				if err != nil || attempt != tt.started {
+					t.Fatalf("startAttempt = %+v, %v, want the started attempt", attempt, err)
+				}
+			case tt.wantNew:
+				if err != nil || attempt == nil || attempt.StartedAt.Before(now) {
+					t.Fatalf("startAttempt = %+v, %v, want a new attempt", attempt, err)
+				}
+			default:
+				if !errors.Is(err, ErrAttemptNotStarted) || repo.attempts[7] != nil {
+					t.Fatalf("startAttempt = %+v, %v, want ErrAttemptNotStarted", attempt, err)
+				}
+			}
+		})
+	}
+}
+
+func TestTimelinePerUserClock(t *testing.T) {
+	now := time.Now()
+	repo := &attemptRepo{contest: selfPacedContest(now.Add(-time.Hour)), attempts: map[int64]*repository.Attempt{
+		1: {UserID: 1, StartedAt: now.Add(-25 * time.Second)},
+		2: {UserID: 2, StartedAt: now.Add(-5 * time.Second)},
+		3: {UserID: 3, StartedAt: now.Add(-time.Minute)},
+	}}
+	s := New(&config.Config{}, repo)
+	//у каждого участника свой вопрос в один и тот же момент
+	for userID, want := range map[int64]int64{1: 3, 2: 1, 3: 0} {
+		slots, err := s.timeline(&repo.contest, userID, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := currentSlot(slots, now).Question.ID; got != want {
+			t.Errorf("user %d is on question %d, want %d", userID, got, want)
+		}
+	}
+	if _, err := s.timeline(&repo.contest, 4, now); !errors.Is(err, ErrAttemptNotStarted) {
+		t.Fatalf("timeline without attempt = %v, want ErrAttemptNotStarted", err)
+	}
+}
+
+func TestSubscribeAfterAttemptWindow(t *testing.T) {
+	repo := &attemptRepo{contest: selfPacedContest(time.Now().Add(-2 * time.Hour))}
+	err := New(&config.Config{}, repo).SubscribeContest(&repository.UserContests{ContestID: 1, UserID: 7}, "")
+	if !errors.Is(err, ErrAttemptWindowClosed) {
+		t.Fatalf("SubscribeContest = %v, want ErrAttemptWindowClosed", err)
+	}
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/dwnGnL/pg-contests/internal/repository"
+)
+
+var ErrInvalidBankDraw = errors.New("invalid bank draw")
+
+func (s ServiceImpl) CreateBankQuestion(question repository.BankQuestion) (*repository.BankQuestion, error) {
+	if err := s.repo.CreateBankQuestion(&question); err != nil {
+		return nil, err
+	}
+	return &question, nil
+}
+
+func (s ServiceImpl) GetBankQuestion(questionID int64) (*repository.BankQuestion, error) {
+	return s.repo.GetBankQuestion(questionID)
+}
+
+func (s ServiceImpl) SearchBankQuestions(filter repository.BankFilter, pagination *repository.Pagination) (*repository.Pagination, error) {
+	return s.repo.SearchBankQuestions(filter, pagination)
+}
+
+func (s ServiceImpl) UpdateBankQuestion(question repository.BankQuestion) (*repository.BankQuestion, error) {
+	return s.repo.UpdateBankQuestion(question)
+}
+
+func (s ServiceImpl) DeleteBankQuestion(questionID int64) error {
+	return s.repo.DeleteBankQuestion(questionID)
+}
+
+func (s ServiceImpl) GetBankTags() ([]repository.BankTag, error) {
+	return s.repo.GetBankTags()
+}
+
+// drawBankQuestions добавляет к вопросам конкурса снимки вопросов банка по contest.Bank.
+// Один вопрос банка попадает в конкурс не больше одного раза
+func (s ServiceImpl) drawBankQuestions(contest *repository.Contest) error {
+	order := 0
+	picked := make([]int64, 0)
+	for _, question := range contest.Questions {
+		if question.Order > order {
+			order = question.Order
+		}
+		if question.BankQuestionID != nil {
+			picked = append(picked, *question.BankQuestionID)
+		}
+	}
+
+	for i, draw := range contest.Bank {
+		var (
+			questions []repository.BankQuestion
+			err       error
+		)
+		switch {
+		case len(draw.IDs) > 0:
+			questions, err = s.bankQuestionsByIDs(draw.IDs)
+		case draw.Count > 0:
+			filter := repository.BankFilter{Tags: draw.Tags, MinDifficulty: draw.Difficulty, MaxDifficulty: draw.Difficulty}
+			questions, err = s.repo.DrawBankQuestions(filter, draw.Count, picked)
+		default:
+			err = fmt.Errorf("%w: ids or count is required", ErrInvalidBankDraw)
+		}
+		if err != nil {
+			return fmt.Errorf("bank draw #%d: %w", i, err)
+		}
+
+		for _, question := range questions {
+			for _, id := range picked {
+				if id == question.ID {
+					return fmt.Errorf("bank draw #%d: %w: question %d is already in the contest", i, ErrInvalidBankDraw, id)
+				}
+			}
+			order++
+			contest.Questions = append(contest.Questions, question.Snapshot(order))
+			picked = append(picked, question.ID)
+		}
+	}
+	contest.Bank = nil
+	return nil
+}
+
+// bankQuestionsByIDs вопросы банка в порядке ids
+func (s ServiceImpl) bankQuestionsByIDs(ids []int64) ([]repository.BankQuestion, error) {
+	questions, err := s.repo.GetBankQuestions(ids)
+	if err != nil {
+		return nil, err
+	}
+	position := make(map[int64]int, len(ids))
+	for i, id := range ids {
+		if _, ok := position[id]; !ok {
+			position[id] = i
+		}
+	}
+	if len(questions) != len(position) {
+		return nil, fmt.Errorf("%w: some of questions %v are not in the bank", repository.ErrNotEnoughBankQuestions, ids)
+	}
+	sort.Slice(questions, func(i, j int) bool {
+		return position[questions[i].ID] < position[questions[j].ID]
+	})
+	return questions, nil
+}
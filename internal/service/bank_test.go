@@ -0,0 +1,144 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/dwnGnL/pg-contests/internal/config"
+	"github.com/dwnGnL/pg-contests/internal/repository"
+)
+
+// bankRepo банк вопросов в памяти, случайная выборка как в DrawBankQuestions
+type bankRepo struct {
+	repositoryIter
+	questions []repository.BankQuestion
+}
+
+func (r *bankRepo) DrawBankQuestions(filter repository.BankFilter, count int, excludeIDs []int64) ([]repository.BankQuestion, error) {
+	excluded := make(map[int64]bool, len(excludeIDs))
+	for _, id := range excludeIDs {
+		excluded[id] = true
+	}
+	var found []repository.BankQuestion
+	for _, question := range r.questions {
+		if excluded[question.ID] || filter.MinDifficulty > 0 && question.Difficulty != filter.MinDifficulty {
+			continue
+		}
+		tags := make(map[string]bool, len(question.Tags))
+		for _, tag := range question.Tags {
+			tags[tag] = true
+		}
+		matched := true
+		for _, tag := range repository.NormalizeTags(filter.Tags) {
+			matched = matched && tags[tag]
+		}
+		if matched {
+			found = append(found, question)
+		}
+	}
+	rand.Shuffle(len(found), func(i, j int) { found[i], found[j] = found[j], found[i] })
+	if len(found) < count {
+		return nil, fmt.Errorf("%w: want %d, found %d", repository.ErrNotEnoughBankQuestions, count, len(found))
+	}
+	return found[:count], nil
+}
+
+func (r *bankRepo) GetBankQuestions(ids []int64) ([]repository.BankQuestion, error) {
+	var found []repository.BankQuestion
+	for _, question := range r.questions {
+		for _, id := range ids {
+			if question.ID == id {
+				found = append(found, question)
+				break
+			}
+		}
+	}
+	return found, nil
+}
+
+func testBank() *bankRepo {
+	repo := &bankRepo{}
+	for id := int64(1); id <= 10; id++ {
+		tags := []string{"история"}
+		if id%2 == 0 {
+			tags = append(tags, "россия")
+		}
+		repo.questions = append(repo.questions, repository.BankQuestion{
+			ID: id, Title: fmt.Sprint("Вопрос ", id), Score: 1, Time: 10, Difficulty: int(id%3 + 1), Tags: tags,
+		})
+	}
+	return repo
+}
+
+func TestDrawBankQuestionsByTag(t *testing.T) {
+	s := New(&config.Config{}, testBank())
+	existing := int64(2)
+	contest := repository.Contest{
+		Questions: []repository.Question{{Title: "Свой", Order: 3}, {Title: "Из банка", Order: 1, BankQuestionID: &existing}},
+		Bank: []repository.BankDraw{
+			{Tags: []string{" Россия "}, Count: 3},
+			{Tags: []string{"история"}, Count: 5},
+		},
+	}
+	if err := s.drawBankQuestions(&contest); err != nil {
+		t.Fatal(err)
+	}
+	if contest.Bank != nil || len(contest.Questions) != 10 {
+		t.Fatalf("bank = %v, %d questions, want all 8 drawn", contest.Bank, len(contest.Questions))
+	}
+	seen := map[int64]bool{}
+	for i, question := range contest.Questions {
+		if question.BankQuestionID == nil {
+			continue
+		}
+		id := *question.BankQuestionID
+		if seen[id] {
+			t.Fatalf("bank question %d drawn twice", id)
+		}
+		seen[id] = true
+		if i < 2 {
+			continue
+		}
+		if want := i + 2; question.Order != want {
+			t.Errorf("question %d order = %d, want %d", id, question.Order, want)
+		}
+		//первая выборка только из вопросов с тегом "россия", то есть четных
+		if i < 5 && id%2 != 0 {
+			t.Errorf("question %d has no tag россия", id)
+		}
+	}
+}
+
+func TestDrawBankQuestionsNotEnough(t *testing.T) {
+	s := New(&config.Config{}, testBank())
+	contest := repository.Contest{Bank: []repository.BankDraw{{Tags: []string{"россия"}, Count: 6}}}
+	if err := s.drawBankQuestions(&contest); !errors.Is(err, repository.ErrNotEnoughBankQuestions) {
+		t.Fatalf("drawBankQuestions = %v, want ErrNotEnoughBankQuestions", err)
+	}
+	contest = repository.Contest{Bank: []repository.BankDraw{{Tags: []string{"история"}}}}
+	if err := s.drawBankQuestions(&contest); !errors.Is(err, ErrInvalidBankDraw) {
+		t.Fatalf("drawBankQuestions = %v, want ErrInvalidBankDraw", err)
+	}
+}
+
+func TestDrawBankQuestionsByIDs(t *testing.T) {
+	s := New(&config.Config{}, testBank())
+	contest := repository.Contest{Bank: []repository.BankDraw{{IDs: []int64{7, 3, 9}}}}
+	if err := s.drawBankQuestions(&contest); err != nil {
+		t.Fatal(err)
+	}
+	var got []int64
+	for _, question := range contest.Questions {
+		got = append(got, *question.BankQuestionID)
+	}
+	if !reflect.DeepEqual(got, []int64{7, 3, 9}) {
+		t.Fatalf("drawn %v, want ids order", got)
+	}
+	contest = repository.Contest{Bank: []repository.BankDraw{{IDs: []int64{3}}, {IDs: []int64{3}}}}
+	if err := s.drawBankQuestions(&contest); !errors.Is(err, ErrInvalidBankDraw) {
+		t.Fatalf("drawBankQuestions = %v, want ErrInvalidBankDraw for a repeated question", err)
+	}
+}
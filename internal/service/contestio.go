@@ -0,0 +1,46 @@
+package service
+
+import (
+	"io"
+
+	"github.com/dwnGnL/pg-contests/internal/contestio"
+	"github.com/dwnGnL/pg-contests/internal/repository"
+)
+
+// ImportResult разобранный конкурс и ошибки по строкам файла; Contest создан, только если ошибок нет и это не DryRun
+type ImportResult struct {
+	Contest *repository.Contest  `json:"contest"`
+	Errors  []contestio.RowError `json:"errors"`
+	DryRun  bool                 `json:"dry_run"`
+}
+
+func (s ServiceImpl) ExportContest(contestID int64, format contestio.Format, w io.Writer) error {
+	contest, err := s.repo.GetContest(contestID)
+	if err != nil {
+		return err
+	}
+	return contestio.Export(w, contest, format)
+}
+
+// ImportContest импортированный конкурс всегда создается черновиком, чтобы автор проверил его перед планированием
+func (s ServiceImpl) ImportContest(r io.Reader, format contestio.Format, base repository.Contest, dryRun bool) (*ImportResult, error) {
+	parsed, err := contestio.Import(r, format, base)
+	if err != nil {
+		return nil, err
+	}
+	result := &ImportResult{Contest: &parsed.Contest, Errors: parsed.Errors, DryRun: dryRun}
+	if result.Errors == nil {
+		result.Errors = []contestio.RowError{}
+	}
+	if dryRun || len(parsed.Errors) > 0 {
+		return result, nil
+	}
+
+	parsed.Contest.Status = repository.StatusDraft
+	parsed.Contest.CreatedBy = base.CreatedBy
+	result.Contest, err = s.CreateContest(parsed.Contest)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
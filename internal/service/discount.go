@@ -0,0 +1,88 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/dwnGnL/pg-contests/internal/repository"
+)
+
+var ErrNoTicketUsers = errors.New("user_ids are required")
+
+// applyDiscounts цена места для участника: бесплатный конкурс не оплачивается, билет дает место бесплатно
+// (промокод тогда не тратится), промокод уменьшает цену. Билет и код окончательно списываются при записи подписки
+func (s ServiceImpl) applyDiscounts(contest *repository.Contest, userContest *repository.UserContests) error {
+	userContest.Price = contest.Price
+	userContest.TicketID = nil
+	userContest.PromoCode = strings.TrimSpace(userContest.PromoCode)
+	if contest.Price == 0 {
+		userContest.PromoCode = ""
+		return nil
+	}
+	ticket, err := s.repo.GetUserTikets(userContest.UserID, contest.ID)
+	if err != nil {
+		return err
+	}
+	if ticket.ID != 0 {
+		userContest.TicketID = &ticket.ID
+		userContest.Price = 0
+		userContest.PromoCode = ""
+		return nil
+	}
+	if userContest.PromoCode == "" {
+		return nil
+	}
+	promo, err := s.repo.GetPromoCode(userContest.PromoCode)
+	if err != nil {
+		return err
+	}
+	if err = promo.Available(contest.ID, time.Now()); err != nil {
+		return err
+	}
+	userContest.PromoCode = promo.Code
+	userContest.Price = promo.Apply(contest.Price)
+	return nil
+}
+
+// IssueTickets выдать участникам по бесплатному билету на конкурс
+func (s ServiceImpl) IssueTickets(contestID int64, userIDs []int64, by string) ([]repository.UserTickets, error) {
+	if len(userIDs) == 0 {
+		return nil, ErrNoTicketUsers
+	}
+	if _, err := s.repo.GetContestInfo(contestID); err != nil {
+		return nil, err
+	}
+	tickets := make([]repository.UserTickets, 0, len(userIDs))
+	for _, userID := range userIDs {
+		tickets = append(tickets, repository.UserTickets{UserID: userID, ContestID: contestID, CreatedBy: by})
+	}
+	if err := s.repo.IssueTickets(tickets); err != nil {
+		return nil, err
+	}
+	return tickets, nil
+}
+
+func (s ServiceImpl) GetContestTickets(contestID int64) ([]repository.UserTickets, error) {
+	return s.repo.GetContestTickets(contestID)
+}
+
+func (s ServiceImpl) CancelTicket(ticketID int64) (*repository.UserTickets, error) {
+	return s.repo.CancelTicket(ticketID)
+}
+
+func (s ServiceImpl) CreatePromoCode(promo repository.PromoCode) (*repository.PromoCode, error) {
+	promo.ID, promo.Used = 0, 0
+	if err := s.repo.CreatePromoCode(&promo); err != nil {
+		return nil, err
+	}
+	return &promo, nil
+}
+
+func (s ServiceImpl) GetPromoCodes() ([]repository.PromoCode, error) {
+	return s.repo.GetPromoCodes()
+}
+
+func (s ServiceImpl) DeletePromoCode(promoID int64) error {
+	return s.repo.DeletePromoCode(promoID)
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dwnGnL/pg-contests/internal/config"
+	"github.com/dwnGnL/pg-contests/internal/repository"
+)
+
+// discountRepo билеты по id участника и промокоды в памяти
+type discountRepo struct {
+	repositoryIter
+	tickets map[int64]repository.UserTickets
+	promos  []repository.PromoCode
+}
+
+func (r *discountRepo) GetUserTikets(userID, _ int64) (*repository.UserTickets, error) {
+	ticket := r.tickets[userID]
+	return &ticket, nil
+}
+
+func (r *discountRepo) GetPromoCode(code string) (*repository.PromoCode, error) {
+	for _, promo := range r.promos {
+		if strings.EqualFold(promo.Code, code) {
+			return &promo, nil
+		}
+	}
+	return nil, repository.ErrPromoNotFound
+}
+
+func TestApplyDiscounts(t *testing.T) {
+	repo := &discountRepo{
+		tickets: map[int64]repository.UserTickets{2: {ID: 40, UserID: 2, ContestID: 1}},
+		promos: []repository.PromoCode{
+			{Code: "HALF", Kind: repository.PromoPercent, Value: 50},
+			{Code: "USED", Kind: repository.PromoFixed, Value: 10, MaxUses: 1, Used: 1},
+		},
+	}
+	s := New(&config.Config{}, repo)
+	tests := []struct {
+		name      string
+		price     float64
+		userID    int64
+		promo     string
+		wantPrice float64
+		wantPromo string
+		wantErr   error
+	}{
+		{name: "full price", price: 100, userID: 1, wantPrice: 100},
+		{name: "promo code", price: 100, userID: 1, promo: " half ", wantPrice: 50, wantPromo: "HALF"},
+		{name: "ticket beats promo", price: 100, userID: 2, promo: "HALF", wantPrice: 0},
+		{name: "free contest ignores promo", userID: 1, promo: "HALF"},
+		{name: "unknown promo", price: 100, userID: 1, promo: "NOPE", wantErr: repository.ErrPromoNotFound},
+		{name: "used up promo", price: 100, userID: 1, promo: "USED", wantErr: repository.ErrPromoUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contest := repository.Contest{ID: 1, Price: tt.price}
+			userContest := repository.UserContests{ContestID: 1, UserID: tt.userID, PromoCode: tt.promo, Price: 1}
+			err := s.applyDiscounts(&contest, &userContest)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("applyDiscounts = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if userContest.Price != tt.wantPrice || userContest.PromoCode != tt.wantPromo {
+				t.Fatalf("price %v, promo %q, want %v, %q", userContest.Price, userContest.PromoCode, tt.wantPrice, tt.wantPromo)
+			}
+			//билет гасится только у того, у кого он есть
+			if redeemed := userContest.TicketID != nil; redeemed != (tt.userID == 2) || redeemed && *userContest.TicketID != 40 {
+				t.Fatalf("ticket = %v", userContest.TicketID)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/dwnGnL/pg-contests/internal/api/models"
+	"github.com/dwnGnL/pg-contests/internal/repository"
+)
+
+var (
+	ErrEliminated     = errors.New("player is eliminated")
+	ErrQuestionClosed = errors.New("question is closed")
+)
+
+// applyElimination добавляет в общее событие число оставшихся после закрытых вопросов и выбывших участников.
+// Состояние не хранится, а каждый раз пересчитывается из ответов, поэтому переживает смену лидера
+func (s ServiceImpl) applyElimination(contest *repository.Contest, resp *models.WsResponse) error {
+	closed := len(resp.Questions)
+	if resp.ActiveQuestionID != 0 {
+		closed--
+	}
+	participants, err := s.repo.GetContestParticipants(contest.ID)
+	if err != nil {
+		return err
+	}
+	userAnswers, err := s.repo.GetContestUserAnswers(contest.ID)
+	if err != nil {
+		return err
+	}
+	userIDs := make([]int64, 0, len(participants))
+	for _, participant := range participants {
+		userIDs = append(userIDs, participant.UserID)
+	}
+	questions := sortedQuestions(contest)
+	out := eliminate(questions, closed, userIDs, userAnswers)
+
+	resp.Survivors = make([]models.WsSurvivors, 0, closed)
+	for i := 0; i < closed; i++ {
+		count := len(userIDs)
+		for _, position := range out {
+			if position <= i {
+				count--
+			}
+		}
+		resp.Survivors = append(resp.Survivors, models.WsSurvivors{QuestionID: questions[i].ID, Step: i + 1, Count: count})
+	}
+	resp.EliminatedIDs = make([]int64, 0, len(out))
+	for userID := range out {
+		resp.EliminatedIDs = append(resp.EliminatedIDs, userID)
+	}
+	sort.Slice(resp.EliminatedIDs, func(i, j int) bool { return resp.EliminatedIDs[i] < resp.EliminatedIDs[j] })
+	return nil
+}
+
+// checkElimination в конкурсе на выбывание отвечать может только не выбывший участник и только на еще открытый вопрос
+func (s ServiceImpl) checkElimination(contestID, userID, questionID int64) error {
+	contest, err := s.repo.GetContest(contestID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	slots, err := s.liveSlots(contest, now)
+	if err != nil {
+		return err
+	}
+	questions := sortedQuestions(contest)
+	closed := passedSlots(slots, now)
+	for i := 0; i < closed; i++ {
+		//ответ на закрытый вопрос изменил бы уже разосланный итог
+		if questions[i].ID == questionID {
+			return ErrQuestionClosed
+		}
+	}
+	userAnswers, err := s.repo.GetUserAnswers(contestID, userID)
+	if err != nil {
+		return err
+	}
+	if _, ok := eliminate(questions, closed, []int64{userID}, userAnswers)[userID]; ok {
+		return ErrEliminated
+	}
+	return nil
+}
+
+// eliminationOrder поднимает не выбывших наверх таблицы, выбывшие - по тому, как долго продержались
+func eliminationOrder(contest *repository.Contest, closed int, stats []repository.ContestStats, userAnswers []repository.UserAnswers) {
+	questions := sortedQuestions(contest)
+	userIDs := make([]int64, 0, len(stats))
+	for _, stat := range stats {
+		userIDs = append(userIDs, stat.UserID)
+	}
+	out := eliminate(questions, closed, userIDs, userAnswers)
+	survived := func(userID int64) int {
+		if position, ok := out[userID]; ok {
+			return position
+		}
+		return len(questions)
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		return survived(stats[i].UserID) > survived(stats[j].UserID)
+	})
+	for i := range stats {
+		_, stats[i].Eliminated = out[stats[i].UserID]
+		stats[i].Rank = int64(i + 1)
+	}
+}
+
+// eliminate индекс вопроса, на котором выбыл участник: первый из closed закрытых без полностью верного ответа.
+// Аннулированный вопрос не выбивает никого
+func eliminate(questions []repository.Question, closed int, userIDs []int64, userAnswers []repository.UserAnswers) map[int64]int {
+	answered := make(map[int64]map[int64]float64, len(userIDs))
+	for _, userAnswer := range userAnswers {
+		if answered[userAnswer.UserID] == nil {
+			answered[userAnswer.UserID] = make(map[int64]float64)
+		}
+		answered[userAnswer.UserID][userAnswer.QuestionID] = userAnswer.Credit
+	}
+	out := make(map[int64]int)
+	for _, userID := range userIDs {
+		for i := 0; i < closed && i < len(questions); i++ {
+			if questions[i].Voided {
+				continue
+			}
+			credit, ok := answered[userID][questions[i].ID]
+			if !ok || credit < 1 {
+				out[userID] = i
+				break
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dwnGnL/pg-contests/internal/repository"
+)
+
+func TestEliminate(t *testing.T) {
+	questions := []repository.Question{{ID: 1}, {ID: 2, Voided: true}, {ID: 3}, {ID: 4}}
+	userAnswers := []repository.UserAnswers{
+		{UserID: 1, QuestionID: 1, Credit: 1}, {UserID: 1, QuestionID: 3, Credit: 1}, {UserID: 1, QuestionID: 4, Credit: 1},
+		{UserID: 2, QuestionID: 1, Credit: 1}, {UserID: 2, QuestionID: 3, Credit: 0.5},
+		{UserID: 3, QuestionID: 1},
+		//на аннулированный не ответил, но и не выбыл на нем
+		{UserID: 5, QuestionID: 1, Credit: 1}, {UserID: 5, QuestionID: 3, Credit: 1},
+	}
+	userIDs := []int64{1, 2, 3, 4, 5}
+	tests := []struct {
+		closed int
+		want   map[int64]int
+	}{
+		{closed: 0, want: map[int64]int{}},
+		{closed: 1, want: map[int64]int{3: 0, 4: 0}},
+		{closed: 3, want: map[int64]int{2: 2, 3: 0, 4: 0}},
+		{closed: 4, want: map[int64]int{2: 2, 3: 0, 4: 0, 5: 3}},
+		{closed: 10, want: map[int64]int{2: 2, 3: 0, 4: 0, 5: 3}},
+	}
+	for _, tt := range tests {
+		if got := eliminate(questions, tt.closed, userIDs, userAnswers); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("closed %d: eliminate = %v, want %v", tt.closed, got, tt.want)
+		}
+	}
+}
+
+func TestEliminationOrder(t *testing.T) {
+	contest := repository.Contest{Mode: repository.ModeElimination, Questions: []repository.Question{
+		{ID: 1, Order: 1}, {ID: 2, Order: 2}, {ID: 3, Order: 3},
+	}}
+	//по баллам выбывший на втором вопросе был бы первым
+	stats := []repository.ContestStats{{UserID: 1, TotalScore: 50}, {UserID: 2, TotalScore: 30}, {UserID: 3, TotalScore: 20}, {UserID: 4}}
+	userAnswers := []repository.UserAnswers{
+		{UserID: 1, QuestionID: 1, Credit: 1},
+		{UserID: 2, QuestionID: 1, Credit: 1}, {UserID: 2, QuestionID: 2, Credit: 1}, {UserID: 2, QuestionID: 3, Credit: 1},
+		{UserID: 3, QuestionID: 1, Credit: 1}, {UserID: 3, QuestionID: 2, Credit: 1},
+	}
+	eliminationOrder(&contest, 3, stats, userAnswers)
+	var order []int64
+	for i, stat := range stats {
+		order = append(order, stat.UserID)
+		if stat.Rank != int64(i+1) || stat.Eliminated != (stat.UserID != 2) {
+			t.Errorf("user %d: rank %d, eliminated %v", stat.UserID, stat.Rank, stat.Eliminated)
+		}
+	}
+	if !reflect.DeepEqual(order, []int64{2, 3, 1, 4}) {
+		t.Fatalf("order = %v, want [2 3 1 4]", order)
+	}
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dwnGnL/pg-contests/internal/api/models"
+	"github.com/dwnGnL/pg-contests/internal/repository"
+	"github.com/dwnGnL/pg-contests/lib/goerrors"
+)
+
+var (
+	ErrHostNotAllowed    = errors.New("host actions are available for a running live contest only")
+	ErrNoActiveQuestion  = errors.New("no question is running")
+	ErrAlreadyPaused     = errors.New("question is already paused")
+	ErrNotPaused         = errors.New("question is not paused")
+	ErrExtendSeconds     = errors.New("extend requires positive seconds")
+	ErrUnknownHostAction = errors.New("unknown host action")
+)
+
+// slot когда вопрос таймлайна фактически идет с учетом действий ведущего, время в unix секундах
+type slot struct {
+	Question repository.Question
+	Start    int64
+	End      int64 // у вопроса на паузе - конец, если снять с паузы сейчас
+	Extended int64 // секунды, добавленные ведущим
+	Paused   bool
+}
+
+// replayTimeline раскладывает вопросы (уже в порядке показа) от origin и применяет журнал ведущего до now.
+// Без событий это тот же таймлайн, что считался только из длительности вопросов
+func replayTimeline(questions []repository.Question, origin time.Time, events []repository.HostEvent, now time.Time) []slot {
+	slots := make([]slot, 0, len(questions))
+	start := origin.Unix()
+	var extended, pausedFor, pausedAt int64
+	paused := false
+	//закрыть текущий вопрос в end и перейти к следующему
+	closeCurrent := func(end int64) {
+		slots = append(slots, slot{Question: questions[len(slots)], Start: start, End: end, Extended: extended})
+		start, extended, pausedFor, paused = end, 0, 0, false
+	}
+	//вопросы, время которых вышло к моменту at
+	closeUntil := func(at int64) {
+		for !paused && len(slots) < len(questions) {
+			end := start + questions[len(slots)].Time + extended + pausedFor
+			if end > at {
+				return
+			}
+			closeCurrent(end)
+		}
+	}
+
+	for _, event := range events {
+		at := event.CreatedAt.Unix()
+		if at > now.Unix() {
+			break
+		}
+		closeUntil(at)
+		//действие относится к вопросу, который уже закрылся, или пришло до старта
+		if len(slots) == len(questions) || at < start || event.QuestionID != questions[len(slots)].ID {
+			continue
+		}
+		switch event.Action {
+		case repository.HostPause:
+			if !paused {
+				paused, pausedAt = true, at
+			}
+		case repository.HostResume:
+			if paused {
+				pausedFor += at - pausedAt
+				paused = false
+			}
+		case repository.HostExtend:
+			extended += event.Seconds
+		case repository.HostSkip, repository.HostVoid:
+			closeCurrent(at)
+		}
+	}
+	closeUntil(now.Unix())
+
+	for len(slots) < len(questions) {
+		question := questions[len(slots)]
+		end := start + question.Time + extended + pausedFor
+		if paused {
+			//пауза останавливает отсчет: оставшееся время сдвигается вместе с now
+			end += now.Unix() - pausedAt
+		}
+		slots = append(slots, slot{Question: question, Start: start, End: end, Extended: extended, Paused: paused})
+		start, extended, pausedFor, paused = end, 0, 0, false
+	}
+	return slots
+}
+
+// passedSlots сколько вопросов уже закрылись к now
+func passedSlots(slots []slot, now time.Time) int {
+	for i, s := range slots {
+		if s.Paused || s.End > now.Unix() {
+			return i
+		}
+	}
+	return len(slots)
+}
+
+// currentSlot идущий вопрос; до старта - первый, после конца - пустой
+func currentSlot(slots []slot, now time.Time) slot {
+	passed := passedSlots(slots, now)
+	if passed == len(slots) {
+		return slot{}
+	}
+	return slots[passed]
+}
+
+// answerTime сколько секунд вопроса прошло к now без учета пауз: до начала отрицательное,
+// в добавленное ведущим время - не больше Question.Time, после закрытия - больше него
+func answerTime(s slot, now time.Time) int64 {
+	if now.Unix() > s.End && !s.Paused {
+		return s.Question.Time + 1
+	}
+	if now.Unix() < s.Start {
+		return now.Unix() - s.Start
+	}
+	elapsed := s.Question.Time + s.Extended - (s.End - now.Unix())
+	if elapsed > s.Question.Time {
+		return s.Question.Time
+	}
+	return elapsed
+}
+
+// liveSlots общий таймлайн живого конкурса с действиями ведущего
+func (s ServiceImpl) liveSlots(contest *repository.Contest, now time.Time) ([]slot, error) {
+	events, err := s.repo.GetHostEvents(contest.ID)
+	if err != nil {
+		return nil, err
+	}
+	return replayTimeline(sortedQuestions(contest), contest.StartTime, events, now), nil
+}
+
+// HostAction действие ведущего над идущим вопросом; участники и лидер таймлайна узнают о нем через шину
+func (s ServiceImpl) HostAction(contestID int64, event repository.HostEvent) (*models.WsResponse, error) {
+	if !event.Action.Valid() {
+		return nil, fmt.Errorf("%w %q", ErrUnknownHostAction, event.Action)
+	}
+	if event.Action == repository.HostExtend && event.Seconds <= 0 {
+		return nil, ErrExtendSeconds
+	}
+	contest, err := s.repo.GetContest(contestID)
+	if err != nil {
+		return nil, err
+	}
+	if contest.SelfPaced() || contest.Status != repository.StatusLive {
+		return nil, ErrHostNotAllowed
+	}
+	now := time.Now().Truncate(time.Second)
+	slots, err := s.liveSlots(contest, now)
+	if err != nil {
+		return nil, err
+	}
+	current := currentSlot(slots, now)
+	if current.Question.ID == 0 || now.Unix() < current.Start {
+		return nil, ErrNoActiveQuestion
+	}
+	switch {
+	case event.Action == repository.HostPause && current.Paused:
+		return nil, ErrAlreadyPaused
+	case event.Action == repository.HostResume && !current.Paused:
+		return nil, ErrNotPaused
+	}
+
+	event.ID = 0
+	event.ContestID = contestID
+	event.QuestionID = current.Question.ID
+	if event.Action != repository.HostExtend {
+		event.Seconds = 0
+	}
+	event.CreatedAt = now
+	if err = s.repo.AddHostEvent(&event); err != nil {
+		return nil, err
+	}
+
+	resp := s.Generate(contestID)
+	resp.HostAction = string(event.Action)
+	if err = s.bus.Publish(context.Background(), contestID, resp); err != nil {
+		goerrors.Log().WithError(err).Warnf("publish contest %d host action", contestID)
+	}
+	return &resp, nil
+}
+
+func (s ServiceImpl) GetHostEvents(contestID int64) ([]repository.HostEvent, error) {
+	return s.repo.GetHostEvents(contestID)
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dwnGnL/pg-contests/internal/repository"
+)
+
+func TestReplayTimeline(t *testing.T) {
+	origin := time.Unix(1000, 0)
+	questions := []repository.Question{{ID: 1, Time: 10}, {ID: 2, Time: 20}, {ID: 3, Time: 30}}
+	event := func(action repository.HostAction, questionID, at int64) repository.HostEvent {
+		return repository.HostEvent{Action: action, QuestionID: questionID, CreatedAt: origin.Add(time.Duration(at) * time.Second)}
+	}
+	extend := func(questionID, at, seconds int64) repository.HostEvent {
+		e := event(repository.HostExtend, questionID, at)
+		e.Seconds = seconds
+		return e
+	}
+	//сравниваем только расписание: id вопроса, начало и конец от origin, добавленное время и паузу
+	type want struct {
+		ID, Start, End, Extended int64
+		Paused                   bool
+	}
+	tests := []struct {
+		name   string
+		events []repository.HostEvent
+		now    int64
+		want   []want
+	}{
+		{
+			name: "no events",
+			now:  100,
+			want: []want{{ID: 1, Start: 0, End: 10}, {ID: 2, Start: 10, End: 30}, {ID: 3, Start: 30, End: 60}},
+		},
+		{
+			name: "before start",
+			now:  -5,
+			want: []want{{ID: 1, Start: 0, End: 10}, {ID: 2, Start: 10, End: 30}, {ID: 3, Start: 30, End: 60}},
+		},
+		{
+			name:   "pause then resume",
+			events: []repository.HostEvent{event(repository.HostPause, 1, 4), event(repository.HostResume, 1, 9)},
+			now:    100,
+			want:   []want{{ID: 1, Start: 0, End: 15}, {ID: 2, Start: 15, End: 35}, {ID: 3, Start: 35, End: 65}},
+		},
+		{
+			name:   "still paused",
+			events: []repository.HostEvent{event(repository.HostPause, 1, 4)},
+			now:    20,
+			want:   []want{{ID: 1, Start: 0, End: 26, Paused: true}, {ID: 2, Start: 26, End: 46}, {ID: 3, Start: 46, End: 76}},
+		},
+		{
+			name:   "repeated pause keeps the first one",
+			events: []repository.HostEvent{event(repository.HostPause, 1, 4), event(repository.HostPause, 1, 6), event(repository.HostResume, 1, 9)},
+			now:    100,
+			want:   []want{{ID: 1, Start: 0, End: 15}, {ID: 2, Start: 15, End: 35}, {ID: 3, Start: 35, End: 65}},
+		},
+		{
+			name:   "extend",
+			events: []repository.HostEvent{extend(2, 12, 15)},
+			now:    100,
+			want:   []want{{ID: 1, Start: 0, End: 10}, {ID: 2, Start: 10, End: 45, Extended: 15}, {ID: 3, Start: 45, End: 75}},
+		},
+		{
+			name:   "skip",
+			events: []repository.HostEvent{event(repository.HostSkip, 1, 3)},
+			now:    100,
+			want:   []want{{ID: 1, Start: 0, End: 3}, {ID: 2, Start: 3, End: 23}, {ID: 3, Start: 23, End: 53}},
+		},
+		{
+			name:   "void",
+			events: []repository.HostEvent{event(repository.HostVoid, 2, 15)},
+			now:    100,
+			want:   []want{{ID: 1, Start: 0, End: 10}, {ID: 2, Start: 10, End: 15}, {ID: 3, Start: 15, End: 45}},
+		},
+		{
+			name:   "event after the question closed",
+			events: []repository.HostEvent{extend(1, 12, 30), event(repository.HostSkip, 1, 14)},
+			now:    100,
+			want:   []want{{ID: 1, Start: 0, End: 10}, {ID: 2, Start: 10, End: 30}, {ID: 3, Start: 30, End: 60}},
+		},
+		{
+			name:   "event after the contest ended",
+			events: []repository.HostEvent{extend(3, 70, 30)},
+			now:    100,
+			want:   []want{{ID: 1, Start: 0, End: 10}, {ID: 2, Start: 10, End: 30}, {ID: 3, Start: 30, End: 60}},
+		},
+		{
+			name:   "event after now",
+			events: []repository.HostEvent{event(repository.HostSkip, 1, 8)},
+			now:    5,
+			want:   []want{{ID: 1, Start: 0, End: 10}, {ID: 2, Start: 10, End: 30}, {ID: 3, Start: 30, End: 60}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slots := replayTimeline(questions, origin, tt.events, origin.Add(time.Duration(tt.now)*time.Second))
+			got := make([]want, 0, len(slots))
+			for _, s := range slots {
+				got = append(got, want{
+					ID:       s.Question.ID,
+					Start:    s.Start - origin.Unix(),
+					End:      s.End - origin.Unix(),
+					Extended: s.Extended,
+					Paused:   s.Paused,
+				})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("replayTimeline = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"github.com/dwnGnL/pg-contests/internal/repository"
+	"github.com/dwnGnL/pg-contests/internal/scoring"
+	"github.com/dwnGnL/pg-contests/lib/goerrors"
+)
+
+// leaderboard таблица по правилам подсчета конкурса; ответы, для которых hidden вернул true (на идущие вопросы), не учитываются
+func (s ServiceImpl) leaderboard(contest *repository.Contest, hidden func(repository.UserAnswers) bool) ([]repository.ContestStats, error) {
+	participants, err := s.repo.GetContestParticipants(contest.ID)
+	if err != nil {
+		return nil, err
+	}
+	userAnswers, err := s.repo.GetContestUserAnswers(contest.ID)
+	if err != nil {
+		return nil, err
+	}
+	byUser := make(map[int64][]repository.UserAnswers, len(participants))
+	for _, userAnswer := range userAnswers {
+		if hidden != nil && hidden(userAnswer) {
+			continue
+		}
+		byUser[userAnswer.UserID] = append(byUser[userAnswer.UserID], userAnswer)
+	}
+	if contest.Teams {
+		return s.teamLeaderboard(contest, participants, byUser)
+	}
+
+	names := make(map[int64]string, len(participants))
+	entries := make([]scoring.Entry, 0, len(participants))
+	for _, participant := range participants {
+		names[participant.UserID] = participant.UserName
+		questions := userQuestions(contest, participant.UserID)
+		entries = append(entries, scoring.Entry{
+			ID:     participant.UserID,
+			Result: contest.Scoring.Total(scoringAnswers(questions, byUser[participant.UserID])),
+		})
+	}
+	scoring.Rank(entries)
+
+	stats := make([]repository.ContestStats, 0, len(entries))
+	for i, entry := range entries {
+		stats = append(stats, repository.ContestStats{
+			Rank:         int64(i + 1),
+			UserID:       entry.ID,
+			UserName:     names[entry.ID],
+			TotalScore:   entry.Result.Score,
+			TotalTime:    entry.Result.Time,
+			TotalCorrect: entry.Result.Correct,
+		})
+	}
+	if contest.Elimination() {
+		now := time.Now()
+		slots, err := s.liveSlots(contest, now)
+		if err != nil {
+			return nil, err
+		}
+		eliminationOrder(contest, passedSlots(slots, now), stats, userAnswers)
+	}
+	return stats, nil
+}
+
+// finalizeResults фиксирует итоговую таблицу законченного конкурса
+func (s ServiceImpl) finalizeResults(contestID int64) error {
+	contest, err := s.repo.GetContest(contestID)
+	if err != nil {
+		return err
+	}
+	stats, err := s.leaderboard(contest, nil)
+	if err != nil {
+		return err
+	}
+	return s.repo.SaveContestResults(contestID, stats)
+}
+
+// finalResultsReady для законченного конкурса отдаем зафиксированную таблицу, если ее еще нет - фиксируем
+func (s ServiceImpl) finalResultsReady(contest *repository.Contest) bool {
+	if contest.Status != repository.StatusFinished && contest.Status != repository.StatusArchived {
+		return false
+	}
+	count, err := s.repo.CountContestResults(contest.ID)
+	if err != nil {
+		goerrors.Log().WithError(err).Warnf("count contest %d results", contest.ID)
+		return false
+	}
+	if count == 0 {
+		if err = s.finalizeResults(contest.ID); err != nil {
+			goerrors.Log().WithError(err).Warnf("finalize contest %d results", contest.ID)
+			return false
+		}
+	}
+	return true
+}
+
+// applyQuestionPoints проставляет баллы участника за каждый вопрос в полной статистике, вопросы уже в его порядке
+func applyQuestionPoints(contest *repository.Contest) {
+	userAnswers := make([]repository.UserAnswers, 0, len(contest.Questions))
+	for _, question := range contest.Questions {
+		if question.UserAnswer != nil {
+			userAnswers = append(userAnswers, *question.UserAnswer)
+		}
+	}
+	result := contest.Scoring.Total(scoringAnswers(contest.Questions, userAnswers))
+	for i := range contest.Questions {
+		p := result.Points[i]
+		contest.Questions[i].Points = &p
+	}
+}
+
+func sortedQuestions(contest *repository.Contest) []repository.Question {
+	questions := make([]repository.Question, len(contest.Questions))
+	copy(questions, contest.Questions)
+	sort.Slice(questions, func(i, j int) bool {
+		return questions[i].Order < questions[j].Order
+	})
+	return questions
+}
+
+// scoringAnswers ответы участника в порядке, в котором он видел вопросы, серия считается именно по этому порядку
+func scoringAnswers(questions []repository.Question, userAnswers []repository.UserAnswers) []scoring.Answer {
+	byQuestion := make(map[int64]repository.UserAnswers, len(userAnswers))
+	for _, userAnswer := range userAnswers {
+		byQuestion[userAnswer.QuestionID] = userAnswer
+	}
+	answers := make([]scoring.Answer, 0, len(questions))
+	for _, question := range questions {
+		answer := scoring.Answer{Score: question.Score, Limit: question.Time, Voided: question.Voided}
+		if userAnswer, ok := byQuestion[question.ID]; ok {
+			answer.Answered = true
+			answer.Elapsed = userAnswer.Time
+			answer.Credit = userAnswer.Credit
+		}
+		answers = append(answers, answer)
+	}
+	return answers
+}
+
+// paginateStats страница из посчитанной в памяти таблицы
+func paginateStats(stats []repository.ContestStats, pagination *repository.Pagination) *repository.Pagination {
+	totalRows := int64(len(stats))
+	from := (pagination.Page - 1) * pagination.Limit
+	if from < 0 {
+		from = 0
+	}
+	to := from + pagination.Limit
+	if from > len(stats) {
+		from = len(stats)
+	}
+	if to > len(stats) {
+		to = len(stats)
+	}
+	page := stats[from:to]
+
+	pagination.Records = &page
+	pagination.TotalRows = totalRows
+	pagination.TotalPages = int(pagination.TotalRows / int64(pagination.Limit))
+	if pagination.TotalRows%int64(pagination.Limit) > 0 {
+		pagination.TotalPages++
+	}
+	return pagination
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/dwnGnL/pg-contests/internal/repository"
+)
+
+func TestPaginateStats(t *testing.T) {
+	stats := make([]repository.ContestStats, 5)
+	for i := range stats {
+		stats[i].Rank = int64(i + 1)
+	}
+	tests := []struct {
+		name      string
+		page      int
+		limit     int
+		wantRanks []int64
+		wantPages int
+	}{
+		{"first page", 1, 2, []int64{1, 2}, 3},
+		{"last partial page", 3, 2, []int64{5}, 3},
+		{"page after the end", 4, 2, []int64{}, 3},
+		{"negative page", -1, 2, []int64{1, 2}, 3},
+		{"zero page", 0, 10, []int64{1, 2, 3, 4, 5}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pagination := paginateStats(stats, &repository.Pagination{Page: tt.page, Limit: tt.limit})
+			page := *pagination.Records.(*[]repository.ContestStats)
+			if len(page) != len(tt.wantRanks) {
+				t.Fatalf("got %d rows, want %d", len(page), len(tt.wantRanks))
+			}
+			for i, rank := range tt.wantRanks {
+				if page[i].Rank != rank {
+					t.Errorf("row %d rank = %d, want %d", i, page[i].Rank, rank)
+				}
+			}
+			if pagination.TotalPages != tt.wantPages || pagination.TotalRows != 5 {
+				t.Errorf("pages = %d, rows = %d", pagination.TotalPages, pagination.TotalRows)
+			}
+		})
+	}
+}
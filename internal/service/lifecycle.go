@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dwnGnL/pg-contests/internal/repository"
+	"github.com/dwnGnL/pg-contests/lib/goerrors"
+)
+
+var ErrTransitionNotAllowed = errors.New("contest status transition not allowed")
+
+// contestTransitions разрешенные переходы жизненного цикла конкурса.
+// В live конкурс переводит только планировщик в момент StartTime
+var contestTransitions = map[repository.ContestStatus][]repository.ContestStatus{
+	repository.StatusDraft:     {repository.StatusScheduled, repository.StatusCancelled},
+	repository.StatusScheduled: {repository.StatusDraft, repository.StatusLive, repository.StatusCancelled},
+	repository.StatusLive:      {repository.StatusFinished, repository.StatusCancelled},
+	repository.StatusFinished:  {repository.StatusArchived},
+	repository.StatusCancelled: {repository.StatusArchived},
+}
+
+func canTransition(from, to repository.ContestStatus) bool {
+	for _, allowed := range contestTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionContest переводит конкурс в новый статус по запросу админа
+func (s ServiceImpl) TransitionContest(contestID int64, to repository.ContestStatus) (*repository.Contest, error) {
+	if to == repository.StatusLive {
+		return nil, fmt.Errorf("%w: contest goes live by schedule only", ErrTransitionNotAllowed)
+	}
+	contest, err := s.repo.GetContest(contestID)
+	if err != nil {
+		return nil, err
+	}
+	if to == repository.StatusScheduled {
+		if err = contest.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	if err = s.changeStatus(contest, to); err != nil {
+		return nil, err
+	}
+
+	switch to {
+	case repository.StatusScheduled:
+		s.wakeScheduler()
+	case repository.StatusFinished, repository.StatusCancelled:
+		if to == repository.StatusFinished {
+			s.onContestFinished(contestID)
+		} else {
+			//возвраты ходят во внешний API с повторами, переход статуса их не ждет
+			go s.refundContest(contestID)
+		}
+		//подключенные участники должны сразу узнать что конкурс закончился
+		if err = s.bus.Publish(context.Background(), contestID, s.Generate(contestID)); err != nil {
+			goerrors.Log().WithError(err).Warnf("publish contest %d event", contestID)
+		}
+	}
+	return contest, nil
+}
+
+// ChangeStatus переключает конкурс между черновиком и запланированным
+func (s ServiceImpl) ChangeStatus(contestID int64) (repository.ContestStatus, error) {
+	contest, err := s.repo.GetContestInfo(contestID)
+	if err != nil {
+		return "", err
+	}
+	switch contest.Status {
+	case repository.StatusDraft:
+		contest, err = s.TransitionContest(contestID, repository.StatusScheduled)
+	case repository.StatusScheduled:
+		contest, err = s.TransitionContest(contestID, repository.StatusDraft)
+	default:
+		err = fmt.Errorf("%w: contest is %s", ErrTransitionNotAllowed, contest.Status)
+	}
+	if err != nil {
+		return "", err
+	}
+	return contest.Status, nil
+}
+
+func (s ServiceImpl) changeStatus(contest *repository.Contest, to repository.ContestStatus) error {
+	if !canTransition(contest.Status, to) {
+		return fmt.Errorf("%w: %s -> %s", ErrTransitionNotAllowed, contest.Status, to)
+	}
+	err := s.repo.UpdateContestStatus(contest.ID, contest.Status, to, time.Now())
+	if err != nil {
+		return err
+	}
+	contest.Status = to
+	return nil
+}
+
+// onContestFinished действия после перехода в finished, кем бы он ни был сделан
+func (s ServiceImpl) onContestFinished(contestID int64) {
+	if err := s.finalizeResults(contestID); err != nil {
+		goerrors.Log().WithError(err).Warnf("finalize contest %d results", contestID)
+		return
+	}
+	//выплаты ходят во внешний API с повторами, переход статуса их не ждет
+	go s.payContest(contestID)
+}
+
+func (s ServiceImpl) wakeScheduler() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
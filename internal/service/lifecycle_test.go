@@ -0,0 +1,104 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dwnGnL/pg-contests/internal/config"
+	"github.com/dwnGnL/pg-contests/internal/repository"
+)
+
+func TestCanTransition(t *testing.T) {
+	statuses := []repository.ContestStatus{
+		repository.StatusDraft, repository.StatusScheduled, repository.StatusLive,
+		repository.StatusFinished, repository.StatusCancelled, repository.StatusArchived,
+	}
+	allowed := map[[2]repository.ContestStatus]bool{
+		{repository.StatusDraft, repository.StatusScheduled}:     true,
+		{repository.StatusDraft, repository.StatusCancelled}:     true,
+		{repository.StatusScheduled, repository.StatusDraft}:     true,
+		{repository.StatusScheduled, repository.StatusLive}:      true,
+		{repository.StatusScheduled, repository.StatusCancelled}: true,
+		{repository.StatusLive, repository.StatusFinished}:       true,
+		{repository.StatusLive, repository.StatusCancelled}:      true,
+		{repository.StatusFinished, repository.StatusArchived}:   true,
+		{repository.StatusCancelled, repository.StatusArchived}:  true,
+	}
+	for _, from := range statuses {
+		for _, to := range statuses {
+			if got := canTransition(from, to); got != allowed[[2]repository.ContestStatus{from, to}] {
+				t.Errorf("canTransition(%s, %s) = %v", from, to, got)
+			}
+		}
+	}
+}
+
+// lifecycleRepo один конкурс в памяти
+type lifecycleRepo struct {
+	repositoryIter
+	contest repository.Contest
+}
+
+func (r *lifecycleRepo) GetContest(int64) (*repository.Contest, error) {
+	contest := r.contest
+	return &contest, nil
+}
+
+func (r *lifecycleRepo) UpdateContestStatus(_ int64, from, to repository.ContestStatus, _ time.Time) error {
+	if r.contest.Status != from {
+		return ErrTransitionNotAllowed
+	}
+	r.contest.Status = to
+	return nil
+}
+
+func TestTransitionContest(t *testing.T) {
+	valid := repository.Contest{ID: 1, StartTime: time.Now().Add(time.Hour), Timezone: "UTC"}
+	tests := []struct {
+		name    string
+		from    repository.ContestStatus
+		to      repository.ContestStatus
+		contest repository.Contest
+		wantErr error
+	}{
+		{name: "draft to scheduled", from: repository.StatusDraft, to: repository.StatusScheduled, contest: valid},
+		{name: "scheduled back to draft", from: repository.StatusScheduled, to: repository.StatusDraft, contest: valid},
+		{name: "live only by schedule", from: repository.StatusScheduled, to: repository.StatusLive, contest: valid, wantErr: ErrTransitionNotAllowed},
+		{name: "archived is final", from: repository.StatusArchived, to: repository.StatusDraft, contest: valid, wantErr: ErrTransitionNotAllowed},
+		{name: "draft cannot finish", from: repository.StatusDraft, to: repository.StatusFinished, contest: valid, wantErr: ErrTransitionNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &lifecycleRepo{contest: tt.contest}
+			repo.contest.Status = tt.from
+			contest, err := New(&config.Config{}, repo).TransitionContest(1, tt.to)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("TransitionContest = %v, want %v", err, tt.wantErr)
+				}
+				if repo.contest.Status != tt.from {
+					t.Fatalf("status changed to %s", repo.contest.Status)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if contest.Status != tt.to || repo.contest.Status != tt.to {
+				t.Fatalf("status = %s, stored %s, want %s", contest.Status, repo.contest.Status, tt.to)
+			}
+		})
+	}
+}
+
+func TestTransitionContestValidatesSchedule(t *testing.T) {
+	//без start_time конкурс нельзя запланировать
+	repo := &lifecycleRepo{contest: repository.Contest{ID: 1, Status: repository.StatusDraft, Timezone: "UTC"}}
+	if _, err := New(&config.Config{}, repo).TransitionContest(1, repository.StatusScheduled); err == nil {
+		t.Fatal("contest without start_time was scheduled")
+	}
+	if repo.contest.Status != repository.StatusDraft {
+		t.Fatalf("status changed to %s", repo.contest.Status)
+	}
+}
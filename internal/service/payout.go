@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/dwnGnL/pg-contests/internal/repository"
+	"github.com/dwnGnL/pg-contests/lib/goerrors"
+)
+
+// payContest считает выплаты по зафиксированной таблице и зачисляет их. Выплаты считаются один раз,
+// перепроверка вопросов после этого уже выплаченное не меняет
+func (s ServiceImpl) payContest(contestID int64) {
+	contest, err := s.repo.GetContestInfo(contestID)
+	if err != nil {
+		goerrors.Log().WithError(err).Warnf("pay contest %d", contestID)
+		return
+	}
+	if len(contest.Prizes.Places) == 0 {
+		return
+	}
+	results, err := s.repo.GetFinalResults(contestID)
+	if err != nil {
+		goerrors.Log().WithError(err).Warnf("pay contest %d: results", contestID)
+		return
+	}
+	participants, err := s.repo.GetContestParticipants(contestID)
+	if err != nil {
+		goerrors.Log().WithError(err).Warnf("pay contest %d: participants", contestID)
+		return
+	}
+	var teams []repository.Team
+	if contest.Teams {
+		if teams, err = s.repo.GetContestTeams(contestID); err != nil {
+			goerrors.Log().WithError(err).Warnf("pay contest %d: teams", contestID)
+			return
+		}
+	}
+	payouts := computePayouts(contest, results, participants, teams)
+	if err = s.repo.CreatePayouts(contestID, payouts); err != nil {
+		goerrors.Log().WithError(err).Warnf("pay contest %d: save payouts", contestID)
+		return
+	}
+	if _, err = s.processPayouts(contestID); err != nil {
+		goerrors.Log().WithError(err).Warnf("pay contest %d", contestID)
+	}
+}
+
+// processPayouts зачисляет еще не выплаченные выплаты конкурса
+func (s ServiceImpl) processPayouts(contestID int64) ([]repository.Payout, error) {
+	payouts, err := s.repo.GetContestPayouts(contestID)
+	if err != nil {
+		return nil, err
+	}
+	for _, payout := range payouts {
+		if payout.Status == repository.PayoutPaid {
+			continue
+		}
+		claimed, err := s.repo.ClaimPayout(contestID, payout.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if !claimed {
+			continue
+		}
+		payErr := s.payments.Credit(context.Background(), payout.UserID, payout.Amount, payout.IdempotencyKey)
+		if payErr != nil {
+			goerrors.Log().WithError(payErr).Warnf("contest %d payout to user %d", contestID, payout.UserID)
+		}
+		if err = s.repo.FinishPayout(contestID, payout.UserID, payErr); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.GetContestPayouts(contestID)
+}
+
+func (s ServiceImpl) GetContestPayouts(contestID int64) ([]repository.Payout, error) {
+	return s.repo.GetContestPayouts(contestID)
+}
+
+// RetryPayouts повтор неудавшихся выплат по запросу админа
+func (s ServiceImpl) RetryPayouts(contestID int64) ([]repository.Payout, error) {
+	return s.processPayouts(contestID)
+}
+
+// computePayouts выплаты по местам итоговой таблицы. participants - только оплаченные места: игроки,
+// которым оплату вернули, не входят ни в фонд, ни в призеры. Приз получают только набравшие баллы,
+// в конкурсе на выбывание - только не выбывшие, и они делят призы всех своих мест поровну;
+// приз команды делится поровну между ее оплаченными участниками, команда без них призов не получает
+func computePayouts(contest *repository.Contest, results []repository.ContestStats,
+	participants []repository.UserContests, teams []repository.Team) []repository.Payout {
+	var pool float64
+	paid := make(map[int64]bool, len(participants))
+	for _, participant := range participants {
+		pool += participant.Price
+		paid[participant.UserID] = true
+	}
+	prizes := make(map[int]float64, len(contest.Prizes.Places))
+	for _, prize := range contest.Prizes.Places {
+		prizes[prize.Place] = prize.Amount
+		if prize.Percent > 0 {
+			prizes[prize.Place] = pool * prize.Percent / 100
+		}
+	}
+	members := make(map[int64][]int64, len(teams))
+	for _, team := range teams {
+		for _, userID := range team.MemberIDs() {
+			if paid[userID] {
+				members[team.ID] = append(members[team.ID], userID)
+			}
+		}
+	}
+
+	winners := make([]repository.ContestStats, 0, len(results))
+	for _, result := range results {
+		if result.TeamID == 0 && !paid[result.UserID] || result.TeamID != 0 && len(members[result.TeamID]) == 0 {
+			continue
+		}
+		if result.TotalScore > 0 && !(contest.Elimination() && result.Eliminated) {
+			winners = append(winners, result)
+		}
+	}
+
+	var payouts []repository.Payout
+	add := func(result repository.ContestStats, place int, amount float64) {
+		if amount <= 0 {
+			return
+		}
+		userIDs := []int64{result.UserID}
+		if result.TeamID != 0 {
+			userIDs = members[result.TeamID]
+		}
+		for _, userID := range userIDs {
+			payouts = append(payouts, repository.Payout{
+				ContestID:      contest.ID,
+				UserID:         userID,
+				TeamID:         result.TeamID,
+				Place:          place,
+				Amount:         floorMoney(amount / float64(len(userIDs))),
+				Status:         repository.PayoutPending,
+				IdempotencyKey: fmt.Sprintf("contest-%d-payout-%d", contest.ID, userID),
+			})
+		}
+	}
+	place := 1
+	for i := 0; i < len(winners); {
+		//ничья: одинаковые баллы и время; выжившие в конкурсе на выбывание - одна группа
+		j := i + 1
+		for j < len(winners) && winners[j].TotalScore == winners[i].TotalScore && winners[j].TotalTime == winners[i].TotalTime {
+			j++
+		}
+		if contest.Elimination() {
+			j = len(winners)
+		}
+		if contest.Prizes.Ties == repository.TieRank && !contest.Elimination() {
+			for k := i; k < j; k++ {
+				add(winners[k], place+k-i, prizes[place+k-i])
+			}
+		} else {
+			var shared float64
+			for k := i; k < j; k++ {
+				shared += prizes[place+k-i]
+			}
+			for k := i; k < j; k++ {
+				add(winners[k], place, shared/float64(j-i))
+			}
+		}
+		place += j - i
+		i = j
+	}
+	return payouts
+}
+
+// floorMoney до копеек вниз, чтобы сумма выплат не превысила фонд
+func floorMoney(amount float64) float64 {
+	return math.Floor(amount*100+1e-9) / 100
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/dwnGnL/pg-contests/internal/repository"
+)
+
+func TestComputePayouts(t *testing.T) {
+	paid := func(ids ...int64) []repository.UserContests {
+		participants := make([]repository.UserContests, 0, len(ids))
+		for _, id := range ids {
+			participants = append(participants, repository.UserContests{UserID: id, Price: 10})
+		}
+		return participants
+	}
+	prizes := repository.PrizeConfig{Places: []repository.Prize{{Place: 1, Amount: 60}, {Place: 2, Amount: 30}, {Place: 3, Percent: 25}}}
+	tests := []struct {
+		name         string
+		contest      repository.Contest
+		results      []repository.ContestStats
+		participants []repository.UserContests
+		want         map[int64]float64
+	}{
+		{
+			name:    "places by rank",
+			contest: repository.Contest{ID: 1, Prizes: prizes},
+			results: []repository.ContestStats{
+				{UserID: 1, TotalScore: 30}, {UserID: 2, TotalScore: 20}, {UserID: 3, TotalScore: 10}, {UserID: 4},
+			},
+			participants: paid(1, 2, 3, 4),
+			want:         map[int64]float64{1: 60, 2: 30, 3: 10},
+		},
+		{
+			name:    "tie splits the places",
+			contest: repository.Contest{ID: 1, Prizes: prizes},
+			results: []repository.ContestStats{
+				{UserID: 1, TotalScore: 30, TotalTime: 5}, {UserID: 2, TotalScore: 30, TotalTime: 5}, {UserID: 3, TotalScore: 10},
+			},
+			participants: paid(1, 2, 3, 4),
+			want:         map[int64]float64{1: 45, 2: 45, 3: 10},
+		},
+		{
+			name:    "tie by rank rule",
+			contest: repository.Contest{ID: 1, Prizes: repository.PrizeConfig{Places: prizes.Places, Ties: repository.TieRank}},
+			results: []repository.ContestStats{
+				{UserID: 1, TotalScore: 30, TotalTime: 5}, {UserID: 2, TotalScore: 30, TotalTime: 5},
+			},
+			participants: paid(1, 2),
+			want:         map[int64]float64{1: 60, 2: 30},
+		},
+		{
+			name:    "elimination survivors split all prizes",
+			contest: repository.Contest{ID: 1, Mode: repository.ModeElimination, Prizes: prizes},
+			results: []repository.ContestStats{
+				{UserID: 1, TotalScore: 30}, {UserID: 2, TotalScore: 20}, {UserID: 3, TotalScore: 25, Eliminated: true},
+			},
+			participants: paid(1, 2, 3, 4),
+			want:         map[int64]float64{1: 45, 2: 45},
+		},
+		{
+			name:    "refunded player is not paid and not in the pool",
+			contest: repository.Contest{ID: 1, Prizes: prizes},
+			results: []repository.ContestStats{
+				{UserID: 1, TotalScore: 30}, {UserID: 2, TotalScore: 20}, {UserID: 3, TotalScore: 10},
+			},
+			participants: paid(2, 3),
+			want:         map[int64]float64{2: 60, 3: 30},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payouts := computePayouts(&tt.contest, tt.results, tt.participants, nil)
+			got := make(map[int64]float64, len(payouts))
+			for _, payout := range payouts {
+				got[payout.UserID] = payout.Amount
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("payouts = %v, want %v", got, tt.want)
+			}
+			for userID, amount := range tt.want {
+				if got[userID] != amount {
+					t.Errorf("user %d got %v, want %v", userID, got[userID], amount)
+				}
+			}
+		})
+	}
+}
+
+func TestComputePayoutsTeamSplit(t *testing.T) {
+	contest := repository.Contest{ID: 1, Teams: true, Prizes: repository.PrizeConfig{Places: []repository.Prize{{Place: 1, Amount: 100}}}}
+	teams := []repository.Team{{ID: 7, Members: []repository.UserContests{{UserID: 1}, {UserID: 2}, {UserID: 3}}}}
+	participants := []repository.UserContests{{UserID: 1}, {UserID: 2}, {UserID: 3}}
+	payouts := computePayouts(&contest, []repository.ContestStats{{TeamID: 7, TotalScore: 10}}, participants, teams)
+	if len(payouts) != 3 {
+		t.Fatalf("got %d payouts, want 3", len(payouts))
+	}
+	for _, payout := range payouts {
+		if payout.Amount != 33.33 || payout.TeamID != 7 {
+			t.Errorf("payout %+v", payout)
+		}
+	}
+}
+
+func TestComputePayoutsTeamRefundedMembers(t *testing.T) {
+	contest := repository.Contest{ID: 1, Teams: true, Prizes: repository.PrizeConfig{Places: []repository.Prize{{Place: 1, Amount: 100}, {Place: 2, Amount: 50}}}}
+	teams := []repository.Team{
+		{ID: 7, Members: []repository.UserContests{{UserID: 1}, {UserID: 2}, {UserID: 3}}},
+		{ID: 8, Members: []repository.UserContests{{UserID: 4}}},
+		{ID: 9, Members: []repository.UserContests{{UserID: 5}}},
+	}
+	results := []repository.ContestStats{{TeamID: 7, TotalScore: 30}, {TeamID: 8, TotalScore: 20}, {TeamID: 9, TotalScore: 10}}
+	//участнику 2 оплату вернули, команда 8 осталась без оплаченных участников
+	participants := []repository.UserContests{{UserID: 1}, {UserID: 3}, {UserID: 5}}
+	got := make(map[int64]float64)
+	for _, payout := range computePayouts(&contest, results, participants, teams) {
+		got[payout.UserID] = payout.Amount
+	}
+	want := map[int64]float64{1: 50, 3: 50, 5: 50}
+	if len(got) != len(want) {
+		t.Fatalf("payouts = %v, want %v", got, want)
+	}
+	for userID, amount := range want {
+		if got[userID] != amount {
+			t.Errorf("user %d got %v, want %v", userID, got[userID], amount)
+		}
+	}
+}
@@ -0,0 +1,325 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/dwnGnL/pg-contests/internal/repository"
+	"github.com/dwnGnL/pg-contests/lib/goerrors"
+	"github.com/dwnGnL/pg-contests/lib/imaging"
+)
+
+const (
+	defaultPhotoMaxSize    = 5 << 20
+	defaultThumbnailSide   = 320
+	defaultPhotoPresignTTL = 15 * time.Minute
+)
+
+var (
+	ErrInvalidImage          = errors.New("invalid image")
+	ErrStorageNotConfigured  = errors.New("photo storage is not configured")
+	ErrUnknownPhotoOwnerType = errors.New("unknown photo owner type")
+)
+
+// PhotoUpload описание файла для двухфазной загрузки
+type PhotoUpload struct {
+	FileName    string `json:"file_name" binding:"required"`
+	ContentType string `json:"content_type" binding:"required"`
+	Size        int64  `json:"size" binding:"required"`
+}
+
+// PresignedPhoto фото, ожидающее загрузки клиентом по UploadURL
+type PresignedPhoto struct {
+	Photo     *repository.Photo `json:"photo"`
+	UploadURL string            `json:"upload_url"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// UploadPhoto загрузка файла через сервис: проверка, сохранение оригинала и превью
+func (s ServiceImpl) UploadPhoto(ctx context.Context, ownerType string, ownerID int64, fileName string, r io.Reader) (*repository.Photo, error) {
+	if s.storage == nil {
+		return nil, ErrStorageNotConfigured
+	}
+	if err := s.checkPhotoOwner(ownerType, ownerID); err != nil {
+		return nil, err
+	}
+	data, err := s.readImage(r)
+	if err != nil {
+		return nil, err
+	}
+	photo := &repository.Photo{
+		FileName:  fileName,
+		OwnerID:   ownerID,
+		OwnerType: ownerType,
+	}
+	if err = s.storePhoto(ctx, photo, data, true); err != nil {
+		return nil, err
+	}
+	if err = s.repo.CreatePhoto(photo); err != nil {
+		s.removePhotoObjects([]repository.Photo{*photo})
+		return nil, err
+	}
+	return photo, nil
+}
+
+// PresignPhoto первая фаза двухфазной загрузки: запись фото и ссылка, по которой клиент загрузит файл сам
+func (s ServiceImpl) PresignPhoto(ctx context.Context, ownerType string, ownerID int64, upload PhotoUpload) (*PresignedPhoto, error) {
+	if s.storage == nil {
+		return nil, ErrStorageNotConfigured
+	}
+	if err := s.checkPhotoOwner(ownerType, ownerID); err != nil {
+		return nil, err
+	}
+	ext, ok := imaging.Extension(upload.ContentType)
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported content type %s", ErrInvalidImage, upload.ContentType)
+	}
+	if upload.Size <= 0 || upload.Size > s.photoMaxSize() {
+		return nil, fmt.Errorf("%w: size must be in (0, %d] bytes", ErrInvalidImage, s.photoMaxSize())
+	}
+
+	uploaded := false
+	photo := &repository.Photo{
+		FileName:    upload.FileName,
+		Uploaded:    &uploaded,
+		OwnerID:     ownerID,
+		OwnerType:   ownerType,
+		StorageKey:  newPhotoKey(ownerType, ownerID, ext),
+		ContentType: upload.ContentType,
+		Size:        upload.Size,
+	}
+	ttl := s.photoPresignTTL()
+	uploadURL, err := s.storage.PresignPut(ctx, photo.StorageKey, photo.ContentType, ttl)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.repo.CreatePhoto(photo); err != nil {
+		return nil, err
+	}
+	return &PresignedPhoto{Photo: photo, UploadURL: uploadURL, ExpiresAt: time.Now().Add(ttl)}, nil
+}
+
+// CompletePhoto вторая фаза: файл уже в хранилище, проверяем его и делаем превью.
+// Невалидный файл удаляется вместе с записью фото
+func (s ServiceImpl) CompletePhoto(ctx context.Context, photoID int64) (*repository.Photo, error) {
+	if s.storage == nil {
+		return nil, ErrStorageNotConfigured
+	}
+	photo, err := s.repo.GetPhoto(photoID)
+	if err != nil {
+		return nil, err
+	}
+	if photo.Uploaded != nil && *photo.Uploaded {
+		return photo, nil
+	}
+	if photo.StorageKey == "" {
+		return nil, fmt.Errorf("%w: photo %d was not presigned", ErrInvalidImage, photoID)
+	}
+
+	object, err := s.storage.Get(ctx, photo.StorageKey)
+	if err != nil {
+		return nil, fmt.Errorf("get uploaded photo: %w", err)
+	}
+	defer object.Close()
+	data, err := s.readImage(object)
+	if err == nil {
+		err = s.storePhoto(ctx, photo, data, false)
+	}
+	if errors.Is(err, ErrInvalidImage) {
+		if deleteErr := s.DeletePhoto(ctx, photoID); deleteErr != nil {
+			goerrors.Log().WithError(deleteErr).Warnf("delete invalid photo %d", photoID)
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = s.repo.UpdatePhoto(photo); err != nil {
+		return nil, err
+	}
+	return photo, nil
+}
+
+func (s ServiceImpl) DeletePhoto(ctx context.Context, photoID int64) error {
+	photo, err := s.repo.GetPhoto(photoID)
+	if err != nil {
+		return err
+	}
+	if err = s.repo.DeletePhoto(photoID); err != nil {
+		return err
+	}
+	s.removePhotoObjects([]repository.Photo{*photo})
+	return nil
+}
+
+func (s ServiceImpl) checkPhotoOwner(ownerType string, ownerID int64) error {
+	switch ownerType {
+	case repository.PhotoOwnerContests:
+		_, err := s.repo.GetContestInfo(ownerID)
+		return err
+	case repository.PhotoOwnerQuestions:
+		_, err := s.repo.GetQuestion(ownerID)
+		return err
+	case repository.PhotoOwnerAnswers:
+		_, err := s.repo.GetAnswer(ownerID)
+		return err
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownPhotoOwnerType, ownerType)
+	}
+}
+
+func (s ServiceImpl) readImage(r io.Reader) ([]byte, error) {
+	maxSize := s.photoMaxSize()
+	data, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("%w: file is larger than %d bytes", ErrInvalidImage, maxSize)
+	}
+	return data, nil
+}
+
+// storePhoto проверяет файл, сохраняет оригинал (если его еще нет в хранилище) и превью, заполняет ссылки фото
+func (s ServiceImpl) storePhoto(ctx context.Context, photo *repository.Photo, data []byte, putOriginal bool) error {
+	info, err := imaging.Inspect(data)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidImage, err)
+	}
+	if photo.StorageKey == "" {
+		photo.StorageKey = newPhotoKey(photo.OwnerType, photo.OwnerID, info.Ext)
+	} else if path.Ext(photo.StorageKey) != info.Ext {
+		return fmt.Errorf("%w: content is %s, not %s", ErrInvalidImage, info.ContentType, photo.ContentType)
+	}
+	thumb, thumbType, err := imaging.Thumbnail(data, s.thumbnailSide())
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidImage, err)
+	}
+
+	if putOriginal {
+		if err = s.storage.Put(ctx, photo.StorageKey, bytes.NewReader(data), int64(len(data)), info.ContentType); err != nil {
+			return err
+		}
+	}
+	thumbKey := thumbnailKey(photo.StorageKey)
+	if err = s.storage.Put(ctx, thumbKey, bytes.NewReader(thumb), int64(len(thumb)), thumbType); err != nil {
+		return err
+	}
+
+	uploaded := true
+	photo.Uploaded = &uploaded
+	photo.ContentType = info.ContentType
+	photo.Size = int64(len(data))
+	photo.Link = s.storage.URL(photo.StorageKey)
+	photo.ThumbnailLink = s.storage.URL(thumbKey)
+	return nil
+}
+
+// removePhotoObjects удаляет файлы из хранилища, ошибки только логируются - запись фото уже удалена
+func (s ServiceImpl) removePhotoObjects(photos []repository.Photo) {
+	if s.storage == nil {
+		return
+	}
+	for _, photo := range photos {
+		if photo.StorageKey == "" {
+			continue
+		}
+		for _, key := range []string{photo.StorageKey, thumbnailKey(photo.StorageKey)} {
+			if err := s.storage.Delete(context.Background(), key); err != nil {
+				goerrors.Log().WithError(err).Warnf("delete photo object %s", key)
+			}
+		}
+	}
+}
+
+// contestPhotos фото конкурса, его вопросов и ответов
+func contestPhotos(contest *repository.Contest) []repository.Photo {
+	photos := append([]repository.Photo(nil), contest.Photos...)
+	for _, question := range contest.Questions {
+		photos = append(photos, question.Photos...)
+		for _, answer := range question.Answers {
+			photos = append(photos, answer.Photos...)
+		}
+	}
+	return photos
+}
+
+// contestPhotoRefs фото конкурса, его вопросов и ответов в том же порядке, что contestPhotos
+func contestPhotoRefs(contest *repository.Contest) []*repository.Photo {
+	var photos []*repository.Photo
+	for i := range contest.Photos {
+		photos = append(photos, &contest.Photos[i])
+	}
+	for i := range contest.Questions {
+		question := &contest.Questions[i]
+		for j := range question.Photos {
+			photos = append(photos, &question.Photos[j])
+		}
+		for j := range question.Answers {
+			for k := range question.Answers[j].Photos {
+				photos = append(photos, &question.Answers[j].Photos[k])
+			}
+		}
+	}
+	return photos
+}
+
+// removedPhotos фото, которых больше нет в конкурсе после изменения
+func removedPhotos(before, after *repository.Contest) []repository.Photo {
+	kept := make(map[int64]struct{})
+	for _, photo := range contestPhotos(after) {
+		kept[photo.ID] = struct{}{}
+	}
+	var removed []repository.Photo
+	for _, photo := range contestPhotos(before) {
+		if _, ok := kept[photo.ID]; !ok {
+			removed = append(removed, photo)
+		}
+	}
+	return removed
+}
+
+func (s ServiceImpl) photoMaxSize() int64 {
+	if s.conf.Storage.MaxSize > 0 {
+		return s.conf.Storage.MaxSize
+	}
+	return defaultPhotoMaxSize
+}
+
+func (s ServiceImpl) thumbnailSide() int {
+	if s.conf.Storage.Thumbnail > 0 {
+		return s.conf.Storage.Thumbnail
+	}
+	return defaultThumbnailSide
+}
+
+func (s ServiceImpl) photoPresignTTL() time.Duration {
+	if s.conf.Storage.PresignTTL > 0 {
+		return s.conf.Storage.PresignTTL
+	}
+	return defaultPhotoPresignTTL
+}
+
+func newPhotoKey(ownerType string, ownerID int64, ext string) string {
+	random := make([]byte, 16)
+	_, _ = rand.Read(random)
+	return fmt.Sprintf("%s/%d/%s%s", ownerType, ownerID, hex.EncodeToString(random), ext)
+}
+
+// thumbnailKey превью jpeg-картинок в JPEG, остальных в PNG (см. imaging.Thumbnail)
+func thumbnailKey(key string) string {
+	ext := path.Ext(key)
+	thumbExt := ".png"
+	if ext == ".jpg" {
+		thumbExt = ".jpg"
+	}
+	return strings.TrimSuffix(key, ext) + "_thumb" + thumbExt
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/dwnGnL/pg-contests/internal/config"
+	"github.com/dwnGnL/pg-contests/internal/repository"
+	"gorm.io/gorm"
+)
+
+func photoIDs(photos []repository.Photo) []int64 {
+	ids := make([]int64, 0, len(photos))
+	for _, photo := range photos {
+		ids = append(ids, photo.ID)
+	}
+	return ids
+}
+
+func photoContest() *repository.Contest {
+	return &repository.Contest{
+		Photos: []repository.Photo{{ID: 1}},
+		Questions: []repository.Question{
+			{Photos: []repository.Photo{{ID: 2}}, Answers: []repository.Answer{{Photos: []repository.Photo{{ID: 3}, {ID: 4}}}, {}}},
+			{Answers: []repository.Answer{{Photos: []repository.Photo{{ID: 5}}}}},
+		},
+	}
+}
+
+func TestContestPhotos(t *testing.T) {
+	contest := photoContest()
+	if got := photoIDs(contestPhotos(contest)); !reflect.DeepEqual(got, []int64{1, 2, 3, 4, 5}) {
+		t.Fatalf("contestPhotos = %v", got)
+	}
+	refs := contestPhotoRefs(contest)
+	for i, ref := range refs {
+		ref.Link = string(rune('a' + i))
+	}
+	if contest.Questions[0].Answers[0].Photos[1].Link != "d" || contest.Questions[1].Answers[0].Photos[0].Link != "e" {
+		t.Fatal("photo refs do not point into the contest in contestPhotos order")
+	}
+}
+
+func TestRemovedPhotos(t *testing.T) {
+	before, after := photoContest(), photoContest()
+	after.Questions[0].Answers[0].Photos = after.Questions[0].Answers[0].Photos[:1]
+	after.Questions = after.Questions[:1]
+	if got := photoIDs(removedPhotos(before, after)); !reflect.DeepEqual(got, []int64{4, 5}) {
+		t.Fatalf("removedPhotos = %v, want the answer photos that were dropped", got)
+	}
+}
+
+// photoOwnerRepo есть только вопрос 1 и ответ 2
+type photoOwnerRepo struct {
+	repositoryIter
+}
+
+func (photoOwnerRepo) GetQuestion(questionID int64) (*repository.Question, error) {
+	if questionID != 1 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &repository.Question{ID: 1}, nil
+}
+
+func (photoOwnerRepo) GetAnswer(answerID int64) (*repository.Answer, error) {
+	if answerID != 2 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &repository.Answer{ID: 2}, nil
+}
+
+func TestCheckPhotoOwner(t *testing.T) {
+	s := New(&config.Config{}, photoOwnerRepo{})
+	tests := []struct {
+		ownerType string
+		ownerID   int64
+		wantErr   error
+	}{
+		{ownerType: repository.PhotoOwnerQuestions, ownerID: 1},
+		{ownerType: repository.PhotoOwnerQuestions, ownerID: 2, wantErr: gorm.ErrRecordNotFound},
+		{ownerType: repository.PhotoOwnerAnswers, ownerID: 2},
+		{ownerType: repository.PhotoOwnerAnswers, ownerID: 1, wantErr: gorm.ErrRecordNotFound},
+		{ownerType: "users", ownerID: 1, wantErr: ErrUnknownPhotoOwnerType},
+	}
+	for _, tt := range tests {
+		if err := s.checkPhotoOwner(tt.ownerType, tt.ownerID); !errors.Is(err, tt.wantErr) {
+			t.Errorf("checkPhotoOwner(%s, %d) = %v, want %v", tt.ownerType, tt.ownerID, err, tt.wantErr)
+		}
+	}
+}
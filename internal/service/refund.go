@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dwnGnL/pg-contests/internal/repository"
+	"github.com/dwnGnL/pg-contests/lib/goerrors"
+)
+
+var ErrNotPaid = errors.New("player has not paid for this contest")
+
+// refundable оплату возвращаем, пока конкурс не закончился: у законченного фонд уходит на призы
+func refundable(contest *repository.Contest) bool {
+	return contest.Status != repository.StatusFinished && contest.FinishedAt == nil
+}
+
+// refundContest возврат оплаты всем участникам отмененного конкурса
+func (s ServiceImpl) refundContest(contestID int64) {
+	if err := s.repo.CreateRefunds(contestID, 0); err != nil {
+		goerrors.Log().WithError(err).Warnf("refund contest %d", contestID)
+		return
+	}
+	if _, err := s.processRefunds(contestID); err != nil {
+		goerrors.Log().WithError(err).Warnf("refund contest %d", contestID)
+	}
+}
+
+// processRefunds возвращает еще не возвращенное
+func (s ServiceImpl) processRefunds(contestID int64) ([]repository.Refund, error) {
+	refunds, err := s.repo.GetContestRefunds(contestID)
+	if err != nil {
+		return nil, err
+	}
+	for _, refund := range refunds {
+		if refund.Status == repository.RefundDone {
+			continue
+		}
+		if err = s.processRefund(refund); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.GetContestRefunds(contestID)
+}
+
+func (s ServiceImpl) processRefund(refund repository.Refund) error {
+	claimed, err := s.repo.ClaimRefund(refund.ContestID, refund.UserID)
+	if err != nil || !claimed {
+		return err
+	}
+	refundErr := s.payments.Refund(context.Background(), refund.UserID, refund.Amount, refund.IdempotencyKey)
+	if refundErr != nil {
+		goerrors.Log().WithError(refundErr).Warnf("contest %d refund to user %d", refund.ContestID, refund.UserID)
+	}
+	return s.repo.FinishRefund(refund.ContestID, refund.UserID, refundErr)
+}
+
+func (s ServiceImpl) GetContestRefunds(contestID int64) ([]repository.Refund, error) {
+	return s.repo.GetContestRefunds(contestID)
+}
+
+// RetryRefunds повтор неудавшихся возвратов по запросу админа
+func (s ServiceImpl) RetryRefunds(contestID int64) ([]repository.Refund, error) {
+	return s.processRefunds(contestID)
+}
+
+// RefundPlayer возврат одному игроку по запросу админа, статус конкурса не важен. После возврата игрок
+// выбывает из конкурса: не играет, не попадает в таблицу и призы. Повторный вызов денег второй раз не возвращает
+func (s ServiceImpl) RefundPlayer(contestID, userID int64) (*repository.Refund, error) {
+	if err := s.repo.CreateRefunds(contestID, userID); err != nil {
+		return nil, err
+	}
+	find := func() (*repository.Refund, error) {
+		refunds, err := s.repo.GetContestRefunds(contestID)
+		if err != nil {
+			return nil, err
+		}
+		for i := range refunds {
+			if refunds[i].UserID == userID {
+				return &refunds[i], nil
+			}
+		}
+		return nil, fmt.Errorf("%w: user %d, contest %d", ErrNotPaid, userID, contestID)
+	}
+	refund, err := find()
+	if err != nil || refund.Status == repository.RefundDone {
+		return refund, err
+	}
+	if err = s.processRefund(*refund); err != nil {
+		return nil, err
+	}
+	return find()
+}
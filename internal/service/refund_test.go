@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/dwnGnL/pg-contests/internal/config"
+	"github.com/dwnGnL/pg-contests/internal/payment"
+	"github.com/dwnGnL/pg-contests/internal/repository"
+)
+
+// refundRepo места и возвраты одного конкурса в памяти, по правилам RepoImpl
+type refundRepo struct {
+	repositoryIter
+	seats   map[int64]*repository.UserContests
+	refunds map[int64]*repository.Refund
+}
+
+func (r *refundRepo) CreateRefunds(contestID, userID int64) error {
+	for _, seat := range r.seats {
+		if userID != 0 && seat.UserID != userID || seat.Price <= 0 || seat.PaymentStatus != repository.PaymentPaid {
+			continue
+		}
+		if _, ok := r.refunds[seat.UserID]; !ok {
+			r.refunds[seat.UserID] = &repository.Refund{
+				ContestID: contestID, UserID: seat.UserID, Amount: seat.Price, Status: repository.RefundPending,
+				IdempotencyKey: fmt.Sprintf("contest-%d-refund-%d", contestID, seat.UserID),
+			}
+		}
+	}
+	return nil
+}
+
+func (r *refundRepo) GetContestRefunds(int64) ([]repository.Refund, error) {
+	var refunds []repository.Refund
+	for _, refund := range r.refunds {
+		refunds = append(refunds, *refund)
+	}
+	return refunds, nil
+}
+
+func (r *refundRepo) ClaimRefund(_, userID int64) (bool, error) {
+	refund := r.refunds[userID]
+	if refund == nil || refund.Status != repository.RefundPending && refund.Status != repository.RefundFailed {
+		return false, nil
+	}
+	refund.Status = repository.RefundProcessing
+	refund.Attempts++
+	return true, nil
+}
+
+func (r *refundRepo) FinishRefund(_, userID int64, refundErr error) error {
+	if refundErr != nil {
+		r.refunds[userID].Status, r.refunds[userID].LastError = repository.RefundFailed, refundErr.Error()
+		return nil
+	}
+	r.refunds[userID].Status, r.refunds[userID].LastError = repository.RefundDone, ""
+	r.seats[userID].PaymentStatus = repository.PaymentRefund
+	return nil
+}
+
+// countingProvider считает возвраты, первые failures из них не доходят до провайдера
+type countingProvider struct {
+	*payment.Fake
+	failures int
+	refunds  int
+}
+
+func (p *countingProvider) Refund(ctx context.Context, userID int64, amount float64, key string) error {
+	p.refunds++
+	if p.refunds <= p.failures {
+		return fmt.Errorf("refund balance err: %w", payment.ErrUnavailable)
+	}
+	return p.Fake.Refund(ctx, userID, amount, key)
+}
+
+func testRefundRepo() *refundRepo {
+	return &refundRepo{
+		seats: map[int64]*repository.UserContests{
+			1: {ContestID: 1, UserID: 1, Price: 10, PaymentStatus: repository.PaymentPaid},
+			2: {ContestID: 1, UserID: 2, Price: 10, PaymentStatus: repository.PaymentPending},
+			3: {ContestID: 1, UserID: 3, PaymentStatus: repository.PaymentPaid},
+		},
+		refunds: map[int64]*repository.Refund{},
+	}
+}
+
+func TestRefundPlayer(t *testing.T) {
+	repo, provider := testRefundRepo(), &countingProvider{Fake: payment.NewFake(0)}
+	s := New(&config.Config{}, repo, WithPayments(provider))
+	for i := 0; i < 2; i++ {
+		refund, err := s.RefundPlayer(1, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if refund.Status != repository.RefundDone || refund.Amount != 10 {
+			t.Fatalf("refund = %+v", refund)
+		}
+	}
+	if provider.refunds != 1 || provider.Balance(1) != 10 {
+		t.Fatalf("%d refunds, balance %v, want one refund of 10", provider.refunds, provider.Balance(1))
+	}
+	if repo.seats[1].PaymentStatus != repository.PaymentRefund {
+		t.Fatalf("seat status = %s, want refunded", repo.seats[1].PaymentStatus)
+	}
+	//не оплачено или бесплатно - возвращать нечего
+	for _, userID := range []int64{2, 3, 4} {
+		if _, err := s.RefundPlayer(1, userID); !errors.Is(err, ErrNotPaid) {
+			t.Errorf("RefundPlayer(%d) = %v, want ErrNotPaid", userID, err)
+		}
+	}
+}
+
+func TestRefundRetry(t *testing.T) {
+	repo, provider := testRefundRepo(), &countingProvider{Fake: payment.NewFake(0), failures: 1}
+	s := New(&config.Config{}, repo, WithPayments(provider))
+	s.refundContest(1)
+	refund := repo.refunds[1]
+	if refund.Status != repository.RefundFailed || refund.LastError == "" || repo.seats[1].PaymentStatus != repository.PaymentPaid {
+		t.Fatalf("failed refund = %+v, seat %s", refund, repo.seats[1].PaymentStatus)
+	}
+	if len(repo.refunds) != 1 {
+		t.Fatalf("refunds for %d players, want only the paid one", len(repo.refunds))
+	}
+
+	//возврат в работе у другого инстанса не повторяется
+	refund.Status = repository.RefundProcessing
+	if _, err := s.RetryRefunds(1); err != nil {
+		t.Fatal(err)
+	}
+	if provider.refunds != 1 {
+		t.Fatalf("claimed refund was sent again")
+	}
+
+	refund.Status = repository.RefundFailed
+	refunds, err := s.RetryRefunds(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refunds) != 1 || refunds[0].Status != repository.RefundDone || refunds[0].Attempts != 2 || provider.Balance(1) != 10 {
+		t.Fatalf("retried refunds = %+v, balance %v", refunds, provider.Balance(1))
+	}
+}
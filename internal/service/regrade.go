@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dwnGnL/pg-contests/internal/repository"
+	"github.com/dwnGnL/pg-contests/lib/goerrors"
+)
+
+var ErrPayoutsCreated = errors.New("prizes are already paid out by the current results, regrade would change them")
+
+// RegradeQuestion правка проверки вопроса задним числом: ответы участников пересчитываются по новой проверке,
+// итоговая таблица законченного конкурса фиксируется заново, подключенным участникам уходит событие.
+// После фиксации выплат правка запрещена: выплаченные призы по новой таблице уже не пересчитать
+func (s ServiceImpl) RegradeQuestion(questionID int64, regrade repository.QuestionRegrade, by string) (*repository.Question, error) {
+	question, err := s.repo.GetQuestion(questionID)
+	if err != nil {
+		return nil, err
+	}
+	payouts, err := s.repo.GetContestPayouts(question.ContestID)
+	if err != nil {
+		return nil, err
+	}
+	if len(payouts) > 0 {
+		return nil, ErrPayoutsCreated
+	}
+	if err = regrade.Apply(question); err != nil {
+		return nil, err
+	}
+	userAnswers, err := s.repo.GetContestUserAnswers(question.ContestID)
+	if err != nil {
+		return nil, err
+	}
+	credits := make(map[int64]float64)
+	var changed int64
+	for i := range userAnswers {
+		if userAnswers[i].QuestionID != question.ID {
+			continue
+		}
+		credit := question.Grade(&userAnswers[i])
+		if credit != userAnswers[i].Credit {
+			credits[userAnswers[i].UserID] = credit
+			changed++
+		}
+	}
+
+	action := "regrade"
+	if regrade.Voided != nil && regrade.CorrectIDs == nil && len(regrade.Accept) == 0 && regrade.Positions == nil {
+		action = "void"
+		if !*regrade.Voided {
+			action = "unvoid"
+		}
+	}
+	audit := repository.ContestAudit{
+		ContestID:      question.ContestID,
+		QuestionID:     question.ID,
+		Action:         action,
+		Details:        regrade,
+		ChangedAnswers: changed,
+		CreatedBy:      by,
+	}
+	if err = s.repo.RegradeQuestion(question, credits, &audit); err != nil {
+		return nil, err
+	}
+
+	contest, err := s.repo.GetContestInfo(question.ContestID)
+	if err != nil {
+		return nil, err
+	}
+	if contest.Status == repository.StatusFinished || contest.Status == repository.StatusArchived {
+		if err = s.finalizeResults(contest.ID); err != nil {
+			return nil, err
+		}
+	}
+	resp := s.Generate(contest.ID)
+	resp.ResultsChanged = true
+	if err = s.bus.Publish(context.Background(), contest.ID, resp); err != nil {
+		goerrors.Log().WithError(err).Warnf("publish contest %d regrade", contest.ID)
+	}
+	return question, nil
+}
+
+func (s ServiceImpl) GetContestAudit(contestID int64) ([]repository.ContestAudit, error) {
+	return s.repo.GetContestAudit(contestID)
+}
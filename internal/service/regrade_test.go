@@ -0,0 +1,41 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dwnGnL/pg-contests/internal/config"
+	"github.com/dwnGnL/pg-contests/internal/repository"
+)
+
+// paidOutRepo конкурс, по которому выплаты уже зафиксированы
+type paidOutRepo struct {
+	repositoryIter
+	regraded bool
+}
+
+func (r *paidOutRepo) GetQuestion(questionID int64) (*repository.Question, error) {
+	correct := true
+	return &repository.Question{ID: questionID, ContestID: 7, Answers: []repository.Answer{{ID: 1, IsCorrect: &correct}}}, nil
+}
+
+func (r *paidOutRepo) GetContestPayouts(contestID int64) ([]repository.Payout, error) {
+	return []repository.Payout{{ContestID: contestID, UserID: 1}}, nil
+}
+
+func (r *paidOutRepo) RegradeQuestion(*repository.Question, map[int64]float64, *repository.ContestAudit) error {
+	r.regraded = true
+	return nil
+}
+
+func TestRegradeQuestionAfterPayouts(t *testing.T) {
+	repo := &paidOutRepo{}
+	void := true
+	_, err := New(&config.Config{}, repo).RegradeQuestion(5, repository.QuestionRegrade{Voided: &void}, "admin")
+	if !errors.Is(err, ErrPayoutsCreated) {
+		t.Fatalf("RegradeQuestion = %v, want ErrPayoutsCreated", err)
+	}
+	if repo.regraded {
+		t.Fatal("question was regraded after payouts")
+	}
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/dwnGnL/pg-contests/internal/repository"
+	"github.com/dwnGnL/pg-contests/lib/cron"
+	"github.com/dwnGnL/pg-contests/lib/goerrors"
+)
+
+const (
+	defaultRecurringInterval = 5 * time.Minute
+	defaultRecurringHorizon  = 7 * 24 * time.Hour
+	// maxMaterializeRuns ограничение за один проход, чтобы "* * * * *" не создало тысячи конкурсов разом
+	maxMaterializeRuns = 50
+	scheduleNextRuns   = 5
+)
+
+func (s ServiceImpl) CreateSchedule(schedule repository.ContestSchedule) (*repository.ContestSchedule, error) {
+	if err := s.checkScheduleTemplate(&schedule); err != nil {
+		return nil, err
+	}
+	schedule.Issued = 0
+	schedule.LastStartTime = nil
+	schedule.LastError = ""
+	if err := s.repo.CreateSchedule(&schedule); err != nil {
+		return nil, err
+	}
+	return s.runScheduleNow(schedule.ID)
+}
+
+func (s ServiceImpl) GetSchedule(scheduleID int64) (*repository.ContestSchedule, error) {
+	schedule, err := s.repo.GetSchedule(scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	return s.withNextRuns(schedule), nil
+}
+
+func (s ServiceImpl) GetSchedules(pagination *repository.Pagination) (*repository.Pagination, error) {
+	pagination, err := s.repo.GetSchedules(pagination)
+	if err != nil {
+		return nil, err
+	}
+	if schedules, ok := pagination.Records.(*[]repository.ContestSchedule); ok {
+		for i := range *schedules {
+			s.withNextRuns(&(*schedules)[i])
+		}
+	}
+	return pagination, nil
+}
+
+// UpdateSchedule уже созданные конкурсы не меняются, новое выражение действует для следующих выпусков
+func (s ServiceImpl) UpdateSchedule(schedule repository.ContestSchedule) (*repository.ContestSchedule, error) {
+	if err := s.checkScheduleTemplate(&schedule); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.UpdateSchedule(schedule); err != nil {
+		return nil, err
+	}
+	return s.runScheduleNow(schedule.ID)
+}
+
+// PauseSchedule на паузе новые конкурсы не создаются; после возобновления пропущенные выпуски не догоняются
+func (s ServiceImpl) PauseSchedule(scheduleID int64, paused bool) (*repository.ContestSchedule, error) {
+	if err := s.repo.SetSchedulePaused(scheduleID, paused); err != nil {
+		return nil, err
+	}
+	return s.runScheduleNow(scheduleID)
+}
+
+func (s ServiceImpl) DeleteSchedule(scheduleID int64) error {
+	return s.repo.DeleteSchedule(scheduleID)
+}
+
+// runScheduleNow изменения расписания сразу видны: ближайшие конкурсы создаются не дожидаясь планировщика
+func (s ServiceImpl) runScheduleNow(scheduleID int64) (*repository.ContestSchedule, error) {
+	schedule, err := s.repo.GetSchedule(scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if !schedule.Paused {
+		s.runSchedule(*schedule)
+	}
+	return s.GetSchedule(scheduleID)
+}
+
+func (s ServiceImpl) checkScheduleTemplate(schedule *repository.ContestSchedule) error {
+	if schedule.TemplateID == nil {
+		return nil
+	}
+	_, err := s.repo.GetTemplate(*schedule.TemplateID)
+	return err
+}
+
+func (s ServiceImpl) withNextRuns(schedule *repository.ContestSchedule) *repository.ContestSchedule {
+	if !schedule.Paused {
+		schedule.NextRuns = schedule.Runs(time.Now(), scheduleNextRuns)
+	}
+	return schedule
+}
+
+// materializeSchedules создает конкурсы всех активных расписаний на RecurringHorizon вперед
+func (s ServiceImpl) materializeSchedules(ctx context.Context) {
+	schedules, err := s.repo.GetActiveSchedules()
+	if err != nil {
+		goerrors.Log().WithError(err).Warn("scheduler: get schedules")
+		return
+	}
+	for _, schedule := range schedules {
+		if ctx.Err() != nil {
+			return
+		}
+		s.runSchedule(schedule)
+	}
+}
+
+// runSchedule ошибка выпуска сохраняется в расписании, чтобы ее было видно в админке
+func (s ServiceImpl) runSchedule(schedule repository.ContestSchedule) {
+	err := s.materializeSchedule(schedule, time.Now().Add(s.recurringHorizon()))
+	message := ""
+	if err != nil {
+		goerrors.Log().WithError(err).Warnf("scheduler: materialize schedule %d", schedule.ID)
+		message = err.Error()
+	}
+	if message != schedule.LastError {
+		if err = s.repo.SetScheduleError(schedule.ID, message); err != nil {
+			goerrors.Log().WithError(err).Warnf("scheduler: save schedule %d error", schedule.ID)
+		}
+	}
+	s.wakeScheduler()
+}
+
+// materializeSchedule пропущенные выпуски (сервис лежал или расписание стояло на паузе) не создаются задним числом
+func (s ServiceImpl) materializeSchedule(schedule repository.ContestSchedule, until time.Time) error {
+	expr, err := cron.Parse(schedule.Cron)
+	if err != nil {
+		return err
+	}
+	loc, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return err
+	}
+	after := time.Now()
+	if schedule.LastStartTime != nil && schedule.LastStartTime.After(after) {
+		after = *schedule.LastStartTime
+	}
+
+	for i := 0; i < maxMaterializeRuns; i++ {
+		startTime := expr.Next(after.In(loc))
+		if startTime.IsZero() || startTime.After(until) {
+			return nil
+		}
+		contest, err := s.scheduledContest(&schedule, startTime)
+		if err != nil {
+			return err
+		}
+		created, err := s.repo.CreateScheduledContest(contest)
+		if err != nil {
+			return err
+		}
+		if created != nil {
+			goerrors.Log().Infof("scheduler: schedule %d created contest %d at %s", schedule.ID, created.ID, startTime)
+			if schedule.TemplateID == nil {
+				schedule.Issued++
+			}
+		} else if fresh, err := s.repo.GetSchedule(schedule.ID); err == nil {
+			//выпуск создал другой экземпляр сервиса, номер выпуска мог сдвинуться
+			schedule.Issued = fresh.Issued
+		}
+		after = startTime
+	}
+	return nil
+}
+
+// scheduledContest конкурс выпуска startTime с уже набранными из банка вопросами
+func (s ServiceImpl) scheduledContest(schedule *repository.ContestSchedule, startTime time.Time) (repository.Contest, error) {
+	template := schedule.Template()
+	if schedule.TemplateID != nil {
+		stored, err := s.repo.GetTemplate(*schedule.TemplateID)
+		if err != nil {
+			return repository.Contest{}, err
+		}
+		template = *stored
+	}
+	contest := template.Contest(startTime)
+	scheduleID := schedule.ID
+	contest.ScheduleID = &scheduleID
+	contest.CreatedBy = schedule.CreatedBy
+	if schedule.Publish {
+		now := time.Now()
+		contest.Status = repository.StatusScheduled
+		contest.ScheduledAt = &now
+	}
+	if err := s.drawBankQuestions(&contest); err != nil {
+		return repository.Contest{}, err
+	}
+	return contest, nil
+}
+
+func (s ServiceImpl) recurringInterval() time.Duration {
+	if s.conf.Scheduler.RecurringInterval > 0 {
+		return s.conf.Scheduler.RecurringInterval
+	}
+	return defaultRecurringInterval
+}
+
+func (s ServiceImpl) recurringHorizon() time.Duration {
+	if s.conf.Scheduler.RecurringHorizon > 0 {
+		return s.conf.Scheduler.RecurringHorizon
+	}
+	return defaultRecurringHorizon
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/dwnGnL/pg-contests/lib/goerrors"
+)
+
+const (
+	defaultSchedulerInterval = 30 * time.Second
+	defaultSchedulerHorizon  = 10 * time.Minute
+)
+
+// StartScheduler ведет таймлайны конкурсов независимо от того, подключен ли кто-то по вебсокету.
+// Состояние не хранится в памяти: после рестарта все пересчитывается из StartTime и длительности вопросов,
+// конкурсы, закончившиеся пока сервис лежал, сразу помечаются законченными
+func (s ServiceImpl) StartScheduler(ctx context.Context) {
+	interval := s.conf.Scheduler.Interval
+	if interval <= 0 {
+		interval = defaultSchedulerInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var materializedAt time.Time
+	for {
+		//конкурсы по расписаниям создаются раньше, чтобы ближайшие сразу попали в планировщик
+		if time.Since(materializedAt) >= s.recurringInterval() {
+			s.materializeSchedules(ctx)
+			materializedAt = time.Now()
+		}
+		s.scheduleContests(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+func (s ServiceImpl) scheduleContests(ctx context.Context) {
+	horizon := s.conf.Scheduler.Horizon
+	if horizon <= 0 {
+		horizon = defaultSchedulerHorizon
+	}
+	contests, err := s.repo.GetContestsToSchedule()
+	if err != nil {
+		goerrors.Log().WithError(err).Warn("scheduler: get contests")
+		return
+	}
+	for _, contest := range contests {
+		if time.Until(contest.StartTime) > horizon {
+			continue
+		}
+		if _, loaded := s.drivers.LoadOrStore(contest.ID, struct{}{}); !loaded {
+			go s.driveContest(ctx, contest.ID)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dwnGnL/pg-contests/internal/config"
+	"github.com/dwnGnL/pg-contests/internal/eventbus"
+	"github.com/dwnGnL/pg-contests/internal/repository"
+)
+
+// scheduleRepo конкурсы планировщика в памяти
+type scheduleRepo struct {
+	repositoryIter
+	contests []repository.Contest
+}
+
+func (r *scheduleRepo) GetContestsToSchedule() ([]repository.Contest, error) {
+	return r.contests, nil
+}
+
+func (r *scheduleRepo) GetContest(contestID int64) (*repository.Contest, error) {
+	for _, contest := range r.contests {
+		if contest.ID == contestID {
+			return &contest, nil
+		}
+	}
+	return &repository.Contest{}, nil
+}
+
+func (r *scheduleRepo) GetHostEvents(int64) ([]repository.HostEvent, error) {
+	return nil, nil
+}
+
+func TestScheduleContestsHorizon(t *testing.T) {
+	repo := &scheduleRepo{contests: []repository.Contest{
+		{ID: 1, Status: repository.StatusScheduled, StartTime: time.Now().Add(time.Minute)},
+		{ID: 2, Status: repository.StatusScheduled, StartTime: time.Now().Add(time.Hour)},
+	}}
+	s := New(&config.Config{}, repo)
+	//конкурс 1 уже ведется, второй раз его не запускаем
+	s.drivers.Store(1, struct{}{})
+	s.scheduleContests(context.Background())
+	if _, ok := s.drivers.Load(2); ok {
+		t.Fatal("contest beyond the horizon is driven")
+	}
+	if _, ok := s.drivers.Load(1); !ok {
+		t.Fatal("driven contest was dropped")
+	}
+}
+
+func TestDriveContestFollowerStopsAtEnd(t *testing.T) {
+	bus := eventbus.NewMemory()
+	defer bus.Close()
+	repo := &scheduleRepo{contests: []repository.Contest{{
+		ID: 1, Status: repository.StatusFinished, StartTime: time.Now().Add(-time.Hour),
+		Questions: []repository.Question{{ID: 1, Time: 10}},
+	}}}
+	s := New(&config.Config{}, repo, WithBus(bus))
+	//таймлайн ведет другой инстанс
+	release, ok, err := bus.Lead(context.Background(), 1)
+	if err != nil || !ok {
+		t.Fatalf("Lead = %v, %v", ok, err)
+	}
+	defer release()
+
+	s.drivers.Store(1, struct{}{})
+	done := make(chan struct{})
+	go func() {
+		s.driveContest(context.Background(), 1)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("follower keeps watching a finished contest")
+	}
+	if _, ok := s.drivers.Load(1); ok {
+		t.Fatal("finished contest is still registered as driven")
+	}
+}
@@ -1,48 +1,144 @@
 package service
 
 import (
-	"bytes"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"sort"
 	"time"
 
 	"github.com/dwnGnL/pg-contests/internal/config"
+	"github.com/dwnGnL/pg-contests/internal/eventbus"
+	"github.com/dwnGnL/pg-contests/internal/payment"
 	"github.com/dwnGnL/pg-contests/internal/repository"
+	"github.com/dwnGnL/pg-contests/lib/cachemap"
 	"github.com/dwnGnL/pg-contests/lib/goerrors"
+	"github.com/dwnGnL/pg-contests/lib/storage"
 )
 
 type repositoryIter interface {
 	GetAllContest(pagination *repository.Pagination) (*repository.Pagination, error)
 	GetAllContestByUserID(userID int64, pagination *repository.Pagination) (*repository.Pagination, error)
-	GetContestStatsById(contestID, currentQuestionID int64, pagination *repository.Pagination) (*repository.Pagination, error)
-	GetContestStatsForUser(contestID, userID, currentQuestionID int64) (*repository.ContestStats, error)
-	GetContestFullStatsForUser(contestID, userID int64, currentQuestionOrder int) (*repository.Contest, error)
+	GetContestParticipants(contestID int64) ([]repository.UserContests, error)
+	GetContestUserAnswers(contestID int64) ([]repository.UserAnswers, error)
+	GetUserAnswers(contestID, userID int64) ([]repository.UserAnswers, error)
+	SaveContestResults(contestID int64, results []repository.ContestStats) error
+	CountContestResults(contestID int64) (int64, error)
+	GetContestResults(contestID int64, pagination *repository.Pagination) (*repository.Pagination, error)
+	GetContestResultForUser(contestID, userID, teamID int64) (*repository.ContestStats, error)
+	GetContestFullStatsForUser(contestID, userID int64, teamID *int64) (*repository.Contest, error)
 	CreateContest(contest repository.Contest) (*repository.Contest, error)
-	UpdateContest(contest repository.Contest) (*repository.Contest, error)
+	UpdateContest(contest repository.Contest, force bool) (*repository.Contest, bool, error)
 	ChangeContestInfo(contest *repository.Contest) error
 	DeleteContest(contest repository.Contest) error
 	GetContest(contestID int64) (*repository.Contest, error)
 	GetContestInfo(contestID int64) (*repository.Contest, error)
 	GetUserTikets(userID, tiketID int64) (*repository.UserTickets, error)
 	Migrate() error
+	GetContestsToSchedule() ([]repository.Contest, error)
+	UpdateContestStatus(contestID int64, from, to repository.ContestStatus, at time.Time) error
 	SubscribeContest(userContest *repository.UserContests) error
 	ContestAvailability(contestID int64, userID int64) (*repository.Contest, error)
 	GetUserContest(contestID int64, userID int64) (*repository.UserContests, error)
+	GetQuestion(questionID int64) (*repository.Question, error)
+	GetAnswer(answerID int64) (*repository.Answer, error)
 	SubmitAnswer(userAnswer *repository.UserAnswers) (err error)
+	CreatePhoto(photo *repository.Photo) error
+	GetPhoto(photoID int64) (*repository.Photo, error)
+	UpdatePhoto(photo *repository.Photo) error
+	DeletePhoto(photoID int64) error
+	CreateBankQuestion(question *repository.BankQuestion) error
+	GetBankQuestion(questionID int64) (*repository.BankQuestion, error)
+	GetBankQuestions(questionIDs []int64) ([]repository.BankQuestion, error)
+	UpdateBankQuestion(question repository.BankQuestion) (*repository.BankQuestion, error)
+	DeleteBankQuestion(questionID int64) error
+	SearchBankQuestions(filter repository.BankFilter, pagination *repository.Pagination) (*repository.Pagination, error)
+	DrawBankQuestions(filter repository.BankFilter, count int, excludeIDs []int64) ([]repository.BankQuestion, error)
+	GetBankTags() ([]repository.BankTag, error)
+	CreateTemplate(template *repository.ContestTemplate) error
+	GetTemplate(templateID int64) (*repository.ContestTemplate, error)
+	GetTemplates(pagination *repository.Pagination) (*repository.Pagination, error)
+	UpdateTemplate(template repository.ContestTemplate) (*repository.ContestTemplate, error)
+	DeleteTemplate(templateID int64) error
+	IncrementTemplateIssued(templateID int64) error
+	CreateSchedule(schedule *repository.ContestSchedule) error
+	GetSchedule(scheduleID int64) (*repository.ContestSchedule, error)
+	GetSchedules(pagination *repository.Pagination) (*repository.Pagination, error)
+	GetActiveSchedules() ([]repository.ContestSchedule, error)
+	UpdateSchedule(schedule repository.ContestSchedule) (*repository.ContestSchedule, error)
+	SetSchedulePaused(scheduleID int64, paused bool) error
+	SetScheduleError(scheduleID int64, message string) error
+	DeleteSchedule(scheduleID int64) error
+	CreateScheduledContest(contest repository.Contest) (*repository.Contest, error)
+	StartAttempt(contestID, userID int64, at time.Time) (*repository.Attempt, error)
+	GetAttempt(contestID, userID int64) (*repository.Attempt, error)
+	GetContestAttempts(contestID int64) ([]repository.Attempt, error)
+	GetTeam(teamID int64) (*repository.Team, error)
+	GetContestTeams(contestID int64) ([]repository.Team, error)
+	SubmitTeamAnswer(userAnswer *repository.UserAnswers, teamID int64) error
+	AddHostEvent(event *repository.HostEvent) error
+	GetHostEvents(contestID int64) ([]repository.HostEvent, error)
+	RegradeQuestion(question *repository.Question, credits map[int64]float64, audit *repository.ContestAudit) error
+	GetContestAudit(contestID int64) ([]repository.ContestAudit, error)
+	GetFinalResults(contestID int64) ([]repository.ContestStats, error)
+	CreatePayouts(contestID int64, payouts []repository.Payout) error
+	GetContestPayouts(contestID int64) ([]repository.Payout, error)
+	ClaimPayout(contestID, userID int64) (bool, error)
+	FinishPayout(contestID, userID int64, payErr error) error
+	CreateRefunds(contestID, userID int64) error
+	GetContestRefunds(contestID int64) ([]repository.Refund, error)
+	ClaimRefund(contestID, userID int64) (bool, error)
+	FinishRefund(contestID, userID int64, refundErr error) error
+	ConfirmSubscription(contestID, userID int64, paymentKey string) (bool, error)
+	CancelSubscription(contestID, userID int64, paymentKey string) (bool, error)
+	GetPendingSubscriptions(before time.Time) ([]repository.UserContests, error)
+	IssueTickets(tickets []repository.UserTickets) error
+	GetContestTickets(contestID int64) ([]repository.UserTickets, error)
+	CancelTicket(ticketID int64) (*repository.UserTickets, error)
+	CreatePromoCode(promo *repository.PromoCode) error
+	GetPromoCodes() ([]repository.PromoCode, error)
+	GetPromoCode(code string) (*repository.PromoCode, error)
+	DeletePromoCode(promoID int64) error
 }
 
 type ServiceImpl struct {
-	conf *config.Config
-	repo repositoryIter
+	conf     *config.Config
+	repo     repositoryIter
+	bus      eventbus.Bus
+	storage  storage.Storage
+	payments payment.Provider
+	drivers  *cachemap.CacheMaper[int64, struct{}]
+	wake     chan struct{}
 }
 
 type Option func(*ServiceImpl)
 
+func WithBus(bus eventbus.Bus) Option {
+	return func(s *ServiceImpl) {
+		s.bus = bus
+	}
+}
+
+// WithStorage хранилище загружаемых фото, без него загрузка недоступна
+func WithStorage(st storage.Storage) Option {
+	return func(s *ServiceImpl) {
+		s.storage = st
+	}
+}
+
+// WithPayments провайдер оплаты; без него - пустой провайдер в памяти, на котором любое списание отклоняется
+func WithPayments(p payment.Provider) Option {
+	return func(s *ServiceImpl) {
+		s.payments = p
+	}
+}
+
 func New(conf *config.Config, repo repositoryIter, opts ...Option) *ServiceImpl {
 	s := ServiceImpl{
-		conf: conf,
-		repo: repo,
+		conf:     conf,
+		repo:     repo,
+		bus:      eventbus.NewMemory(),
+		payments: payment.NewFake(0),
+		drivers:  cachemap.NewCacheMap[int64, struct{}](),
+		wake:     make(chan struct{}, 1),
 	}
 
 	for _, opt := range opts {
@@ -59,7 +155,9 @@ func (s ServiceImpl) CheckAndReturnContestByUserID(contestID, userID int64) (*re
 	if err != nil {
 		return nil, fmt.Errorf("GetContest err: %w", err)
 	}
-	if !*contest.Active {
+	switch contest.Status {
+	case repository.StatusScheduled, repository.StatusLive, repository.StatusFinished:
+	default:
 		return nil, fmt.Errorf("contest not active")
 	}
 	userContest, err := s.repo.GetUserContest(contestID, userID)
@@ -74,68 +172,40 @@ func (s ServiceImpl) CheckAndReturnContestByUserID(contestID, userID int64) (*re
 	return contest, nil
 }
 
-func (s ServiceImpl) CalculateTimeForQuestion(contestID, questionID int64) (resTime int64, err error) {
+// CalculateTimeForQuestion сколько секунд прошло с начала вопроса на таймлайне участника, паузы ведущего не считаются
+func (s ServiceImpl) CalculateTimeForQuestion(contestID, userID, questionID int64) (resTime int64, err error) {
 	contest, err := s.repo.GetContest(contestID)
 	if err != nil {
 		goerrors.Log().Warnln("err on GetContest ", err)
 		return
 	}
 
-	layout := "2006-01-02T15:04Z07:00"
-	startTime, err := time.Parse(layout, contest.StartTime)
+	now := time.Now()
+	slots, err := s.timeline(contest, userID, now)
 	if err != nil {
-		goerrors.Log().Warnln("err on time.Parse ", err)
 		return
 	}
-	startTimeUnix := startTime.Unix()
-
-	now := time.Now().Unix() - startTimeUnix
-
-	sort.Slice(contest.Questions, func(i, j int) bool {
-		return contest.Questions[i].Order < contest.Questions[j].Order
-	})
-
-	var totalTime int64
-	for _, v := range contest.Questions {
-		if v.ID == questionID {
-			resTime = now - totalTime
-			return
+	for _, slot := range slots {
+		if slot.Question.ID == questionID {
+			return answerTime(slot, now), nil
 		}
-		totalTime += v.Time
 	}
 	return
 }
 
-func (s ServiceImpl) GetCurrentQuestion(contestID int64) (question repository.Question, err error) {
+func (s ServiceImpl) GetCurrentQuestion(contestID, userID int64) (question repository.Question, err error) {
 	contest, err := s.repo.GetContest(contestID)
 	if err != nil {
 		goerrors.Log().Warnln("err on GetContest ", err)
 		return
 	}
 
-	layout := "2006-01-02T15:04Z07:00"
-	startTime, err := time.Parse(layout, contest.StartTime)
+	now := time.Now()
+	slots, err := s.timeline(contest, userID, now)
 	if err != nil {
-		goerrors.Log().Warnln("err on time.Parse ", err)
 		return
 	}
-	startTimeUnix := startTime.Unix()
-
-	now := time.Now().Unix() - startTimeUnix
-
-	sort.Slice(contest.Questions, func(i, j int) bool {
-		return contest.Questions[i].Order < contest.Questions[j].Order
-	})
-
-	var totalTime int64
-	for _, v := range contest.Questions {
-		totalTime += v.Time
-		if now <= totalTime {
-			question = v
-			return
-		}
-	}
-	return
+	return currentSlot(slots, now).Question, nil
 }
 
 func (s ServiceImpl) GetAllContest(pagination *repository.Pagination) (*repository.Pagination, error) {
@@ -155,47 +225,85 @@ func (s ServiceImpl) GetAllContestByUserID(userID int64, pagination *repository.
 }
 
 func (s ServiceImpl) GetContestStatsById(contestID int64, pagination *repository.Pagination) (*repository.Pagination, error) {
-	currentQuestion, err := s.GetCurrentQuestion(contestID)
+	contest, err := s.repo.GetContest(contestID)
+	if err != nil {
+		return nil, err
+	}
+	if s.finalResultsReady(contest) {
+		return s.repo.GetContestResults(contestID, pagination)
+	}
+	hidden, err := s.hiddenAnswers(contest)
 	if err != nil {
 		return nil, err
 	}
-	contestStats, err := s.repo.GetContestStatsById(contestID, currentQuestion.ID, pagination)
+	contestStats, err := s.leaderboard(contest, hidden)
 	if err != nil {
 		return nil, err
 	}
-	return contestStats, nil
+	return paginateStats(contestStats, pagination), nil
 }
 
+// GetContestStatsForUser строка участника, в командном конкурсе - строка его команды
 func (s ServiceImpl) GetContestStatsForUser(contestID, userID int64) (*repository.ContestStats, error) {
-	currentQuestion, err := s.GetCurrentQuestion(contestID)
+	contest, err := s.repo.GetContest(contestID)
+	if err != nil {
+		return nil, err
+	}
+	var teamID int64
+	if contest.Teams {
+		team, err := s.userTeamID(contestID, userID)
+		if err != nil || team == nil {
+			return nil, err
+		}
+		userID, teamID = 0, *team
+	}
+	if s.finalResultsReady(contest) {
+		return s.repo.GetContestResultForUser(contestID, userID, teamID)
+	}
+	hidden, err := s.hiddenAnswers(contest)
 	if err != nil {
 		return nil, err
 	}
 
-	contestStats, err := s.repo.GetContestStatsForUser(contestID, userID, currentQuestion.ID)
+	contestStats, err := s.leaderboard(contest, hidden)
 	if err != nil {
 		return nil, err
 	}
-	return contestStats, nil
+	for i := range contestStats {
+		if contestStats[i].UserID == userID && contestStats[i].TeamID == teamID {
+			return &contestStats[i], nil
+		}
+	}
+	return nil, nil
 }
 
+// GetContestFullStatsForUser вопросы в порядке участника; пока конкурс идет - только те, что у него уже закончились.
+// В командном конкурсе с ответами всей команды
 func (s ServiceImpl) GetContestFullStatsForUser(contestID, userID int64) (*repository.Contest, error) {
-	currentQuestion, err := s.GetCurrentQuestion(contestID)
+	teamID, err := s.userTeamID(contestID, userID)
 	if err != nil {
 		return nil, err
 	}
-	cont, err := s.repo.GetContest(contestID)
+	contest, err := s.repo.GetContestFullStatsForUser(contestID, userID, teamID)
 	if err != nil {
 		return nil, err
 	}
-	if cont.IsEnd != nil && *cont.IsEnd {
-		currentQuestion.Order = 0
-	}
-
-	contest, err := s.repo.GetContestFullStatsForUser(contestID, userID, currentQuestion.Order)
-	if err != nil {
-		return nil, err
+	questions := userQuestions(contest, userID)
+	if !contest.Status.Closed() {
+		now := time.Now()
+		slots, err := s.timeline(contest, userID, now)
+		switch {
+		case errors.Is(err, ErrAttemptNotStarted):
+			questions = nil
+		case err != nil:
+			return nil, err
+		default:
+			questions = questions[:passedSlots(slots, now)]
+		}
 	}
+	contest.Questions = questions
+	applyQuestionPoints(contest)
+	shuffleContestAnswers(contest, userID)
 	return contest, nil
 }
 
@@ -208,6 +316,21 @@ func (s ServiceImpl) GetContest(contestID int64) (*repository.Contest, error) {
 }
 
 func (s ServiceImpl) CreateContest(contest repository.Contest) (*repository.Contest, error) {
+	//раньше конкурс сразу становился активным, поэтому без явного статуса сразу планируем его
+	switch contest.Status {
+	case "":
+		contest.Status = repository.StatusScheduled
+	case repository.StatusDraft, repository.StatusScheduled:
+	default:
+		return nil, fmt.Errorf("%w: contest can't be created as %s", ErrTransitionNotAllowed, contest.Status)
+	}
+	if contest.Status == repository.StatusScheduled {
+		now := time.Now()
+		contest.ScheduledAt = &now
+	}
+	if err := s.drawBankQuestions(&contest); err != nil {
+		return nil, err
+	}
 	createdContest, err := s.repo.CreateContest(contest)
 	if err != nil {
 		return nil, err
@@ -215,21 +338,24 @@ func (s ServiceImpl) CreateContest(contest repository.Contest) (*repository.Cont
 	return createdContest, nil
 }
 
-func (s ServiceImpl) UpdateContest(contest repository.Contest) (*repository.Contest, error) {
-	updatedContest, err := s.repo.UpdateContest(contest)
+// UpdateContest статус и время переходов не меняются, они меняются только через переходы жизненного цикла
+func (s ServiceImpl) UpdateContest(contest repository.Contest, force bool) (*repository.Contest, error) {
+	stored, err := s.repo.GetContest(contest.ID)
 	if err != nil {
 		return nil, err
 	}
-	return updatedContest, nil
-}
-
-func (s ServiceImpl) ChangeStatus(contestID int64) (newStatus bool, err error) {
-	contest, err := s.repo.GetContestInfo(contestID)
+	updatedContest, regraded, err := s.repo.UpdateContest(contest, force)
 	if err != nil {
-		return
+		return nil, err
 	}
-	*contest.Active = !*contest.Active
-	return *contest.Active, s.repo.ChangeContestInfo(contest)
+	s.removePhotoObjects(removedPhotos(stored, updatedContest))
+	//правка пересчитала ответы законченного конкурса, итоговая таблица фиксируется заново
+	if regraded && (stored.Status == repository.StatusFinished || stored.Status == repository.StatusArchived) {
+		if err = s.finalizeResults(contest.ID); err != nil {
+			return nil, err
+		}
+	}
+	return updatedContest, nil
 }
 
 func (s ServiceImpl) DeleteContest(contestID int64) (err error) {
@@ -237,7 +363,25 @@ func (s ServiceImpl) DeleteContest(contestID int64) (err error) {
 	if err != nil {
 		return
 	}
-	return s.repo.DeleteContest(*contest)
+	//возвраты фиксируются до удаления: участники конкурса в user_contests остаются, а сам конкурс нет
+	refund := refundable(contest)
+	if refund {
+		if err = s.repo.CreateRefunds(contestID, 0); err != nil {
+			return
+		}
+	}
+	if err = s.repo.DeleteContest(*contest); err != nil {
+		return
+	}
+	s.removePhotoObjects(contestPhotos(contest))
+	if refund {
+		go func() {
+			if _, err := s.processRefunds(contestID); err != nil {
+				goerrors.Log().WithError(err).Warnf("refund deleted contest %d", contestID)
+			}
+		}()
+	}
+	return nil
 }
 
 func (s ServiceImpl) Migrate() error {
@@ -245,38 +389,61 @@ func (s ServiceImpl) Migrate() error {
 }
 
 func (s ServiceImpl) SubmitAnswer(userAnswer *repository.UserAnswers) (err error) {
+	question, err := s.repo.GetQuestion(userAnswer.QuestionID)
+	if err != nil {
+		return fmt.Errorf("GetQuestion err: %w", err)
+	}
+	if question.ContestID != userAnswer.ContestID {
+		return fmt.Errorf("question %d is not from contest %d", question.ID, userAnswer.ContestID)
+	}
+	contest, err := s.repo.GetContestInfo(userAnswer.ContestID)
+	if err != nil {
+		return fmt.Errorf("GetContestInfo err: %w", err)
+	}
+	if contest.Elimination() {
+		if err = s.checkElimination(contest.ID, userAnswer.UserID, question.ID); err != nil {
+			return err
+		}
+	}
+	if question.Kind() == repository.QuestionSingle && userAnswer.AnswerID == 0 && len(userAnswer.AnswerIDs) == 1 {
+		userAnswer.AnswerID = userAnswer.AnswerIDs[0]
+		userAnswer.AnswerIDs = nil
+	}
+	userAnswer.Credit = question.Grade(userAnswer)
+	if contest.Teams {
+		return s.submitTeamAnswer(contest, userAnswer)
+	}
 	return s.repo.SubmitAnswer(userAnswer)
 }
 
+// SubscribeContest место занимается до списания денег, поэтому списание без места невозможно:
+// подписка создается в pending, после ответа API баланса подтверждается или удаляется,
+// а если ответа не было, ее судьбу решает сверка (reconcilePayments). Место без оплаты сразу оплачено
 func (s ServiceImpl) SubscribeContest(userContest *repository.UserContests, jwtToken string) error {
-	var (
-		header map[string]string
-		res    interface{}
-		err    error
-	)
-
-	header = map[string]string{"Authorization": jwtToken}
-
 	contest, err := s.repo.ContestAvailability(userContest.ContestID, userContest.UserID)
 	if err != nil {
 		return err
 	}
-	req := struct {
-		Amount float64 `json:"amount"`
-	}{contest.Price}
-
-	body, err := json.Marshal(&req)
-	if err != nil {
+	//купить попытку, которую уже нельзя начать, бессмысленно
+	if contest.SelfPaced() && !time.Now().Before(contest.AttemptsUntil()) {
+		return ErrAttemptWindowClosed
+	}
+	if err = s.checkTeamChoice(contest, userContest); err != nil {
 		return err
 	}
-	userContest.Price = contest.Price
-	goerrors.Log().Info("contest:", contest)
-	if err = s.SendRequest("POST", bytes.NewBuffer(body), &res, &header); err != nil {
+	if err = s.applyDiscounts(contest, userContest); err != nil {
 		return err
 	}
-	err = s.repo.SubscribeContest(userContest)
-	if err != nil {
+	userContest.PaymentStatus = repository.PaymentPending
+	if userContest.Price == 0 {
+		userContest.PaymentStatus = repository.PaymentPaid
+	}
+	userContest.PaymentKey = fmt.Sprintf("contest-%d-subscribe-%d-%d", contest.ID, userContest.UserID, time.Now().UnixNano())
+	if err = s.repo.SubscribeContest(userContest); err != nil {
 		return err
 	}
-	return nil
+	if userContest.PaymentStatus == repository.PaymentPaid {
+		return nil
+	}
+	return s.paySubscription(userContest, jwtToken)
 }
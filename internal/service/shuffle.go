@@ -0,0 +1,66 @@
+package service
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+
+	"github.com/dwnGnL/pg-contests/internal/api/models"
+	"github.com/dwnGnL/pg-contests/internal/repository"
+)
+
+// PersonalizeResponse варианты ответов в порядке конкретного участника, если конкурс их перемешивает,
+// и выбыл ли он из конкурса на выбывание. resp общий для всех подписчиков, поэтому вопросы копируются
+func PersonalizeResponse(resp models.WsResponse, contestID, userID int64) models.WsResponse {
+	if resp.EliminatedIDs != nil {
+		i := sort.Search(len(resp.EliminatedIDs), func(i int) bool { return resp.EliminatedIDs[i] >= userID })
+		resp.Eliminated = i < len(resp.EliminatedIDs) && resp.EliminatedIDs[i] == userID
+		resp.EliminatedIDs = nil
+	}
+	if !resp.ShuffleAnswers || len(resp.Questions) == 0 {
+		return resp
+	}
+	questions := make([]models.WsQuestion, len(resp.Questions))
+	for i, question := range resp.Questions {
+		question.Answers = shuffleByID(question.Answers, func(a models.WsAnswer) int64 { return a.ID },
+			shuffleSeed(contestID, userID, question.ID))
+		questions[i] = question
+	}
+	resp.Questions = questions
+	return resp
+}
+
+// shuffleContestAnswers тот же порядок вариантов, что участник видел во время конкурса, для страницы результатов
+func shuffleContestAnswers(contest *repository.Contest, userID int64) {
+	if !contest.ShuffleAnswers {
+		return
+	}
+	for i, question := range contest.Questions {
+		contest.Questions[i].Answers = shuffleByID(question.Answers, func(a repository.Answer) int64 { return a.ID },
+			shuffleSeed(contest.ID, userID, question.ID))
+	}
+}
+
+// shuffleByID детерминированная перестановка: одна и та же для участника при переподключениях и на всех инстансах.
+// Исходный порядок - по возрастанию id, чтобы порядок из базы не влиял на результат. items не меняется
+func shuffleByID[T any](items []T, id func(T) int64, seed int64) []T {
+	shuffled := make([]T, len(items))
+	copy(shuffled, items)
+	sort.Slice(shuffled, func(i, j int) bool { return id(shuffled[i]) < id(shuffled[j]) })
+	random := rand.New(rand.NewSource(seed))
+	random.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	return shuffled
+}
+
+func shuffleSeed(ids ...int64) int64 {
+	hash := fnv.New64a()
+	buf := make([]byte, 8)
+	for _, id := range ids {
+		binary.LittleEndian.PutUint64(buf, uint64(id))
+		hash.Write(buf)
+	}
+	return int64(hash.Sum64())
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/dwnGnL/pg-contests/internal/api/models"
+	"github.com/dwnGnL/pg-contests/internal/repository"
+)
+
+func answerIDs(answers []models.WsAnswer) []int64 {
+	ids := make([]int64, 0, len(answers))
+	for _, answer := range answers {
+		ids = append(ids, answer.ID)
+	}
+	return ids
+}
+
+func testResponse(ids ...int64) models.WsResponse {
+	question := models.WsQuestion{ID: 5}
+	for _, id := range ids {
+		question.Answers = append(question.Answers, models.WsAnswer{ID: id})
+	}
+	return models.WsResponse{ShuffleAnswers: true, Questions: []models.WsQuestion{question}}
+}
+
+func TestPersonalizeResponseSameOrderOnReconnect(t *testing.T) {
+	resp := testResponse(1, 2, 3, 4, 5, 6, 7, 8)
+	first := answerIDs(PersonalizeResponse(resp, 1, 42).Questions[0].Answers)
+	//переподключение, другой инстанс и другой порядок из базы дают тот же порядок
+	again := answerIDs(PersonalizeResponse(resp, 1, 42).Questions[0].Answers)
+	reordered := answerIDs(PersonalizeResponse(testResponse(8, 3, 5, 1, 7, 2, 6, 4), 1, 42).Questions[0].Answers)
+	if !reflect.DeepEqual(first, again) || !reflect.DeepEqual(first, reordered) {
+		t.Fatalf("order changed between calls: %v, %v, %v", first, again, reordered)
+	}
+	if got := answerIDs(resp.Questions[0].Answers); !reflect.DeepEqual(got, []int64{1, 2, 3, 4, 5, 6, 7, 8}) {
+		t.Fatalf("shared response was modified: %v", got)
+	}
+}
+
+func TestPersonalizeResponseDiffersPerUser(t *testing.T) {
+	resp := testResponse(1, 2, 3, 4, 5, 6, 7, 8)
+	orders := make(map[string]bool)
+	for userID := int64(1); userID <= 10; userID++ {
+		orders[fmt.Sprint(answerIDs(PersonalizeResponse(resp, 1, userID).Questions[0].Answers))] = true
+	}
+	//8! перестановок: у десяти участников порядки почти наверняка разные, а сиды детерминированы
+	if len(orders) < 9 {
+		t.Fatalf("only %d distinct orders for 10 users", len(orders))
+	}
+	other := answerIDs(PersonalizeResponse(resp, 2, 1).Questions[0].Answers)
+	if reflect.DeepEqual(other, answerIDs(PersonalizeResponse(resp, 1, 1).Questions[0].Answers)) {
+		t.Fatalf("same order in different contests: %v", other)
+	}
+}
+
+func TestPersonalizeResponseWithoutShuffle(t *testing.T) {
+	resp := testResponse(3, 1, 2)
+	resp.ShuffleAnswers = false
+	if got := answerIDs(PersonalizeResponse(resp, 1, 42).Questions[0].Answers); !reflect.DeepEqual(got, []int64{3, 1, 2}) {
+		t.Fatalf("answers reordered without shuffle_answers: %v", got)
+	}
+}
+
+func TestShuffleContestAnswersMatchesLiveOrder(t *testing.T) {
+	contest := repository.Contest{ID: 1, ShuffleAnswers: true, Questions: []repository.Question{{ID: 5}}}
+	for _, id := range []int64{4, 2, 8, 6, 1, 3, 7, 5} {
+		contest.Questions[0].Answers = append(contest.Questions[0].Answers, repository.Answer{ID: id})
+	}
+	shuffleContestAnswers(&contest, 42)
+	var got []int64
+	for _, answer := range contest.Questions[0].Answers {
+		got = append(got, answer.ID)
+	}
+	want := answerIDs(PersonalizeResponse(testResponse(1, 2, 3, 4, 5, 6, 7, 8), 1, 42).Questions[0].Answers)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("results page order %v, live order %v", got, want)
+	}
+}
+
+func TestShuffleByIDQuestions(t *testing.T) {
+	questions := []repository.Question{{ID: 3}, {ID: 1}, {ID: 2}, {ID: 5}, {ID: 4}}
+	id := func(q repository.Question) int64 { return q.ID }
+	first := shuffleByID(questions, id, shuffleSeed(1, 42))
+	second := shuffleByID([]repository.Question{{ID: 5}, {ID: 4}, {ID: 3}, {ID: 2}, {ID: 1}}, id, shuffleSeed(1, 42))
+	if !reflect.DeepEqual(first, second) {
+		t.Fatalf("question order depends on input order: %v, %v", first, second)
+	}
+	if questions[0].ID != 3 || len(first) != len(questions) {
+		t.Fatalf("input was modified or items lost: %v, %v", questions, first)
+	}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dwnGnL/pg-contests/internal/payment"
+	"github.com/dwnGnL/pg-contests/internal/repository"
+	"github.com/dwnGnL/pg-contests/lib/goerrors"
+)
+
+var ErrPaymentPending = errors.New("payment is not confirmed yet, the subscription will be resolved shortly")
+
+const (
+	defaultReconcileInterval = time.Minute
+	defaultPendingTimeout    = 5 * time.Minute
+)
+
+// paySubscription списание за уже занятое место. Отказ провайдера - место освобождается;
+// провайдер недоступен - подписка остается pending: деньги могли списаться, решит сверка
+func (s ServiceImpl) paySubscription(userContest *repository.UserContests, jwtToken string) error {
+	payErr := s.payments.Withdraw(context.Background(), userContest.UserID, jwtToken, userContest.Price, userContest.PaymentKey)
+	if errors.Is(payErr, payment.ErrUnavailable) {
+		goerrors.Log().WithError(payErr).Warnf("contest %d subscription of user %d left pending", userContest.ContestID, userContest.UserID)
+		return fmt.Errorf("subscription stays pending until the payment is reconciled: %w", payErr)
+	}
+	if payErr != nil {
+		if _, err := s.repo.CancelSubscription(userContest.ContestID, userContest.UserID, userContest.PaymentKey); err != nil {
+			goerrors.Log().WithError(err).Warnf("cancel contest %d subscription of user %d", userContest.ContestID, userContest.UserID)
+		}
+		return payErr
+	}
+	if _, err := s.repo.ConfirmSubscription(userContest.ContestID, userContest.UserID, userContest.PaymentKey); err != nil {
+		goerrors.Log().WithError(err).Warnf("confirm contest %d subscription of user %d", userContest.ContestID, userContest.UserID)
+		return fmt.Errorf("%w: %v", ErrPaymentPending, err)
+	}
+	userContest.PaymentStatus = repository.PaymentPaid
+	return nil
+}
+
+// StartReconciler сверка зависших подписок с провайдером оплаты
+func (s ServiceImpl) StartReconciler(ctx context.Context) {
+	interval := s.conf.Payments.ReconcileInterval
+	if interval <= 0 {
+		interval = defaultReconcileInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s.reconcilePayments()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// reconcilePayments подписка, по которой списание прошло, подтверждается, по которой не прошло - удаляется.
+// Таймаут больше времени всех повторов списания, так что запрос по зависшей подписке уже не в пути
+func (s ServiceImpl) reconcilePayments() {
+	timeout := s.conf.Payments.PendingTimeout
+	if timeout <= 0 {
+		timeout = defaultPendingTimeout
+	}
+	pending, err := s.repo.GetPendingSubscriptions(time.Now().Add(-timeout))
+	if err != nil {
+		goerrors.Log().WithError(err).Warn("reconcile: get pending subscriptions")
+		return
+	}
+	for _, userContest := range pending {
+		status, err := s.payments.Status(context.Background(), userContest.PaymentKey)
+		if err != nil {
+			goerrors.Log().WithError(err).Warnf("reconcile contest %d subscription of user %d", userContest.ContestID, userContest.UserID)
+			continue
+		}
+		switch status {
+		case payment.StatusPaid:
+			_, err = s.repo.ConfirmSubscription(userContest.ContestID, userContest.UserID, userContest.PaymentKey)
+		case payment.StatusFailed, payment.StatusNotFound:
+			_, err = s.repo.CancelSubscription(userContest.ContestID, userContest.UserID, userContest.PaymentKey)
+		default:
+			err = fmt.Errorf("unknown payment status %q", status)
+		}
+		if err != nil {
+			goerrors.Log().WithError(err).Warnf("reconcile contest %d subscription of user %d", userContest.ContestID, userContest.UserID)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"sort"
 	"time"
 
@@ -9,103 +11,229 @@ import (
 	"github.com/dwnGnL/pg-contests/lib/goerrors"
 )
 
-func (s ServiceImpl) GenerateAndProcessChan(contestID int64) <-chan models.WsResponse {
-	ch := make(chan models.WsResponse)
-	go s.chanWorker(ch, contestID)
-	return ch
+const leaderRetryInterval = 5 * time.Second
+
+// SubscribeContestEvents подписывает на события конкурса со всех инстансов, таймлайн ведет планировщик
+func (s ServiceImpl) SubscribeContestEvents(contestID int64) (<-chan models.WsResponse, func()) {
+	return s.bus.Subscribe(contestID)
+}
+
+// driveContest ведет таймлайн конкурса, если удалось стать лидером, иначе ждет пока лидер не освободится
+func (s ServiceImpl) driveContest(ctx context.Context, contestID int64) {
+	defer s.drivers.Delete(contestID)
+	for {
+		release, ok, err := s.bus.Lead(ctx, contestID)
+		if err != nil {
+			goerrors.Log().WithError(err).Warnf("lead contest %d", contestID)
+		}
+		if ok {
+			s.chanWorker(ctx, contestID)
+			release()
+			return
+		}
+		//таймлайн ведет другой инстанс, следим чтобы подхватить если он упадет
+		if status := s.Generate(contestID).ContestStatus; status == models.End || status == 0 {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(leaderRetryInterval):
+		}
+	}
 }
+
 func (s ServiceImpl) Generate(contestID int64) models.WsResponse {
 	contest, err := s.repo.GetContest(contestID)
 	if err != nil {
 		goerrors.Log().Warnln("err on GetContest ", err)
 		return models.WsResponse{}
 	}
-	var resp models.WsResponse
-	resp.TotalStep = len(contest.Questions)
+	var events []repository.HostEvent
+	if !contest.SelfPaced() {
+		if events, err = s.repo.GetHostEvents(contestID); err != nil {
+			goerrors.Log().WithError(err).Warnf("contest %d host events", contestID)
+			return models.WsResponse{}
+		}
+	}
+	resp := contestTimeline(contest, events)
+	if contest.Elimination() && resp.ContestStatus != 0 && resp.ContestStatus != models.Waiting {
+		if err = s.applyElimination(contest, &resp); err != nil {
+			goerrors.Log().WithError(err).Warnf("contest %d eliminations", contestID)
+		}
+	}
+	return resp
+}
 
-	layout := "2006-01-02T15:04Z07:00"
-	startTime, err := time.Parse(layout, contest.StartTime)
-	if err != nil {
-		goerrors.Log().Warnln("err on time.Parse ", err)
-		return models.WsResponse{}
+// contestTimeline общий таймлайн конкурса с действиями ведущего events, по нему планировщик переводит конкурс в live и finished
+func contestTimeline(contest *repository.Contest, events []repository.HostEvent) models.WsResponse {
+	var resp models.WsResponse
+	if contest.SelfPaced() {
+		resp = generateWindow(contest)
+	} else {
+		now := time.Now()
+		resp = generateTimeline(replayTimeline(sortedQuestions(contest), contest.StartTime, events, now), contest.StartTime, now)
 	}
-	startTimeUnix := startTime.Unix()
-	now := time.Now().Unix()
-	remained := now - startTimeUnix
-	if remained < 0 {
+	resp.ShuffleAnswers = contest.ShuffleAnswers
+	//досрочно завершенный или отмененный конкурс больше не идет по таймлайну
+	if contest.Status.Closed() && resp.ContestStatus != 0 {
+		resp.ContestStatus = models.End
+	}
+	return resp
+}
+
+// generateTimeline состояние таймлайна slots, начавшегося в origin, на момент now
+func generateTimeline(slots []slot, origin, now time.Time) models.WsResponse {
+	var resp models.WsResponse
+	resp.TotalStep = len(slots)
+
+	startTimeUnix := origin.Unix()
+	nowUnix := now.Unix()
+	if nowUnix < startTimeUnix {
 		resp.TotalTime = startTimeUnix
-		resp.CountDown = (remained) * -1
+		resp.CountDown = startTimeUnix - nowUnix
 		resp.ContestStatus = models.Waiting
 		return resp
 	}
-	sort.Slice(contest.Questions, func(i, j int) bool {
-		return contest.Questions[i].Order < contest.Questions[j].Order
-	})
-	var totalTime int64
-	for i, v := range contest.Questions {
-		totalTime += v.Time
-		if now-totalTime >= startTimeUnix {
-			resp.Questions = append(resp.Questions, convertRepQToWsQ(contest.Questions[i]))
-			continue
-		}
-		break
-	}
 
-	countPassed := len(resp.Questions)
-	if countPassed != len(contest.Questions) {
-		resp.Questions = append(resp.Questions, convertRepQToWsQ(contest.Questions[countPassed]))
-		resp.ActiveQuestionID = contest.Questions[countPassed].ID
-		resp.Step = countPassed + 1
-		resp.TotalTime = contest.Questions[countPassed].Time
+	countPassed := passedSlots(slots, now)
+	for _, passed := range slots[:countPassed] {
+		resp.Questions = append(resp.Questions, convertRepQToWsQ(passed.Question))
 	}
 
 	resp.ContestStatus = models.Start
-	resp.CountDown = (totalTime + startTimeUnix) - now
-	resp.TotalStep = len(contest.Questions)
-	if countPassed == resp.TotalStep && resp.CountDown <= 0 {
+	if countPassed == len(slots) {
 		resp.ContestStatus = models.End
+		if countPassed != 0 {
+			resp.CountDown = slots[countPassed-1].End - nowUnix
+		}
+		return resp
 	}
+	current := slots[countPassed]
+	resp.Questions = append(resp.Questions, convertRepQToWsQ(current.Question))
+	resp.ActiveQuestionID = current.Question.ID
+	resp.Step = countPassed + 1
+	resp.TotalTime = current.Question.Time + current.Extended
+	resp.Paused = current.Paused
+	resp.CountDown = current.End - nowUnix
 	if resp.CountDown == 0 {
 		resp.CountDown++
 	}
-	if resp.CountDown < 0 {
-		resp.ContestStatus = models.End
-	}
 	return resp
 }
 
-func (s ServiceImpl) chanWorker(ch chan<- models.WsResponse, contestID int64) {
+func (s ServiceImpl) chanWorker(ctx context.Context, contestID int64) {
+	events, unsubscribe := s.bus.Subscribe(contestID)
+	defer unsubscribe()
+	started := false
 	for {
 		resp := s.Generate(contestID)
-		truePointer := true
+		//конкурс не найден или сломан, Generate уже залогировал причину
+		if resp.ContestStatus == 0 {
+			return
+		}
+		if resp.ContestStatus != models.Waiting && !started {
+			started = true
+			s.advanceContest(contestID, repository.StatusScheduled, repository.StatusLive)
+		}
+		if err := s.bus.Publish(ctx, contestID, resp); err != nil {
+			goerrors.Log().WithError(err).Warnf("publish contest %d event", contestID)
+		}
 		if resp.ContestStatus == models.End {
-			s.repo.ChangeContestInfo(&repository.Contest{ID: contestID, IsEnd: &truePointer})
-			ch <- resp
-			close(ch)
+			s.advanceContest(contestID, repository.StatusLive, repository.StatusFinished)
+			return
+		}
+		//на паузе таймлайн стоит до следующего действия ведущего
+		var timeout <-chan time.Time
+		if !resp.Paused {
+			timeout = time.After(time.Duration(resp.CountDown) * time.Second)
+		}
+		if !s.waitHost(ctx, events, timeout) {
 			return
 		}
-		ch <- resp
-		time.Sleep(time.Duration(resp.CountDown) * time.Second)
+	}
+}
 
+// waitHost ждет timeout или действие ведущего, после которого таймлайн надо пересчитать раньше; false - остановиться
+func (s ServiceImpl) waitHost(ctx context.Context, events <-chan models.WsResponse, timeout <-chan time.Time) bool {
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-timeout:
+			return true
+		case resp, ok := <-events:
+			if !ok {
+				return false
+			}
+			if resp.HostAction != "" {
+				return true
+			}
+		}
 	}
+}
 
+// advanceContest переход по таймлайну; если статус уже сменился (рестарт, отмена админом) - ничего не делаем
+func (s ServiceImpl) advanceContest(contestID int64, from, to repository.ContestStatus) {
+	err := s.repo.UpdateContestStatus(contestID, from, to, time.Now())
+	if err != nil {
+		if !errors.Is(err, repository.ErrStatusChanged) {
+			goerrors.Log().WithError(err).Warnf("move contest %d from %s to %s", contestID, from, to)
+		}
+		return
+	}
+	if to == repository.StatusFinished {
+		s.onContestFinished(contestID)
+	}
 }
 
 func convertRepQToWsQ(question repository.Question) models.WsQuestion {
-	return models.WsQuestion{
-		ID:      question.ID,
-		Order:   question.Order,
-		Title:   question.Title,
-		Answers: convertRepAToWsA(question.Answers),
+	wsQuestion := models.WsQuestion{
+		ID:     question.ID,
+		Order:  question.Order,
+		Type:   string(question.Kind()),
+		Title:  question.Title,
+		Photos: convertRepPhotoToWsPhoto(question.Photos),
+		Voided: question.Voided,
 	}
+	if question.HidesAnswers() {
+		return wsQuestion
+	}
+	answers := question.Answers
+	if question.Kind() == repository.QuestionOrdering {
+		//авторы обычно вводят варианты уже в правильном порядке
+		answers = make([]repository.Answer, len(question.Answers))
+		copy(answers, question.Answers)
+		sort.Slice(answers, func(i, j int) bool {
+			return answers[i].Title < answers[j].Title
+		})
+	}
+	wsQuestion.Answers = convertRepAToWsA(answers)
+	return wsQuestion
 }
 func convertRepAToWsA(answer []repository.Answer) []models.WsAnswer {
 	var wsAnswer []models.WsAnswer
 	for _, v := range answer {
 		wsAnswer = append(wsAnswer, models.WsAnswer{
-			ID:    v.ID,
-			Title: v.Title,
+			ID:     v.ID,
+			Title:  v.Title,
+			Photos: convertRepPhotoToWsPhoto(v.Photos),
 		})
 	}
 	return wsAnswer
 }
+
+// convertRepPhotoToWsPhoto участникам отдаем только загруженные фото
+func convertRepPhotoToWsPhoto(photos []repository.Photo) []models.WsPhoto {
+	var wsPhotos []models.WsPhoto
+	for _, photo := range photos {
+		if photo.Link == "" || photo.Pending() {
+			continue
+		}
+		wsPhotos = append(wsPhotos, models.WsPhoto{
+			Link:          photo.Link,
+			ThumbnailLink: photo.ThumbnailLink,
+		})
+	}
+	return wsPhotos
+}
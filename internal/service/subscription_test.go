@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dwnGnL/pg-contests/internal/config"
+	"github.com/dwnGnL/pg-contests/internal/payment"
+	"github.com/dwnGnL/pg-contests/internal/repository"
+)
+
+// subscriptionRepo подписки в памяти, остальные методы репозитория тестам не нужны
+type subscriptionRepo struct {
+	repositoryIter
+	seats map[string]*repository.UserContests // по ключу списания
+}
+
+func (r *subscriptionRepo) ConfirmSubscription(_, _ int64, paymentKey string) (bool, error) {
+	seat, ok := r.seats[paymentKey]
+	if !ok || seat.PaymentStatus != repository.PaymentPending {
+		return false, nil
+	}
+	seat.PaymentStatus = repository.PaymentPaid
+	return true, nil
+}
+
+func (r *subscriptionRepo) CancelSubscription(_, _ int64, paymentKey string) (bool, error) {
+	seat, ok := r.seats[paymentKey]
+	if !ok || seat.PaymentStatus != repository.PaymentPending {
+		return false, nil
+	}
+	delete(r.seats, paymentKey)
+	return true, nil
+}
+
+func (r *subscriptionRepo) GetPendingSubscriptions(time.Time) ([]repository.UserContests, error) {
+	var pending []repository.UserContests
+	for _, seat := range r.seats {
+		if seat.PaymentStatus == repository.PaymentPending {
+			pending = append(pending, *seat)
+		}
+	}
+	return pending, nil
+}
+
+// unavailableProvider списание не доходит до провайдера или доходит, но ответ теряется
+type unavailableProvider struct {
+	*payment.Fake
+	charged bool
+}
+
+func (p *unavailableProvider) Withdraw(ctx context.Context, userID int64, authorization string, amount float64, key string) error {
+	if p.charged {
+		if err := p.Fake.Withdraw(ctx, userID, authorization, amount, key); err != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("withdraw balance err: %w", payment.ErrUnavailable)
+}
+
+func newSubscriptionService(provider payment.Provider) (ServiceImpl, *subscriptionRepo, *repository.UserContests) {
+	seat := &repository.UserContests{
+		UserID:        1,
+		ContestID:     2,
+		Price:         10,
+		PaymentStatus: repository.PaymentPending,
+		PaymentKey:    "contest-2-subscribe-1-1",
+	}
+	repo := &subscriptionRepo{seats: map[string]*repository.UserContests{seat.PaymentKey: seat}}
+	s := New(&config.Config{}, repo, WithPayments(provider))
+	return *s, repo, seat
+}
+
+func TestPaySubscriptionConfirms(t *testing.T) {
+	fake := payment.NewFake(100)
+	s, repo, seat := newSubscriptionService(fake)
+	if err := s.paySubscription(seat, "token"); err != nil {
+		t.Fatal(err)
+	}
+	if repo.seats[seat.PaymentKey].PaymentStatus != repository.PaymentPaid {
+		t.Errorf("seat is %q, want paid", seat.PaymentStatus)
+	}
+	//повтор того же списания (двойной клик, повтор запроса) второй раз не списывает
+	if err := fake.Withdraw(context.Background(), 1, "token", 10, seat.PaymentKey); err != nil {
+		t.Fatal(err)
+	}
+	if fake.Balance(1) != 90 {
+		t.Errorf("balance = %v, want 90", fake.Balance(1))
+	}
+}
+
+func TestPaySubscriptionInsufficientFunds(t *testing.T) {
+	s, repo, seat := newSubscriptionService(payment.NewFake(5))
+	err := s.paySubscription(seat, "token")
+	if !errors.Is(err, payment.ErrInsufficientFunds) {
+		t.Fatalf("err = %v, want ErrInsufficientFunds", err)
+	}
+	if _, ok := repo.seats[seat.PaymentKey]; ok {
+		t.Error("seat must be released after a declined payment")
+	}
+}
+
+func TestUnavailableProviderIsReconciled(t *testing.T) {
+	tests := []struct {
+		name     string
+		charged  bool
+		wantSeat bool
+	}{
+		{"charged but the response was lost", true, true},
+		{"request never reached the provider", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &unavailableProvider{Fake: payment.NewFake(100), charged: tt.charged}
+			s, repo, seat := newSubscriptionService(provider)
+
+			err := s.paySubscription(seat, "token")
+			if !errors.Is(err, payment.ErrUnavailable) {
+				t.Fatalf("err = %v, want ErrUnavailable", err)
+			}
+			if repo.seats[seat.PaymentKey].PaymentStatus != repository.PaymentPending {
+				t.Fatal("seat must stay pending while the payment is unknown")
+			}
+
+			s.reconcilePayments()
+			got, ok := repo.seats[seat.PaymentKey]
+			if ok != tt.wantSeat {
+				t.Fatalf("seat kept = %v, want %v", ok, tt.wantSeat)
+			}
+			if ok && got.PaymentStatus != repository.PaymentPaid {
+				t.Errorf("seat is %q, want paid", got.PaymentStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/dwnGnL/pg-contests/internal/api/models"
+	"github.com/dwnGnL/pg-contests/internal/repository"
+	"github.com/dwnGnL/pg-contests/internal/scoring"
+	"github.com/dwnGnL/pg-contests/lib/goerrors"
+)
+
+var (
+	ErrTeamRequired = errors.New("choose a team to join or a name for a new one")
+	ErrNoTeam       = errors.New("player has no team in this contest")
+	ErrNotCaptain   = errors.New("only the team captain can answer")
+)
+
+func (s ServiceImpl) GetContestTeams(contestID int64) ([]repository.Team, error) {
+	return s.repo.GetContestTeams(contestID)
+}
+
+// checkTeamChoice проверка до оплаты; место в команде окончательно проверяется при записи подписки
+func (s ServiceImpl) checkTeamChoice(contest *repository.Contest, userContest *repository.UserContests) error {
+	if !contest.Teams {
+		userContest.TeamID, userContest.TeamName = nil, ""
+		return nil
+	}
+	userContest.TeamName = strings.TrimSpace(userContest.TeamName)
+	if (userContest.TeamID == nil) == (userContest.TeamName == "") {
+		return ErrTeamRequired
+	}
+	if userContest.TeamID == nil {
+		return nil
+	}
+	team, err := s.repo.GetTeam(*userContest.TeamID)
+	if err != nil {
+		return err
+	}
+	if team.ContestID != contest.ID {
+		return repository.ErrTeamNotFound
+	}
+	if contest.TeamSize > 0 && len(team.Members) >= contest.TeamSize {
+		return repository.ErrTeamFull
+	}
+	return nil
+}
+
+// userTeamID команда участника, nil - если конкурс не командный
+func (s ServiceImpl) userTeamID(contestID, userID int64) (*int64, error) {
+	userContest, err := s.repo.GetUserContest(contestID, userID)
+	if err != nil || userContest == nil {
+		return nil, err
+	}
+	return userContest.TeamID, nil
+}
+
+// submitTeamAnswer ответ команды один на вопрос: новый ответ любого участника (или только капитана) заменяет прежний
+func (s ServiceImpl) submitTeamAnswer(contest *repository.Contest, userAnswer *repository.UserAnswers) error {
+	userContest, err := s.repo.GetUserContest(contest.ID, userAnswer.UserID)
+	if err != nil {
+		return err
+	}
+	if userContest == nil || userContest.TeamID == nil {
+		return ErrNoTeam
+	}
+	team, err := s.repo.GetTeam(*userContest.TeamID)
+	if err != nil {
+		return err
+	}
+	if contest.CaptainAnswers && team.CaptainID != userAnswer.UserID {
+		return ErrNotCaptain
+	}
+	if err = s.repo.SubmitTeamAnswer(userAnswer, team.ID); err != nil {
+		return err
+	}
+
+	//сокомандники видят ответ команды сразу, не дожидаясь закрытия вопроса
+	event := models.WsResponse{TeamAnswer: &models.WsTeamAnswer{
+		TeamID:     team.ID,
+		QuestionID: userAnswer.QuestionID,
+		UserID:     userAnswer.UserID,
+		UserName:   userContest.UserName,
+		AnswerID:   userAnswer.AnswerID,
+		AnswerIDs:  userAnswer.AnswerIDs,
+		Value:      userAnswer.Value,
+		Members:    team.MemberIDs(),
+	}}
+	if err = s.bus.Publish(context.Background(), contest.ID, event); err != nil {
+		goerrors.Log().WithError(err).Warnf("publish contest %d team answer", contest.ID)
+	}
+	return nil
+}
+
+// teamLeaderboard таблица по командам: ответ команды на вопрос - ответ любого ее участника
+func (s ServiceImpl) teamLeaderboard(contest *repository.Contest, participants []repository.UserContests,
+	byUser map[int64][]repository.UserAnswers) ([]repository.ContestStats, error) {
+	teams, err := s.repo.GetContestTeams(contest.ID)
+	if err != nil {
+		return nil, err
+	}
+	byTeam := make(map[int64][]repository.UserAnswers, len(teams))
+	for _, participant := range participants {
+		if participant.TeamID != nil {
+			byTeam[*participant.TeamID] = append(byTeam[*participant.TeamID], byUser[participant.UserID]...)
+		}
+	}
+
+	questions := sortedQuestions(contest)
+	names := make(map[int64]string, len(teams))
+	entries := make([]scoring.Entry, 0, len(teams))
+	for _, team := range teams {
+		names[team.ID] = team.Name
+		entries = append(entries, scoring.Entry{
+			ID:     team.ID,
+			Result: contest.Scoring.Total(scoringAnswers(questions, byTeam[team.ID])),
+		})
+	}
+	scoring.Rank(entries)
+
+	stats := make([]repository.ContestStats, 0, len(entries))
+	for i, entry := range entries {
+		stats = append(stats, repository.ContestStats{
+			Rank:         int64(i + 1),
+			TeamID:       entry.ID,
+			TeamName:     names[entry.ID],
+			TotalScore:   entry.Result.Score,
+			TotalTime:    entry.Result.Time,
+			TotalCorrect: entry.Result.Correct,
+		})
+	}
+	return stats, nil
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dwnGnL/pg-contests/internal/config"
+	"github.com/dwnGnL/pg-contests/internal/repository"
+)
+
+// teamRepo команды конкурса и ответы команд в памяти
+type teamRepo struct {
+	repositoryIter
+	teams   []repository.Team
+	answers map[int64]repository.UserAnswers // по id команды
+}
+
+func (r *teamRepo) GetContestTeams(int64) ([]repository.Team, error) {
+	return r.teams, nil
+}
+
+func (r *teamRepo) GetTeam(teamID int64) (*repository.Team, error) {
+	for i := range r.teams {
+		if r.teams[i].ID == teamID {
+			return &r.teams[i], nil
+		}
+	}
+	return nil, repository.ErrTeamNotFound
+}
+
+func (r *teamRepo) GetUserContest(_, userID int64) (*repository.UserContests, error) {
+	for _, team := range r.teams {
+		for _, member := range team.Members {
+			if member.UserID == userID {
+				return &member, nil
+			}
+		}
+	}
+	return &repository.UserContests{UserID: userID}, nil
+}
+
+func (r *teamRepo) SubmitTeamAnswer(userAnswer *repository.UserAnswers, teamID int64) error {
+	r.answers[teamID] = *userAnswer
+	return nil
+}
+
+func testTeams() []repository.Team {
+	first, second := int64(1), int64(2)
+	return []repository.Team{
+		{ID: 1, ContestID: 1, Name: "Знатоки", CaptainID: 1, Members: []repository.UserContests{{UserID: 1, TeamID: &first}, {UserID: 2, TeamID: &first}}},
+		{ID: 2, ContestID: 1, Name: "Эрудиты", CaptainID: 3, Members: []repository.UserContests{{UserID: 3, TeamID: &second}}},
+		{ID: 3, ContestID: 1, Name: "Пустые", CaptainID: 4},
+	}
+}
+
+func TestTeamLeaderboard(t *testing.T) {
+	repo := &teamRepo{teams: testTeams()}
+	contest := repository.Contest{ID: 1, Teams: true, Questions: []repository.Question{
+		{ID: 1, Order: 1, Score: 10, Time: 10}, {ID: 2, Order: 2, Score: 10, Time: 10},
+	}}
+	var participants []repository.UserContests
+	for _, team := range repo.teams {
+		participants = append(participants, team.Members...)
+	}
+	//ответы команды складываются из ответов ее участников
+	byUser := map[int64][]repository.UserAnswers{
+		1: {{UserID: 1, QuestionID: 1, Credit: 1, Time: 3}},
+		2: {{UserID: 2, QuestionID: 2, Credit: 1, Time: 4}},
+		3: {{UserID: 3, QuestionID: 1, Credit: 1, Time: 1}, {UserID: 3, QuestionID: 2, Time: 2}},
+	}
+	stats, err := New(&config.Config{}, repo).teamLeaderboard(&contest, participants, byUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []repository.ContestStats{
+		{Rank: 1, TeamID: 1, TeamName: "Знатоки", TotalScore: 20, TotalTime: 7, TotalCorrect: 2},
+		{Rank: 2, TeamID: 2, TeamName: "Эрудиты", TotalScore: 10, TotalTime: 1, TotalCorrect: 1},
+		{Rank: 3, TeamID: 3, TeamName: "Пустые"},
+	}
+	if len(stats) != len(want) {
+		t.Fatalf("stats = %+v", stats)
+	}
+	for i := range want {
+		if stats[i] != want[i] {
+			t.Errorf("place %d = %+v, want %+v", i+1, stats[i], want[i])
+		}
+	}
+}
+
+func TestSubmitTeamAnswer(t *testing.T) {
+	tests := []struct {
+		name     string
+		captains bool
+		userID   int64
+		wantErr  error
+	}{
+		{name: "any member", userID: 2},
+		{name: "captain only", captains: true, userID: 1},
+		{name: "not a captain", captains: true, userID: 2, wantErr: ErrNotCaptain},
+		{name: "no team", userID: 9, wantErr: ErrNoTeam},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &teamRepo{teams: testTeams(), answers: map[int64]repository.UserAnswers{}}
+			contest := repository.Contest{ID: 1, Teams: true, CaptainAnswers: tt.captains}
+			err := New(&config.Config{}, repo).submitTeamAnswer(&contest, &repository.UserAnswers{UserID: tt.userID, QuestionID: 5, AnswerID: 7})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) || len(repo.answers) != 0 {
+					t.Fatalf("submitTeamAnswer = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if answer := repo.answers[1]; answer.UserID != tt.userID || answer.AnswerID != 7 {
+				t.Fatalf("team answer = %+v", answer)
+			}
+		})
+	}
+}
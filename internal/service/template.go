@@ -0,0 +1,146 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"path"
+	"time"
+
+	"github.com/dwnGnL/pg-contests/internal/repository"
+	"github.com/dwnGnL/pg-contests/lib/goerrors"
+)
+
+// DraftOptions новый черновик из копии конкурса или по шаблону
+type DraftOptions struct {
+	StartTime time.Time `json:"start_time" binding:"required"`
+	Title     string    `json:"title"` // пусто - название исходного конкурса или по шаблону
+	CreatedBy string    `json:"-"`
+}
+
+// CloneContest копия конкурса с вопросами, ответами и фото в новый черновик
+func (s ServiceImpl) CloneContest(ctx context.Context, contestID int64, opts DraftOptions) (*repository.Contest, error) {
+	stored, err := s.repo.GetContest(contestID)
+	if err != nil {
+		return nil, err
+	}
+	clone := stored.Clone()
+	clone.Status = repository.StatusDraft
+	clone.StartTime = opts.StartTime
+	clone.CreatedBy = opts.CreatedBy
+	if opts.Title != "" {
+		clone.Title = opts.Title
+	}
+	created, err := s.CreateContest(clone)
+	if err != nil {
+		return nil, err
+	}
+	s.copyPhotoObjects(ctx, stored, created)
+	return created, nil
+}
+
+func (s ServiceImpl) CreateTemplate(template repository.ContestTemplate) (*repository.ContestTemplate, error) {
+	template.Issued = 0
+	if err := s.repo.CreateTemplate(&template); err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (s ServiceImpl) GetTemplate(templateID int64) (*repository.ContestTemplate, error) {
+	return s.repo.GetTemplate(templateID)
+}
+
+func (s ServiceImpl) GetTemplates(pagination *repository.Pagination) (*repository.Pagination, error) {
+	return s.repo.GetTemplates(pagination)
+}
+
+func (s ServiceImpl) UpdateTemplate(template repository.ContestTemplate) (*repository.ContestTemplate, error) {
+	return s.repo.UpdateTemplate(template)
+}
+
+func (s ServiceImpl) DeleteTemplate(templateID int64) error {
+	return s.repo.DeleteTemplate(templateID)
+}
+
+// InstantiateTemplate черновик следующего выпуска: вопросы набираются из банка по слотам шаблона
+func (s ServiceImpl) InstantiateTemplate(templateID int64, opts DraftOptions) (*repository.Contest, error) {
+	template, err := s.repo.GetTemplate(templateID)
+	if err != nil {
+		return nil, err
+	}
+	contest := template.Contest(opts.StartTime)
+	contest.CreatedBy = opts.CreatedBy
+	if opts.Title != "" {
+		contest.Title = opts.Title
+	}
+	created, err := s.CreateContest(contest)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.repo.IncrementTemplateIssued(templateID); err != nil {
+		goerrors.Log().WithError(err).Warnf("increment template %d issued", templateID)
+	}
+	return created, nil
+}
+
+// copyPhotoObjects у копии свои файлы в хранилище, чтобы удаление исходного конкурса ее не задело.
+// Если скопировать не удалось, фото копии остается ссылкой на исходный файл
+func (s ServiceImpl) copyPhotoObjects(ctx context.Context, source, clone *repository.Contest) {
+	if s.storage == nil {
+		return
+	}
+	var sources []repository.Photo
+	for _, photo := range contestPhotos(source) {
+		if !photo.Pending() {
+			sources = append(sources, photo)
+		}
+	}
+	targets := contestPhotoRefs(clone)
+	if len(sources) != len(targets) {
+		goerrors.Log().Warnf("clone of contest %d has %d photos, source has %d", source.ID, len(targets), len(sources))
+		return
+	}
+	for i, photo := range sources {
+		if photo.StorageKey == "" {
+			continue
+		}
+		if err := s.copyPhotoObject(ctx, photo, targets[i]); err != nil {
+			goerrors.Log().WithError(err).Warnf("copy photo %d to clone %d", photo.ID, clone.ID)
+		}
+	}
+}
+
+func (s ServiceImpl) copyPhotoObject(ctx context.Context, source repository.Photo, target *repository.Photo) error {
+	key := newPhotoKey(target.OwnerType, target.OwnerID, path.Ext(source.StorageKey))
+	copied := repository.Photo{StorageKey: key}
+	for _, keys := range [][2]string{{source.StorageKey, key}, {thumbnailKey(source.StorageKey), thumbnailKey(key)}} {
+		if err := s.copyObject(ctx, keys[0], keys[1]); err != nil {
+			s.removePhotoObjects([]repository.Photo{copied})
+			return err
+		}
+	}
+
+	target.StorageKey = key
+	target.Link = s.storage.URL(key)
+	target.ThumbnailLink = s.storage.URL(thumbnailKey(key))
+	if err := s.repo.UpdatePhoto(target); err != nil {
+		s.removePhotoObjects([]repository.Photo{copied})
+		return err
+	}
+	return nil
+}
+
+func (s ServiceImpl) copyObject(ctx context.Context, from, to string) error {
+	object, err := s.storage.Get(ctx, from)
+	if err != nil {
+		return err
+	}
+	defer object.Close()
+	data, err := io.ReadAll(object)
+	if err != nil {
+		return err
+	}
+	return s.storage.Put(ctx, to, bytes.NewReader(data), int64(len(data)), mime.TypeByExtension(path.Ext(to)))
+}
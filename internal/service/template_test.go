@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dwnGnL/pg-contests/internal/config"
+	"github.com/dwnGnL/pg-contests/internal/repository"
+)
+
+// draftRepo банк вопросов, один исходный конкурс и один шаблон в памяти
+type draftRepo struct {
+	*bankRepo
+	source   repository.Contest
+	template repository.ContestTemplate
+	created  []repository.Contest
+}
+
+func (r *draftRepo) GetContest(int64) (*repository.Contest, error) {
+	source := r.source
+	return &source, nil
+}
+
+func (r *draftRepo) GetTemplate(int64) (*repository.ContestTemplate, error) {
+	template := r.template
+	return &template, nil
+}
+
+func (r *draftRepo) CreateContest(contest repository.Contest) (*repository.Contest, error) {
+	contest.ID = int64(100 + len(r.created))
+	r.created = append(r.created, contest)
+	return &contest, nil
+}
+
+func (r *draftRepo) IncrementTemplateIssued(int64) error {
+	r.template.Issued++
+	return nil
+}
+
+func TestCloneContest(t *testing.T) {
+	correct := true
+	repo := &draftRepo{bankRepo: testBank(), source: repository.Contest{
+		ID: 3, Title: "Квиз", Status: repository.StatusArchived, Timezone: "UTC",
+		Questions: []repository.Question{{ID: 5, Title: "Вопрос", Answers: []repository.Answer{{ID: 7, IsCorrect: &correct}}}},
+	}}
+	start := time.Now().Add(24 * time.Hour)
+	created, err := New(&config.Config{}, repo).CloneContest(context.Background(), 3, DraftOptions{StartTime: start, Title: "Квиз 2", CreatedBy: "editor"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Status != repository.StatusDraft || created.Title != "Квиз 2" || created.CreatedBy != "editor" || !created.StartTime.Equal(start) {
+		t.Fatalf("clone = %+v", created)
+	}
+	if len(created.Questions) != 1 || created.Questions[0].ID != 0 || created.Questions[0].Answers[0].ID != 0 {
+		t.Fatalf("clone questions = %+v", created.Questions)
+	}
+}
+
+func TestInstantiateTemplate(t *testing.T) {
+	repo := &draftRepo{bankRepo: testBank(), template: repository.ContestTemplate{
+		ID: 4, TitlePattern: "Выпуск {n}", Timezone: "UTC", Issued: 2,
+		Slots: repository.TemplateSlots{{IDs: []int64{1}}, {Tags: []string{"россия"}, Count: 2}},
+	}}
+	s := New(&config.Config{}, repo)
+	start := time.Now().Add(24 * time.Hour)
+	created, err := s.InstantiateTemplate(4, DraftOptions{StartTime: start, CreatedBy: "editor"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Title != "Выпуск 3" || created.Status != repository.StatusDraft || *created.TemplateID != 4 || created.Bank != nil {
+		t.Fatalf("contest = %+v", created)
+	}
+	if len(created.Questions) != 3 || *created.Questions[0].BankQuestionID != 1 {
+		t.Fatalf("questions = %+v", created.Questions)
+	}
+	for _, question := range created.Questions[1:] {
+		if *question.BankQuestionID%2 != 0 {
+			t.Errorf("question %d has no tag россия", *question.BankQuestionID)
+		}
+	}
+	if repo.template.Issued != 3 {
+		t.Fatalf("issued = %d, want 3", repo.template.Issued)
+	}
+	//следующий выпуск получает следующий номер
+	if created, err = s.InstantiateTemplate(4, DraftOptions{StartTime: start}); err != nil || created.Title != "Выпуск 4" {
+		t.Fatalf("next issue = %v, %v", created, err)
+	}
+}
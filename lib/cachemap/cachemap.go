@@ -21,6 +21,11 @@ func (c *CacheMaper[K, V]) Store(key K, value V) {
 	c.sMap.Store(key, value)
 }
 
+func (c *CacheMaper[K, V]) LoadOrStore(key K, value V) (V, bool) {
+	actual, loaded := c.sMap.LoadOrStore(key, value)
+	return actual.(V), loaded
+}
+
 func (c *CacheMaper[K, V]) Range(f func(key K, value V) bool) {
 	c.sMap.Range(func(keyAny, valueAny any) bool {
 		return f(keyAny.(K), valueAny.(V))
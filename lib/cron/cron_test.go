@@ -0,0 +1,134 @@
+package cron
+
+import (
+	"errors"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func bitsOf(values ...int) uint64 {
+	var bits uint64
+	for _, v := range values {
+		bits |= 1 << uint(v)
+	}
+	return bits
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr string
+		want Schedule
+	}{
+		{
+			expr: "*/15 9-17 * * *",
+			want: Schedule{minute: bitsOf(0, 15, 30, 45), hour: bitsOf(9, 10, 11, 12, 13, 14, 15, 16, 17),
+				dom:   bitsOf(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31),
+				month: bitsOf(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12), dow: bitsOf(0, 1, 2, 3, 4, 5, 6, 7), domAny: true, dowAny: true},
+		},
+		{
+			expr: "5/20 0 1,15 jan-mar mon-fri",
+			want: Schedule{minute: bitsOf(5, 25, 45), hour: bitsOf(0), dom: bitsOf(1, 15), month: bitsOf(1, 2, 3), dow: bitsOf(1, 2, 3, 4, 5)},
+		},
+		{
+			expr: "0 0 ? * 7",
+			want: Schedule{minute: bitsOf(0), hour: bitsOf(0),
+				dom:   bitsOf(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31),
+				month: bitsOf(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12), dow: bitsOf(0, 7), domAny: true},
+		},
+		{
+			expr: "0 0 */10 * 1-5/2",
+			want: Schedule{minute: bitsOf(0), hour: bitsOf(0), dom: bitsOf(1, 11, 21, 31),
+				month: bitsOf(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12), dow: bitsOf(1, 3, 5), domAny: true},
+		},
+		{
+			expr: " @Weekly ",
+			want: Schedule{minute: bitsOf(0), hour: bitsOf(0),
+				dom:   bitsOf(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31),
+				month: bitsOf(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12), dow: bitsOf(0), domAny: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if *got != tt.want {
+				t.Fatalf("Parse = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"10-5 * * * *",
+		"* * * foo *",
+		"@every",
+	} {
+		if _, err := Parse(expr); !errors.Is(err, ErrInvalidExpression) {
+			t.Errorf("Parse(%q) error = %v, want ErrInvalidExpression", expr, err)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	utc := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name  string
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{name: "step", expr: "*/15 * * * *", after: time.Date(2026, 10, 19, 10, 7, 30, 0, time.UTC), want: utc(2026, 10, 19, 10, 15)},
+		{name: "strictly after", expr: "0 0 * * *", after: utc(2026, 1, 1, 0, 0), want: utc(2026, 1, 2, 0, 0)},
+		{name: "weekdays", expr: "0 9 * * mon-fri", after: utc(2026, 10, 17, 12, 0), want: utc(2026, 10, 19, 9, 0)},
+		{name: "sunday as 7", expr: "0 0 * * 7", after: utc(2026, 10, 17, 12, 0), want: utc(2026, 10, 18, 0, 0)},
+		{name: "day of month or weekday", expr: "0 0 13 * 5", after: utc(2026, 10, 10, 0, 0), want: utc(2026, 10, 13, 0, 0)},
+		{name: "stepped day of month is unrestricted", expr: "0 0 */2 * 1", after: utc(2026, 10, 19, 12, 0), want: utc(2026, 10, 26, 0, 0)},
+		{name: "stepped weekday is unrestricted", expr: "0 0 15 * */2", after: utc(2026, 10, 1, 0, 0), want: utc(2026, 10, 15, 0, 0)},
+		{name: "month rollover", expr: "@monthly", after: utc(2026, 1, 31, 12, 0), want: utc(2026, 2, 1, 0, 0)},
+		{name: "year rollover", expr: "30 23 31 dec *", after: utc(2026, 12, 31, 23, 30), want: utc(2027, 12, 31, 23, 30)},
+		{name: "leap day", expr: "0 0 29 2 *", after: utc(2026, 3, 1, 0, 0), want: utc(2028, 2, 29, 0, 0)},
+		{name: "never", expr: "0 0 30 2 *", after: utc(2026, 1, 1, 0, 0)},
+		//2026-03-29 в Берлине 02:00 CET сразу становится 03:00 CEST
+		{name: "spring forward skips missing time", expr: "30 2 * * *", after: time.Date(2026, 3, 29, 0, 0, 0, 0, berlin), want: utc(2026, 3, 30, 0, 30)},
+		{name: "spring forward hourly", expr: "0 * * * *", after: time.Date(2026, 3, 29, 1, 30, 0, 0, berlin), want: utc(2026, 3, 29, 1, 0)},
+		//2026-10-25 в Берлине 03:00 CEST становится 02:00 CET, 02:30 бывает дважды; time.Date выбирает второе
+		{name: "fall back fires once", expr: "30 2 * * *", after: time.Date(2026, 10, 25, 0, 0, 0, 0, berlin), want: utc(2026, 10, 25, 1, 30)},
+		{name: "fall back repeated time is skipped", expr: "30 2 * * *", after: utc(2026, 10, 25, 0, 30).In(berlin), want: utc(2026, 10, 26, 1, 30)},
+		{name: "fall back hourly", expr: "0 * * * *", after: utc(2026, 10, 25, 0, 0).In(berlin), want: utc(2026, 10, 25, 2, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			got := s.Next(tt.after)
+			if !got.Equal(tt.want) {
+				t.Fatalf("Next(%v) = %v, want %v", tt.after, got, tt.want)
+			}
+			if !got.IsZero() && got.Location() != tt.after.Location() {
+				t.Fatalf("Next location = %v, want %v", got.Location(), tt.after.Location())
+			}
+		})
+	}
+}
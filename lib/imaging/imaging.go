@@ -0,0 +1,118 @@
+// Package imaging проверка загружаемых картинок и превью без внешних зависимостей
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // регистрирует декодер gif
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+const maxPixels = 40_000_000 // защита от "бомб" с огромными размерами при маленьком файле
+
+var ErrUnsupported = errors.New("unsupported image")
+
+var extensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// Info результат проверки файла
+type Info struct {
+	ContentType string
+	Ext         string
+	Width       int
+	Height      int
+}
+
+// Inspect тип определяется по содержимому, а не по имени файла или заголовку клиента
+func Inspect(data []byte) (Info, error) {
+	contentType := http.DetectContentType(data)
+	ext, ok := extensions[contentType]
+	if !ok {
+		return Info{}, fmt.Errorf("%w: %s", ErrUnsupported, contentType)
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Info{}, fmt.Errorf("%w: %s", ErrUnsupported, err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxPixels {
+		return Info{}, fmt.Errorf("%w: %dx%d is too large", ErrUnsupported, config.Width, config.Height)
+	}
+	return Info{ContentType: contentType, Ext: ext, Width: config.Width, Height: config.Height}, nil
+}
+
+// Extension расширение файла для поддерживаемого типа, заявленного клиентом
+func Extension(contentType string) (string, bool) {
+	ext, ok := extensions[contentType]
+	return ext, ok
+}
+
+// Thumbnail уменьшает картинку так, чтобы большая сторона была не больше maxSide, и кодирует в JPEG
+// (PNG для исходников с прозрачностью). Картинки меньше maxSide не увеличиваются
+func Thumbnail(data []byte, maxSide int) ([]byte, string, error) {
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %s", ErrUnsupported, err)
+	}
+	thumb := resize(src, maxSide)
+
+	var buf bytes.Buffer
+	if format == "png" || format == "gif" {
+		err = png.Encode(&buf, thumb)
+		return buf.Bytes(), "image/png", err
+	}
+	err = jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: 85})
+	return buf.Bytes(), "image/jpeg", err
+}
+
+// resize усреднение по области (box filter), для превью этого достаточно
+func resize(src image.Image, maxSide int) image.Image {
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w <= maxSide && h <= maxSide {
+		return src
+	}
+	dw, dh := maxSide, h*maxSide/w
+	if h > w {
+		dw, dh = w*maxSide/h, maxSide
+	}
+	if dw < 1 {
+		dw = 1
+	}
+	if dh < 1 {
+		dh = 1
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0 := bounds.Min.Y + y*h/dh
+		y1 := bounds.Min.Y + (y+1)*h/dh
+		for x := 0; x < dw; x++ {
+			x0 := bounds.Min.X + x*w/dw
+			x1 := bounds.Min.X + (x+1)*w/dw
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := color.NRGBAModel.Convert(src.At(sx, sy)).(color.NRGBA)
+					r += uint64(c.R)
+					g += uint64(c.G)
+					b += uint64(c.B)
+					a += uint64(c.A)
+					n++
+				}
+			}
+			if n == 0 {
+				continue
+			}
+			dst.SetNRGBA(x, y, color.NRGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(b / n), A: uint8(a / n)})
+		}
+	}
+	return dst
+}
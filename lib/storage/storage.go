@@ -0,0 +1,44 @@
+// Package storage хранилище загружаемых файлов: локальная файловая система или S3-совместимый бакет
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound   = errors.New("storage object not found")
+	ErrInvalidKey = errors.New("invalid storage key")
+)
+
+// Storage объекты адресуются ключом вида "contests/1/abc.jpg"
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	// URL публичная ссылка на объект
+	URL(key string) string
+	// PresignPut ссылка, по которой клиент сам загружает объект PUT-запросом до истечения ttl
+	PresignPut(ctx context.Context, key, contentType string, ttl time.Duration) (string, error)
+}
+
+// FileHandler http.Handler для раздачи и приема файлов, если хранилище обслуживает их само (локальное)
+func FileHandler(s Storage) http.Handler {
+	if h, ok := s.(http.Handler); ok {
+		return h
+	}
+	return nil
+}
+
+func cleanKey(key string) (string, error) {
+	key = strings.TrimPrefix(key, "/")
+	if key == "" || path.Clean(key) != key || strings.HasPrefix(key, "../") || key == ".." {
+		return "", ErrInvalidKey
+	}
+	return key, nil
+}
@@ -4,9 +4,14 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"time"
+	_ "time/tzdata"
 
 	"github.com/dwnGnL/pg-contests/internal/cmd"
 	"github.com/dwnGnL/pg-contests/internal/config"
+	"github.com/dwnGnL/pg-contests/internal/contestio"
+	"github.com/dwnGnL/pg-contests/internal/repository"
 	"github.com/dwnGnL/pg-contests/lib/goerrors"
 
 	"github.com/sirupsen/logrus"
@@ -14,9 +19,18 @@ import (
 )
 
 const (
-	flagConfig          = "config"
-	cliArgMigrationDSN  = "dsn"
-	cliArgMigrationDown = "down"
+	flagConfig            = "config"
+	cliArgMigrationDSN    = "dsn"
+	cliArgMigrationUp     = "up"
+	cliArgMigrationDown   = "down"
+	cliArgMigrationStatus = "status"
+	cliArgFormat          = "format"
+	cliArgOutput          = "output"
+	cliArgDryRun          = "dry-run"
+	cliArgTitle           = "title"
+	cliArgPrice           = "price"
+	cliArgStartTime       = "start-time"
+	cliArgTimezone        = "timezone"
 )
 
 var Version = "v0.0.1"
@@ -45,13 +59,126 @@ func main() {
 			},
 			{
 				Name:  "migrate",
-				Usage: "migrate",
+				Usage: "apply pending migrations (same as migrate up)",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  cliArgMigrationDSN,
+						Usage: "override database dsn from config",
+					},
+				},
 				Action: func(cliContext *cli.Context) error {
-					cfg := config.FromFile(cliContext.String(flagConfig))
-					fmt.Println(cfg)
-					intLogger(cfg.LogLevel)
+					cfg := migrationConfig(cliContext)
 					return cmd.StartMigrate(cfg)
 				},
+				Subcommands: []*cli.Command{
+					{
+						Name:  cliArgMigrationUp,
+						Usage: "apply pending migrations",
+						Action: func(cliContext *cli.Context) error {
+							cfg := migrationConfig(cliContext)
+							return cmd.StartMigrate(cfg)
+						},
+					},
+					{
+						Name:      cliArgMigrationDown,
+						Usage:     "roll back last N migrations",
+						ArgsUsage: "N",
+						Action: func(cliContext *cli.Context) error {
+							steps, err := strconv.Atoi(cliContext.Args().First())
+							if err != nil {
+								return fmt.Errorf("migrate down expects number of steps: %w", err)
+							}
+							cfg := migrationConfig(cliContext)
+							return cmd.StartMigrateDown(cfg, steps)
+						},
+					},
+					{
+						Name:  cliArgMigrationStatus,
+						Usage: "show applied and pending migrations",
+						Action: func(cliContext *cli.Context) error {
+							cfg := migrationConfig(cliContext)
+							return cmd.PrintMigrationStatus(cfg, os.Stdout)
+						},
+					},
+				},
+			},
+			{
+				Name:  "contest",
+				Usage: "export and import contests in json, csv or gift formats",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "export",
+						Usage:     "export contest with questions and answers",
+						ArgsUsage: "CONTEST_ID",
+						Flags: []cli.Flag{
+							&cli.StringFlag{Name: cliArgFormat, Usage: "json, csv or gift; by default from --output extension or json"},
+							&cli.StringFlag{Name: cliArgOutput, Aliases: []string{"o"}, Usage: "output file, stdout by default"},
+						},
+						Action: func(cliContext *cli.Context) error {
+							contestID, err := strconv.ParseInt(cliContext.Args().First(), 10, 64)
+							if err != nil {
+								return fmt.Errorf("contest export expects contest id: %w", err)
+							}
+							output := cliContext.String(cliArgOutput)
+							formatName := cliContext.String(cliArgFormat)
+							if formatName == "" && output == "" {
+								formatName = string(contestio.FormatJSON)
+							}
+							format, err := contestio.ParseFormat(formatName, output)
+							if err != nil {
+								return err
+							}
+							cfg := config.FromFile(cliContext.String(flagConfig))
+							intLogger(cfg.LogLevel)
+							w := os.Stdout
+							if output != "" {
+								if w, err = os.Create(output); err != nil {
+									return err
+								}
+								defer w.Close()
+							}
+							return cmd.ExportContest(cfg, contestID, format, w)
+						},
+					},
+					{
+						Name:      "import",
+						Usage:     "import contest as a draft",
+						ArgsUsage: "FILE",
+						Flags: []cli.Flag{
+							&cli.StringFlag{Name: cliArgFormat, Usage: "json, csv or gift; by default from file extension"},
+							&cli.BoolFlag{Name: cliArgDryRun, Usage: "only validate the file"},
+							&cli.StringFlag{Name: cliArgTitle, Usage: "contest title"},
+							&cli.Float64Flag{Name: cliArgPrice, Usage: "contest price"},
+							&cli.TimestampFlag{Name: cliArgStartTime, Layout: time.RFC3339, Usage: "contest start time, RFC3339"},
+							&cli.StringFlag{Name: cliArgTimezone, Usage: "contest IANA timezone"},
+						},
+						Action: func(cliContext *cli.Context) error {
+							fileName := cliContext.Args().First()
+							format, err := contestio.ParseFormat(cliContext.String(cliArgFormat), fileName)
+							if err != nil {
+								return err
+							}
+							file, err := os.Open(fileName)
+							if err != nil {
+								return err
+							}
+							defer file.Close()
+
+							base := repository.Contest{
+								Title:     cliContext.String(cliArgTitle),
+								Price:     cliContext.Float64(cliArgPrice),
+								Timezone:  cliContext.String(cliArgTimezone),
+								CreatedBy: "cli",
+							}
+							if startTime := cliContext.Timestamp(cliArgStartTime); startTime != nil {
+								base.StartTime = *startTime
+							}
+							cfg := config.FromFile(cliContext.String(flagConfig))
+							intLogger(cfg.LogLevel)
+							return cmd.ImportContest(cfg, file, format, base, cliContext.Bool(cliArgDryRun), os.Stdout)
+						},
+					},
+				},
 			},
 			{
 				Name:  "version",
@@ -70,6 +197,16 @@ func main() {
 	}
 }
 
+// migrationConfig читает конфиг и подменяет DSN, если он передан флагом --dsn
+func migrationConfig(cliContext *cli.Context) *config.Config {
+	cfg := config.FromFile(cliContext.String(flagConfig))
+	intLogger(cfg.LogLevel)
+	if dsn := cliContext.String(cliArgMigrationDSN); dsn != "" {
+		cfg.DB.DSN = dsn
+	}
+	return cfg
+}
+
 func intLogger(logLevel string) {
 	var formatter logrus.Formatter = new(logrus.JSONFormatter)
 	if os.Getenv("LOG_FORMAT") == "text" {